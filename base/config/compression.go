@@ -0,0 +1,65 @@
+package config
+
+const defaultCompressMinSize = 1024
+
+type CompressionType string
+
+const (
+	CompressionGzip   CompressionType = "gzip"
+	CompressionZstd   CompressionType = "zstd"
+	CompressionSnappy CompressionType = "snappy"
+)
+
+// Compression 描述数据写入请求(pipeline PostData/PostDataFromBytes、logdb SendLog、tsdb PostPoints)的 body 压缩方式，
+// 其他管理类请求不会被压缩
+type Compression struct {
+	Type    CompressionType
+	Level   int   // 压缩级别，0 表示使用算法的默认级别，snappy 不支持级别
+	MinSize int64 // body 小于该字节数时不压缩
+
+	// 为空时压缩所有数据写入请求，否则只压缩列出的 Operation，如 base.OpPostData
+	Operations []string
+}
+
+func NewCompression(t CompressionType) *Compression {
+	return &Compression{
+		Type:    t,
+		MinSize: defaultCompressMinSize,
+	}
+}
+
+func (c *Compression) WithLevel(level int) *Compression {
+	c.Level = level
+	return c
+}
+
+func (c *Compression) WithMinSize(size int64) *Compression {
+	c.MinSize = size
+	return c
+}
+
+func (c *Compression) WithOperations(ops ...string) *Compression {
+	c.Operations = ops
+	return c
+}
+
+func (c *Compression) Clone() *Compression {
+	if c == nil {
+		return nil
+	}
+	nc := *c
+	nc.Operations = append([]string(nil), c.Operations...)
+	return &nc
+}
+
+func (c *Compression) IsCompressOperation(opName string) bool {
+	if len(c.Operations) == 0 {
+		return true
+	}
+	for _, op := range c.Operations {
+		if op == opName {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/qiniu/pandora-go-sdk/base"
@@ -29,6 +30,44 @@ type Config struct {
 	ConfigType       string
 
 	AllowInsecureServer bool
+
+	// ServiceEndpoints 的 key 为服务类型(如 TypePipeline)，配置后该服务按 region 选择 endpoint 并在连接失败时切换，
+	// 忽略上面对应的单个 endpoint
+	ServiceEndpoints map[string]*Endpoints
+
+	RetryPolicy *RetryPolicy
+	// AdaptiveRateLimit 不为空时根据服务端的限流反馈自动调整 RequestRateLimit 与 FlowRateLimit 的实际速率
+	AdaptiveRateLimit *AdaptiveRateLimit
+	// RateLimitBuckets 不为空时按 repo 或 ResourceOwner 为写入请求分配独立的限速桶
+	RateLimitBuckets *RateLimitBuckets
+	// CredentialsProvider 不为空时每次签名前从中获取 ak/sk，忽略 Ak/Sk 字段，用于运行时轮换密钥
+	CredentialsProvider CredentialsProvider
+	// Compression 不为空时覆盖 Gzip 配置
+	Compression *Compression
+	// MetricsCollector 不为空时记录每个请求的指标，内置的 Prometheus 实现见 base/metrics
+	MetricsCollector MetricsCollector
+	// Tracer 不为空时为每个请求创建 span，并在请求头中注入 traceparent
+	Tracer Tracer
+	// StructuredLogger 不为空时请求日志以结构化字段输出到该 logger，否则输出到 Logger
+	StructuredLogger base.StructuredLogger
+	// BodyLogging 不为空时在 debug 级别输出脱敏后的请求与响应 body
+	BodyLogging *BodyLogging
+
+	// HTTPClient 不为空时各服务直接使用该 client 发送请求，忽略 DialTimeout 等连接配置
+	HTTPClient *http.Client
+	// Middlewares 依次包装请求使用的 http.RoundTripper，第一个位于最外层
+	Middlewares []Middleware
+}
+
+// Middleware 包装一个 http.RoundTripper，可以用于请求日志、代理鉴权、故障注入等，
+// 通过 request.OperationFromContext(req.Context()) 可以拿到当前请求对应的 Operation
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc 让普通函数实现 http.RoundTripper
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
 }
 
 const (
@@ -79,6 +118,19 @@ func (c *Config) Clone() *Config {
 		ConfigType:       c.ConfigType,
 
 		AllowInsecureServer: false,
+		ServiceEndpoints:    cloneServiceEndpoints(c.ServiceEndpoints),
+
+		RetryPolicy:         c.RetryPolicy.Clone(),
+		AdaptiveRateLimit:   c.AdaptiveRateLimit.Clone(),
+		RateLimitBuckets:    c.RateLimitBuckets.Clone(),
+		CredentialsProvider: c.CredentialsProvider,
+		Compression:         c.Compression.Clone(),
+		MetricsCollector:    c.MetricsCollector,
+		Tracer:              c.Tracer,
+		StructuredLogger:    c.StructuredLogger,
+		BodyLogging:         c.BodyLogging.Clone(),
+		HTTPClient:          c.HTTPClient,
+		Middlewares:         append([]Middleware(nil), c.Middlewares...),
 	}
 }
 
@@ -117,6 +169,26 @@ func (c *Config) WithLogkitEndpoint(endpoint string) *Config {
 	return c
 }
 
+// WithServiceEndpoints 为服务 service(如 TypePipeline) 配置多个 region 的 endpoint
+func (c *Config) WithServiceEndpoints(service string, e *Endpoints) *Config {
+	if c.ServiceEndpoints == nil {
+		c.ServiceEndpoints = make(map[string]*Endpoints)
+	}
+	c.ServiceEndpoints[service] = e
+	return c
+}
+
+func cloneServiceEndpoints(m map[string]*Endpoints) map[string]*Endpoints {
+	if m == nil {
+		return nil
+	}
+	ret := make(map[string]*Endpoints, len(m))
+	for k, v := range m {
+		ret[k] = v.Clone()
+	}
+	return ret
+}
+
 func (c *Config) WithAccessKeySecretKey(ak, sk string) *Config {
 	c.Ak, c.Sk = ak, sk
 	return c
@@ -142,6 +214,16 @@ func (c *Config) WithLoggerLevel(level base.LogLevelType) *Config {
 	return c
 }
 
+func (c *Config) WithStructuredLogger(l base.StructuredLogger) *Config {
+	c.StructuredLogger = l
+	return c
+}
+
+func (c *Config) WithBodyLogging(b *BodyLogging) *Config {
+	c.BodyLogging = b
+	return c
+}
+
 func (c *Config) WithRequestRateLimit(limit int64) *Config {
 	c.RequestRateLimit = limit
 	return c
@@ -152,6 +234,16 @@ func (c *Config) WithFlowRateLimit(limit int64) *Config {
 	return c
 }
 
+func (c *Config) WithAdaptiveRateLimit(a *AdaptiveRateLimit) *Config {
+	c.AdaptiveRateLimit = a
+	return c
+}
+
+func (c *Config) WithRateLimitBuckets(b *RateLimitBuckets) *Config {
+	c.RateLimitBuckets = b
+	return c
+}
+
 func (c *Config) WithGzipData(enable bool) *Config {
 	c.Gzip = enable
 	return c
@@ -166,3 +258,38 @@ func (c *Config) WithDefaultRegion(region string) *Config {
 	c.DefaultRegion = region
 	return c
 }
+
+func (c *Config) WithRetryPolicy(policy *RetryPolicy) *Config {
+	c.RetryPolicy = policy
+	return c
+}
+
+func (c *Config) WithCompression(compression *Compression) *Config {
+	c.Compression = compression
+	return c
+}
+
+func (c *Config) WithMetricsCollector(collector MetricsCollector) *Config {
+	c.MetricsCollector = collector
+	return c
+}
+
+func (c *Config) WithTracer(tracer Tracer) *Config {
+	c.Tracer = tracer
+	return c
+}
+
+func (c *Config) WithCredentialsProvider(provider CredentialsProvider) *Config {
+	c.CredentialsProvider = provider
+	return c
+}
+
+func (c *Config) WithHTTPClient(client *http.Client) *Config {
+	c.HTTPClient = client
+	return c
+}
+
+func (c *Config) WithMiddleware(middlewares ...Middleware) *Config {
+	c.Middlewares = append(c.Middlewares, middlewares...)
+	return c
+}
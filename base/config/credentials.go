@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultCredentialsCheckInterval = 10 * time.Second
+
+var ErrNoCredentials = errors.New("no valid credentials found")
+
+type Credentials struct {
+	Ak string
+	Sk string
+}
+
+// CredentialsProvider 在每次签名前被调用以获取最新的 ak/sk，实现需要并发安全且足够快，耗时的刷新应当自行缓存
+type CredentialsProvider interface {
+	Retrieve() (Credentials, error)
+}
+
+// CredentialsProviderFunc 让普通函数实现 CredentialsProvider
+type CredentialsProviderFunc func() (Credentials, error)
+
+func (f CredentialsProviderFunc) Retrieve() (Credentials, error) {
+	return f()
+}
+
+type staticCredentials Credentials
+
+func NewStaticCredentials(ak, sk string) CredentialsProvider {
+	return staticCredentials{Ak: ak, Sk: sk}
+}
+
+func (c staticCredentials) Retrieve() (Credentials, error) {
+	if c.Ak == "" || c.Sk == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+	return Credentials(c), nil
+}
+
+type envCredentials struct{}
+
+// NewEnvCredentials 每次从环境变量 PANDORA_ACCESS_KEY 与 PANDORA_SECRET_KEY 中读取 ak/sk
+func NewEnvCredentials() CredentialsProvider {
+	return envCredentials{}
+}
+
+func (envCredentials) Retrieve() (Credentials, error) {
+	return NewStaticCredentials(strings.TrimSpace(os.Getenv("PANDORA_ACCESS_KEY")), strings.TrimSpace(os.Getenv("PANDORA_SECRET_KEY"))).Retrieve()
+}
+
+// FileCredentials 从 LoadFromFile 支持的配置文件中读取 profile 的 ak/sk，
+// 每隔 CheckInterval 检查一次文件的修改时间，文件变化后重新加载，加载失败时继续使用上一次成功读取的值
+type FileCredentials struct {
+	Path          string
+	Profile       string
+	CheckInterval time.Duration
+
+	mu        sync.Mutex
+	creds     Credentials
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+func NewFileCredentials(path, profile string) *FileCredentials {
+	return &FileCredentials{Path: path, Profile: profile, CheckInterval: defaultCredentialsCheckInterval}
+}
+
+func (f *FileCredentials) Retrieve() (Credentials, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	if f.creds.Ak != "" && now.Sub(f.lastCheck) < f.CheckInterval {
+		return f.creds, nil
+	}
+	f.lastCheck = now
+	err := f.reload()
+	if f.creds.Ak == "" {
+		if err == nil {
+			err = ErrNoCredentials
+		}
+		return Credentials{}, err
+	}
+	return f.creds, nil
+}
+
+func (f *FileCredentials) reload() error {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return err
+	}
+	if f.creds.Ak != "" && info.ModTime().Equal(f.modTime) {
+		return nil
+	}
+	c, err := LoadFromFile(f.Path, f.Profile)
+	if err != nil {
+		return err
+	}
+	if c.Ak == "" || c.Sk == "" {
+		return fmt.Errorf("no ak/sk of profile %q in config file %s", f.Profile, f.Path)
+	}
+	f.creds = Credentials{Ak: c.Ak, Sk: c.Sk}
+	f.modTime = info.ModTime()
+	return nil
+}
+
+type chainCredentials []CredentialsProvider
+
+// NewChainCredentials 依次尝试每个 provider，返回第一个成功获取的 ak/sk
+func NewChainCredentials(providers ...CredentialsProvider) CredentialsProvider {
+	return chainCredentials(providers)
+}
+
+func (c chainCredentials) Retrieve() (Credentials, error) {
+	var errs []string
+	for _, p := range c {
+		creds, err := p.Retrieve()
+		if err == nil {
+			return creds, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	if len(errs) == 0 {
+		return Credentials{}, ErrNoCredentials
+	}
+	return Credentials{}, fmt.Errorf("%v: %s", ErrNoCredentials, strings.Join(errs, "; "))
+}
+
+// GetCredentials 返回当前使用的 ak/sk，设置了 CredentialsProvider 时以其为准，否则使用 Ak/Sk 字段
+func (c *Config) GetCredentials() (Credentials, error) {
+	if c.CredentialsProvider != nil {
+		return c.CredentialsProvider.Retrieve()
+	}
+	return Credentials{Ak: c.Ak, Sk: c.Sk}, nil
+}
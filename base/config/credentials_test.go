@@ -0,0 +1,71 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCredentialsProviders(t *testing.T) {
+	_, err := NewStaticCredentials("", "").Retrieve()
+	assert.Equal(t, ErrNoCredentials, err)
+
+	os.Setenv("PANDORA_ACCESS_KEY", "env-ak")
+	os.Setenv("PANDORA_SECRET_KEY", "env-sk")
+	defer os.Unsetenv("PANDORA_ACCESS_KEY")
+	defer os.Unsetenv("PANDORA_SECRET_KEY")
+
+	chain := NewChainCredentials(NewStaticCredentials("", ""), NewEnvCredentials(), NewStaticCredentials("ak", "sk"))
+	creds, err := chain.Retrieve()
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Ak: "env-ak", Sk: "env-sk"}, creds)
+
+	os.Unsetenv("PANDORA_ACCESS_KEY")
+	creds, err = chain.Retrieve()
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Ak: "ak", Sk: "sk"}, creds)
+
+	c := NewConfig().WithAccessKeySecretKey("old-ak", "old-sk")
+	creds, err = c.GetCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "old-ak", creds.Ak)
+	creds, err = c.WithCredentialsProvider(chain).GetCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "ak", creds.Ak)
+}
+
+func TestFileCredentialsRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pandora-credentials")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "credentials.yaml")
+
+	provider := NewFileCredentials(path, "prod")
+	provider.CheckInterval = 0
+	_, err = provider.Retrieve()
+	assert.Error(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("prod:\n  ak: ak1\n  sk: sk1\n"), 0644))
+	creds, err := provider.Retrieve()
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Ak: "ak1", Sk: "sk1"}, creds)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("prod:\n  ak: ak2\n  sk: sk2\n"), 0644))
+	future := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(path, future, future))
+	creds, err = provider.Retrieve()
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Ak: "ak2", Sk: "sk2"}, creds)
+
+	// 文件写坏时继续使用上一次的值
+	assert.NoError(t, ioutil.WriteFile(path, []byte("prod: ["), 0644))
+	future = future.Add(time.Minute)
+	assert.NoError(t, os.Chtimes(path, future, future))
+	creds, err = provider.Retrieve()
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Ak: "ak2", Sk: "sk2"}, creds)
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const defaultEndpointCooldown = 30 * time.Second
+
+// Endpoints 描述一个服务在多个 region 的 endpoint，请求按 region 选择 endpoint，连接失败时切换到同一 region 的下一个
+type Endpoints struct {
+	// Regions 的 key 为 region，value 为该 region 的 endpoint 列表，排在前面的优先使用
+	Regions map[string][]string
+	// Cooldown 是 endpoint 连接失败后被跳过的时间，之后的第一个请求会重新尝试它，成功则恢复，默认 30s
+	Cooldown time.Duration
+
+	health *endpointHealth
+}
+
+// endpointHealth 记录连接失败的 endpoint 在何时之前被跳过，Clone 得到的 Endpoints 共享同一份记录
+type endpointHealth struct {
+	mu        sync.Mutex
+	downUntil map[string]time.Time
+}
+
+var endpointsInitMu sync.Mutex
+
+func NewEndpoints() *Endpoints {
+	return &Endpoints{
+		Regions:  make(map[string][]string),
+		Cooldown: defaultEndpointCooldown,
+		health:   &endpointHealth{downUntil: make(map[string]time.Time)},
+	}
+}
+
+func (e *Endpoints) WithRegion(region string, endpoints ...string) *Endpoints {
+	if e.Regions == nil {
+		e.Regions = make(map[string][]string)
+	}
+	e.Regions[region] = endpoints
+	return e
+}
+
+func (e *Endpoints) WithCooldown(d time.Duration) *Endpoints {
+	e.Cooldown = d
+	return e
+}
+
+func (e *Endpoints) Clone() *Endpoints {
+	if e == nil {
+		return nil
+	}
+	clone := &Endpoints{
+		Regions:  make(map[string][]string, len(e.Regions)),
+		Cooldown: e.Cooldown,
+		health:   e.getHealth(),
+	}
+	for region, endpoints := range e.Regions {
+		clone.Regions[region] = append([]string(nil), endpoints...)
+	}
+	return clone
+}
+
+func (e *Endpoints) getHealth() *endpointHealth {
+	endpointsInitMu.Lock()
+	defer endpointsInitMu.Unlock()
+	if e.health == nil {
+		e.health = &endpointHealth{downUntil: make(map[string]time.Time)}
+	}
+	return e.health
+}
+
+// Candidates 返回 region 可用的 endpoint：可用的按配置顺序排在前面，仍在冷却中的按冷却结束时间排在后面。
+// region 没有配置时返回所有 region 的 endpoint
+func (e *Endpoints) Candidates(region string) []string {
+	var endpoints []string
+	if eps, ok := e.Regions[region]; ok {
+		endpoints = eps
+	} else {
+		regions := make([]string, 0, len(e.Regions))
+		for r := range e.Regions {
+			regions = append(regions, r)
+		}
+		sort.Strings(regions)
+		for _, r := range regions {
+			endpoints = append(endpoints, e.Regions[r]...)
+		}
+	}
+
+	h := e.getHealth()
+	now := time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ret := make([]string, 0, len(endpoints))
+	var down []string
+	for _, ep := range endpoints {
+		if until, ok := h.downUntil[ep]; ok && now.Before(until) {
+			down = append(down, ep)
+			continue
+		}
+		ret = append(ret, ep)
+	}
+	sort.SliceStable(down, func(i, j int) bool {
+		return h.downUntil[down[i]].Before(h.downUntil[down[j]])
+	})
+	return append(ret, down...)
+}
+
+// MarkFailed 标记 endpoint 无法连接，Cooldown 时间内优先使用其他 endpoint
+func (e *Endpoints) MarkFailed(endpoint string) {
+	cooldown := e.Cooldown
+	if cooldown <= 0 {
+		cooldown = defaultEndpointCooldown
+	}
+	h := e.getHealth()
+	h.mu.Lock()
+	h.downUntil[endpoint] = time.Now().Add(cooldown)
+	h.mu.Unlock()
+}
+
+// MarkSucceeded 标记 endpoint 恢复可用
+func (e *Endpoints) MarkSucceeded(endpoint string) {
+	h := e.getHealth()
+	h.mu.Lock()
+	delete(h.downUntil, endpoint)
+	h.mu.Unlock()
+}
+
+// Healthy 返回 endpoint 当前是否可用
+func (e *Endpoints) Healthy(endpoint string) bool {
+	h := e.getHealth()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	until, ok := h.downUntil[endpoint]
+	return !ok || !time.Now().Before(until)
+}
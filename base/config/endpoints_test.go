@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointsCandidates(t *testing.T) {
+	e := NewEndpoints().
+		WithRegion("nb", "http://nb1", "http://nb2", "http://nb3").
+		WithRegion("hd", "http://hd1")
+	assert.Equal(t, []string{"http://nb1", "http://nb2", "http://nb3"}, e.Candidates("nb"))
+	// 未配置的 region 使用所有 endpoint
+	assert.Equal(t, []string{"http://hd1", "http://nb1", "http://nb2", "http://nb3"}, e.Candidates("gz"))
+
+	e.MarkFailed("http://nb2")
+	e.MarkFailed("http://nb1")
+	assert.False(t, e.Healthy("http://nb1"))
+	assert.Equal(t, []string{"http://nb3", "http://nb2", "http://nb1"}, e.Candidates("nb"))
+
+	// Clone 共享健康状态
+	clone := e.Clone()
+	clone.MarkSucceeded("http://nb1")
+	assert.True(t, e.Healthy("http://nb1"))
+	assert.Equal(t, []string{"http://nb1", "http://nb3", "http://nb2"}, e.Candidates("nb"))
+
+	e.WithCooldown(10 * time.Millisecond)
+	e.MarkFailed("http://hd1")
+	assert.False(t, e.Healthy("http://hd1"))
+	time.Sleep(20 * time.Millisecond)
+	assert.True(t, e.Healthy("http://hd1"))
+
+	cfg := NewConfig().WithServiceEndpoints(TypePipeline, e)
+	assert.Equal(t, e.Regions, cfg.Clone().ServiceEndpoints[TypePipeline].Regions)
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	DefaultProfile = "default"
+
+	EnvConfigFile = "PANDORA_CONFIG_FILE"
+	EnvProfile    = "PANDORA_PROFILE"
+)
+
+type setting struct {
+	key   string // 配置文件中的字段名
+	env   string
+	apply func(c *Config, v string) error
+}
+
+// settings 列出可以通过配置文件和环境变量设置的所有字段
+var settings = []setting{
+	{"endpoint", "PANDORA_ENDPOINT", func(c *Config, v string) error { c.Endpoint = v; return nil }},
+	{"pipeline_endpoint", "PANDORA_PIPELINE_ENDPOINT", func(c *Config, v string) error { c.PipelineEndpoint = v; return nil }},
+	{"logdb_endpoint", "PANDORA_LOGDB_ENDPOINT", func(c *Config, v string) error { c.LogdbEndpoint = v; return nil }},
+	{"tsdb_endpoint", "PANDORA_TSDB_ENDPOINT", func(c *Config, v string) error { c.TsdbEndpoint = v; return nil }},
+	{"report_endpoint", "PANDORA_REPORT_ENDPOINT", func(c *Config, v string) error { c.ReportEndpoint = v; return nil }},
+	{"logkit_endpoint", "PANDORA_LOGKIT_ENDPOINT", func(c *Config, v string) error { c.LogkitEndpoint = v; return nil }},
+	{"ak", "PANDORA_ACCESS_KEY", func(c *Config, v string) error { c.Ak = v; return nil }},
+	{"sk", "PANDORA_SECRET_KEY", func(c *Config, v string) error { c.Sk = v; return nil }},
+	{"dial_timeout", "PANDORA_DIAL_TIMEOUT", func(c *Config, v string) (err error) {
+		c.DialTimeout, err = parseDuration(v)
+		return
+	}},
+	{"response_timeout", "PANDORA_RESPONSE_TIMEOUT", func(c *Config, v string) (err error) {
+		c.ResponseTimeout, err = parseDuration(v)
+		return
+	}},
+	{"request_rate_limit", "PANDORA_REQUEST_RATE_LIMIT", func(c *Config, v string) (err error) {
+		c.RequestRateLimit, err = strconv.ParseInt(v, 10, 64)
+		return
+	}},
+	{"flow_rate_limit", "PANDORA_FLOW_RATE_LIMIT", func(c *Config, v string) (err error) {
+		c.FlowRateLimit, err = strconv.ParseInt(v, 10, 64)
+		return
+	}},
+	{"gzip", "PANDORA_GZIP", func(c *Config, v string) (err error) {
+		c.Gzip, err = strconv.ParseBool(v)
+		return
+	}},
+	{"region", "PANDORA_REGION", func(c *Config, v string) error { c.DefaultRegion = v; return nil }},
+	{"allow_insecure_server", "PANDORA_ALLOW_INSECURE_SERVER", func(c *Config, v string) (err error) {
+		c.AllowInsecureServer, err = strconv.ParseBool(v)
+		return
+	}},
+	{"user_agent", "PANDORA_USER_AGENT", func(c *Config, v string) error { c.HeaderUserAgent = v; return nil }},
+	{"config_type", "PANDORA_CONFIG_TYPE", func(c *Config, v string) error { c.ConfigType = v; return nil }},
+}
+
+// parseDuration 支持 "10s" 这样的 time.Duration 格式，纯数字表示秒
+func parseDuration(v string) (time.Duration, error) {
+	if secs, err := strconv.ParseFloat(v, 64); err == nil {
+		return time.Duration(secs * float64(time.Second)), nil
+	}
+	return time.ParseDuration(v)
+}
+
+// DefaultConfigFile 返回默认的配置文件路径，优先使用环境变量 PANDORA_CONFIG_FILE，否则为 ~/.pandora/config
+func DefaultConfigFile() string {
+	if path := os.Getenv(EnvConfigFile); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".pandora", "config")
+}
+
+// LoadFromEnv 在默认配置的基础上，用 PANDORA_ 开头的环境变量覆盖对应字段
+func LoadFromEnv() (*Config, error) {
+	c := NewConfig()
+	if err := c.applyEnv(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadFromFile 从配置文件中读取 profile 对应的配置，文件格式由扩展名决定(.json/.yaml/.yml/.toml)，没有扩展名时按 TOML 解析。
+// 文件的顶层是 profile 名称，profile 为空时使用 default，其他 profile 中未设置的字段继承 default 中的值
+func LoadFromFile(path, profile string) (*Config, error) {
+	c := NewConfig()
+	if err := c.applyFile(path, profile); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Load 按 默认值 < 配置文件 < 环境变量 的优先级加载配置，之后通过 With* 方法设置的值优先级最高。
+// path 为空时使用 DefaultConfigFile，且文件不存在时忽略；profile 为空时使用环境变量 PANDORA_PROFILE，仍为空则为 default
+func Load(path, profile string) (*Config, error) {
+	c := NewConfig()
+	optional := path == ""
+	if optional {
+		path = DefaultConfigFile()
+	}
+	if profile == "" {
+		profile = os.Getenv(EnvProfile)
+	}
+	if path != "" {
+		err := c.applyFile(path, profile)
+		if err != nil && !(optional && os.IsNotExist(err)) {
+			return nil, err
+		}
+	}
+	if err := c.applyEnv(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Config) applyEnv() error {
+	for _, s := range settings {
+		v, ok := os.LookupEnv(s.env)
+		if !ok {
+			continue
+		}
+		if err := s.apply(c, strings.TrimSpace(v)); err != nil {
+			return fmt.Errorf("invalid environment variable %s: %v", s.env, err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) applyFile(path, profile string) error {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	profiles := make(map[string]map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(buf))
+		dec.UseNumber()
+		err = dec.Decode(&profiles)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(buf, &profiles)
+	default:
+		err = toml.Unmarshal(buf, &profiles)
+	}
+	if err != nil {
+		return fmt.Errorf("parse config file %s: %v", path, err)
+	}
+
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	values, ok := profiles[profile]
+	if !ok {
+		return fmt.Errorf("profile %q not found in config file %s", profile, path)
+	}
+	if profile != DefaultProfile {
+		if err = c.applyProfile(profiles[DefaultProfile], path, DefaultProfile); err != nil {
+			return err
+		}
+	}
+	return c.applyProfile(values, path, profile)
+}
+
+func (c *Config) applyProfile(values map[string]interface{}, path, profile string) error {
+	known := make(map[string]bool, len(settings))
+	for _, s := range settings {
+		known[s.key] = true
+		v, ok := values[s.key]
+		if !ok || v == nil {
+			continue
+		}
+		if err := s.apply(c, fmt.Sprint(v)); err != nil {
+			return fmt.Errorf("invalid %s of profile %q in config file %s: %v", s.key, profile, path, err)
+		}
+	}
+	for key := range values {
+		if !known[key] {
+			return fmt.Errorf("unknown field %s of profile %q in config file %s", key, profile, path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pandora-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"config": `
+[default]
+ak = "ak"
+sk = "sk"
+dial_timeout = "5s"
+
+[prod]
+pipeline_endpoint = "https://pipeline.example.com"
+request_rate_limit = 100
+allow_insecure_server = true
+`,
+		"config.yaml": `
+default:
+  ak: ak
+  sk: sk
+  dial_timeout: 5s
+prod:
+  pipeline_endpoint: https://pipeline.example.com
+  request_rate_limit: 100
+  allow_insecure_server: true
+`,
+		"config.json": `{
+  "default": {"ak": "ak", "sk": "sk", "dial_timeout": 5},
+  "prod": {"pipeline_endpoint": "https://pipeline.example.com", "request_rate_limit": 100, "allow_insecure_server": true}
+}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+
+		c, err := LoadFromFile(path, "prod")
+		assert.NoError(t, err, name)
+		assert.Equal(t, "ak", c.Ak, name)
+		assert.Equal(t, "sk", c.Sk, name)
+		assert.Equal(t, 5*time.Second, c.DialTimeout, name)
+		assert.Equal(t, defaultResponseTimeout, c.ResponseTimeout, name)
+		assert.Equal(t, "https://pipeline.example.com", c.PipelineEndpoint, name)
+		assert.Equal(t, int64(100), c.RequestRateLimit, name)
+		assert.True(t, c.AllowInsecureServer, name)
+
+		c, err = LoadFromFile(path, "")
+		assert.NoError(t, err, name)
+		assert.Equal(t, "", c.PipelineEndpoint, name)
+
+		_, err = LoadFromFile(path, "staging")
+		assert.Error(t, err, name)
+	}
+
+	path := filepath.Join(dir, "bad.yaml")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("default:\n  unknown: 1\n"), 0644))
+	_, err = LoadFromFile(path, "")
+	assert.Error(t, err)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pandora-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("[dev]\nak = \"file-ak\"\nsk = \"file-sk\"\n"), 0644))
+
+	os.Setenv(EnvConfigFile, path)
+	os.Setenv(EnvProfile, "dev")
+	os.Setenv("PANDORA_ACCESS_KEY", "env-ak")
+	os.Setenv("PANDORA_RESPONSE_TIMEOUT", "1m")
+	defer func() {
+		for _, k := range []string{EnvConfigFile, EnvProfile, "PANDORA_ACCESS_KEY", "PANDORA_RESPONSE_TIMEOUT"} {
+			os.Unsetenv(k)
+		}
+	}()
+
+	c, err := Load("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "env-ak", c.Ak)
+	assert.Equal(t, "file-sk", c.Sk)
+	assert.Equal(t, time.Minute, c.ResponseTimeout)
+
+	c, err = LoadFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "env-ak", c.Ak)
+	assert.Equal(t, "", c.Sk)
+
+	os.Setenv("PANDORA_RESPONSE_TIMEOUT", "soon")
+	_, err = LoadFromEnv()
+	assert.Error(t, err)
+
+	os.Setenv(EnvConfigFile, filepath.Join(dir, "missing"))
+	os.Unsetenv("PANDORA_RESPONSE_TIMEOUT")
+	c, err = Load("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "env-ak", c.Ak)
+	_, err = Load(filepath.Join(dir, "missing"), "")
+	assert.Error(t, err)
+}
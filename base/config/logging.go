@@ -0,0 +1,137 @@
+package config
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/qiniu/pandora-go-sdk/base"
+)
+
+const (
+	defaultBodyLogMaxSize = 4096
+
+	redacted = "******"
+)
+
+// GetStructuredLogger 返回输出结构化日志的 logger，未设置 StructuredLogger 时适配 Logger，两者都为空时使用默认 logger
+func (c *Config) GetStructuredLogger() base.StructuredLogger {
+	if c.StructuredLogger != nil {
+		return c.StructuredLogger
+	}
+	if c.Logger != nil {
+		return base.NewFieldLogger(c.Logger)
+	}
+	return base.NewFieldLogger(base.NewDefaultLogger())
+}
+
+// BodyLogging 控制请求与响应 body 的 debug 日志，只有 logger 开启 debug 级别时才会输出
+type BodyLogging struct {
+	MaxSize int // body 超过该字节数时截断，默认 4096
+
+	// RedactHeaders 中的请求头以 ****** 代替，默认为 Authorization
+	RedactHeaders []string
+	// RedactFields 中的 JSON 字段的值以 ****** 代替，用于隐藏 body 中的 token、密钥等信息
+	RedactFields []string
+
+	// redactRe 是根据 redactFor 中的字段编译的脱敏正则，RedactFields 变化时重新编译
+	redactMu  sync.Mutex
+	redactFor []string
+	redactRe  *regexp.Regexp
+}
+
+func NewBodyLogging() *BodyLogging {
+	b := &BodyLogging{
+		MaxSize:       defaultBodyLogMaxSize,
+		RedactHeaders: []string{"Authorization"},
+	}
+	return b.WithRedactFields("token", "pandoraToken", "ak", "sk", "accessKey", "secretKey", "password")
+}
+
+func (b *BodyLogging) WithMaxSize(size int) *BodyLogging {
+	b.MaxSize = size
+	return b
+}
+
+func (b *BodyLogging) WithRedactHeaders(headers ...string) *BodyLogging {
+	b.RedactHeaders = headers
+	return b
+}
+
+func (b *BodyLogging) WithRedactFields(fields ...string) *BodyLogging {
+	b.RedactFields = fields
+	b.fieldsRegexp()
+	return b
+}
+
+func (b *BodyLogging) Clone() *BodyLogging {
+	if b == nil {
+		return nil
+	}
+	clone := &BodyLogging{
+		MaxSize:       b.MaxSize,
+		RedactHeaders: append([]string(nil), b.RedactHeaders...),
+	}
+	return clone.WithRedactFields(append([]string(nil), b.RedactFields...)...)
+}
+
+// RedactHeader 返回脱敏后的请求头副本
+func (b *BodyLogging) RedactHeader(h http.Header) http.Header {
+	ret := h.Clone()
+	for _, k := range b.RedactHeaders {
+		if ret.Get(k) != "" {
+			ret.Set(k, redacted)
+		}
+	}
+	return ret
+}
+
+// RedactBody 隐藏 body 中 RedactFields 字段的字符串值，再截断到 MaxSize。
+// 必须先脱敏再截断，否则被截断的字段值缺少结尾的引号，匹配不到脱敏规则
+func (b *BodyLogging) RedactBody(body []byte) string {
+	s := string(body)
+	if re := b.fieldsRegexp(); re != nil {
+		s = re.ReplaceAllString(s, `"$1"$2"`+redacted+`"`)
+	}
+	if b.MaxSize > 0 && len(s) > b.MaxSize {
+		s = s[:b.MaxSize] + "...(" + strconv.Itoa(len(s)-b.MaxSize) + " bytes truncated)"
+	}
+	return s
+}
+
+// fieldsRegexp 返回 RedactFields 对应的脱敏正则，只在 RedactFields 变化时重新编译
+func (b *BodyLogging) fieldsRegexp() *regexp.Regexp {
+	b.redactMu.Lock()
+	defer b.redactMu.Unlock()
+	if b.redactRe != nil && equalStrings(b.redactFor, b.RedactFields) {
+		return b.redactRe
+	}
+	b.redactFor = append([]string(nil), b.RedactFields...)
+	b.redactRe = nil
+	if len(b.RedactFields) > 0 {
+		b.redactRe = redactFieldsRegexp(b.RedactFields)
+	}
+	return b.redactRe
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func redactFieldsRegexp(fields []string) *regexp.Regexp {
+	quoted := make([]string, len(fields))
+	for i, f := range fields {
+		quoted[i] = regexp.QuoteMeta(f)
+	}
+	return regexp.MustCompile(`"(` + strings.Join(quoted, "|") + `)"(\s*:\s*)"(?:[^"\\]|\\.)*"`)
+}
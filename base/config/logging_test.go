@@ -0,0 +1,22 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactBodyBeforeTruncate(t *testing.T) {
+	b := NewBodyLogging().WithMaxSize(15)
+	// MaxSize 截断在 token 的值中间，仍然不能输出 token 的前缀
+	s := b.RedactBody([]byte(`{"token":"abcdefghijklmnop","x":1}`))
+	assert.NotContains(t, s, "abcdef")
+	assert.Equal(t, `{"token":"*****...(9 bytes truncated)`, s)
+
+	re := b.fieldsRegexp()
+	assert.True(t, re == b.fieldsRegexp())
+	b.WithRedactFields("x")
+	assert.False(t, re == b.fieldsRegexp())
+	b.RedactFields = append(b.RedactFields, "token")
+	assert.Equal(t, `{"token":"******","x":1}`, b.WithMaxSize(0).RedactBody([]byte(`{"token":"abc","x":1}`)))
+}
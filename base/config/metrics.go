@@ -0,0 +1,28 @@
+package config
+
+import "time"
+
+const (
+	LimiterRequest = "request"
+	LimiterFlow    = "flow"
+)
+
+// MetricsCollector 收集每个请求的指标，会被多个 goroutine 并发调用
+type MetricsCollector interface {
+	// ObserveRequest 在 request.Send 返回前调用一次，包含所有重试
+	ObserveRequest(m *RequestMetrics)
+	// ObserveRetry 在每次重试前调用
+	ObserveRetry(operation string)
+	// ObserveLimiterWait 记录请求在 ratelimit.Limiter 中等待的时间，limiter 为 LimiterRequest 或 LimiterFlow
+	ObserveLimiterWait(operation, limiter string, wait time.Duration)
+}
+
+type RequestMetrics struct {
+	Operation     string
+	StatusCode    int // 没有收到响应时为0
+	Error         error
+	Latency       time.Duration
+	BytesSent     int64
+	BytesReceived int64
+	Attempts      int
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"time"
+
+	"github.com/qiniu/pandora-go-sdk/base/ratelimit"
+)
+
+const (
+	defaultAdaptiveMinRatio       = 0.1
+	defaultAdaptiveDecreaseFactor = 0.5
+	defaultAdaptiveIncreaseRatio  = 0.05
+)
+
+// AdaptiveRateLimit 开启后 RequestRateLimit 与 FlowRateLimit 作为速率上限，服务端限流(429)或返回 5xx 时
+// 按 DecreaseFactor 降低速率，之后每个 IncreaseInterval 内请求都成功则逐步恢复
+type AdaptiveRateLimit struct {
+	MinRatio         float64       // 速率下限占上限的比例
+	DecreaseFactor   float64       // 被限流时速率乘以该系数
+	IncreaseRatio    float64       // 每次恢复增加的速率占上限的比例
+	IncreaseInterval time.Duration // 两次调整速率的最小间隔
+}
+
+func NewAdaptiveRateLimit() *AdaptiveRateLimit {
+	return &AdaptiveRateLimit{
+		MinRatio:         defaultAdaptiveMinRatio,
+		DecreaseFactor:   defaultAdaptiveDecreaseFactor,
+		IncreaseRatio:    defaultAdaptiveIncreaseRatio,
+		IncreaseInterval: time.Second,
+	}
+}
+
+func (a *AdaptiveRateLimit) WithMinRatio(ratio float64) *AdaptiveRateLimit {
+	a.MinRatio = ratio
+	return a
+}
+
+func (a *AdaptiveRateLimit) WithDecreaseFactor(factor float64) *AdaptiveRateLimit {
+	a.DecreaseFactor = factor
+	return a
+}
+
+func (a *AdaptiveRateLimit) WithIncrease(ratio float64, interval time.Duration) *AdaptiveRateLimit {
+	a.IncreaseRatio, a.IncreaseInterval = ratio, interval
+	return a
+}
+
+func (a *AdaptiveRateLimit) Clone() *AdaptiveRateLimit {
+	if a == nil {
+		return nil
+	}
+	clone := *a
+	return &clone
+}
+
+// NewLimiter 创建上限为 maxRate 的限速器，a 为 nil 时速率固定不变
+func (a *AdaptiveRateLimit) NewLimiter(maxRate int64) *ratelimit.Limiter {
+	if a == nil {
+		return ratelimit.NewLimiter(maxRate)
+	}
+	return ratelimit.NewAdaptiveLimiter(maxRate, ratelimit.AdaptiveOptions{
+		MinRate:          int64(float64(maxRate) * a.MinRatio),
+		DecreaseFactor:   a.DecreaseFactor,
+		IncreaseStep:     int64(float64(maxRate) * a.IncreaseRatio),
+		IncreaseInterval: a.IncreaseInterval,
+	})
+}
+
+type BucketKey string
+
+const (
+	BucketByRepo          BucketKey = "repo"
+	BucketByResourceOwner BucketKey = "owner"
+	BucketByRepoAndOwner  BucketKey = "repo+owner"
+)
+
+// BucketRate 是单个限速桶的速率，单位与 Config 中的 RequestRateLimit/FlowRateLimit 相同，0 表示不单独限速
+type BucketRate struct {
+	RequestRateLimit int64
+	FlowRateLimit    int64
+}
+
+// RateLimitBuckets 为数据写入请求按 repo 或 ResourceOwner 分配独立的限速桶，多个桶之间公平调度，
+// Config 中的 RequestRateLimit/FlowRateLimit 作为所有桶共享的全局上限
+type RateLimitBuckets struct {
+	KeyBy BucketKey
+	BucketRate
+	// Rates 单独设置某些桶的速率，key 为 repo 名称、ResourceOwner 或者 BucketByRepoAndOwner 时的 "owner/repo"
+	Rates map[string]BucketRate
+}
+
+func NewRateLimitBuckets(keyBy BucketKey, requestRateLimit, flowRateLimit int64) *RateLimitBuckets {
+	return &RateLimitBuckets{
+		KeyBy:      keyBy,
+		BucketRate: BucketRate{RequestRateLimit: requestRateLimit, FlowRateLimit: flowRateLimit},
+		Rates:      make(map[string]BucketRate),
+	}
+}
+
+func (b *RateLimitBuckets) WithBucketRate(key string, rate BucketRate) *RateLimitBuckets {
+	if b.Rates == nil {
+		b.Rates = make(map[string]BucketRate)
+	}
+	b.Rates[key] = rate
+	return b
+}
+
+func (b *RateLimitBuckets) Clone() *RateLimitBuckets {
+	if b == nil {
+		return nil
+	}
+	clone := *b
+	clone.Rates = make(map[string]BucketRate, len(b.Rates))
+	for k, v := range b.Rates {
+		clone.Rates[k] = v
+	}
+	return &clone
+}
+
+// Key 返回请求所属的桶
+func (b *RateLimitBuckets) Key(repoName, resourceOwner string) string {
+	switch b.KeyBy {
+	case BucketByResourceOwner:
+		return resourceOwner
+	case BucketByRepoAndOwner:
+		return resourceOwner + "/" + repoName
+	}
+	return repoName
+}
+
+// NewGroups 创建请求数与流量的限速桶组，reqLimit/flowLimit 为全局上限，可以为 nil
+func (b *RateLimitBuckets) NewGroups(reqLimit, flowLimit *ratelimit.Limiter) (reqGroup, flowGroup *ratelimit.Group) {
+	reqGroup = ratelimit.NewGroup(reqLimit, b.RequestRateLimit)
+	flowGroup = ratelimit.NewGroup(flowLimit, 1024*b.FlowRateLimit)
+	for key, rate := range b.Rates {
+		reqGroup.SetBucketRate(key, rate.RequestRateLimit)
+		flowGroup.SetBucketRate(key, 1024*rate.FlowRateLimit)
+	}
+	return
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"time"
+
+	"github.com/qiniu/pandora-go-sdk/base"
+)
+
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryBaseBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff  = 5 * time.Second
+	defaultRetryJitter      = 0.5
+)
+
+// RetryPolicy 描述 request.Send 失败后的重试策略，Config.RetryPolicy 为 nil 时不重试
+type RetryPolicy struct {
+	MaxAttempts int           // 包含首次请求在内的最大尝试次数，小于等于1表示不重试
+	BaseBackoff time.Duration // 第一次重试前的等待时间，之后每次翻倍
+	MaxBackoff  time.Duration // 单次等待时间的上限
+	Jitter      float64       // 取值[0,1]，等待时间中随机化的比例，避免大量客户端同时重试
+
+	RetryableStatusCodes []int // 服务端返回这些状态码时重试
+	RetryableErrorTypes  []int // reqerr.RequestError 的 ErrorType 属于其中时重试
+	RetryOnNetworkError  bool  // 连接失败、连接被重置等传输层错误时重试
+
+	// 默认只重试幂等的请求(GET/PUT/DELETE)，POST 请求需要在此显式列出 Operation 名称，如 base.OpPostData
+	RetryOperations []string
+}
+
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:          defaultRetryMaxAttempts,
+		BaseBackoff:          defaultRetryBaseBackoff,
+		MaxBackoff:           defaultRetryMaxBackoff,
+		Jitter:               defaultRetryJitter,
+		RetryableStatusCodes: []int{429, 500, 502, 503, 504},
+		RetryOnNetworkError:  true,
+	}
+}
+
+func (p *RetryPolicy) WithMaxAttempts(n int) *RetryPolicy {
+	p.MaxAttempts = n
+	return p
+}
+
+func (p *RetryPolicy) WithBackoff(base, max time.Duration) *RetryPolicy {
+	p.BaseBackoff, p.MaxBackoff = base, max
+	return p
+}
+
+func (p *RetryPolicy) WithJitter(jitter float64) *RetryPolicy {
+	p.Jitter = jitter
+	return p
+}
+
+func (p *RetryPolicy) WithRetryableStatusCodes(codes ...int) *RetryPolicy {
+	p.RetryableStatusCodes = codes
+	return p
+}
+
+func (p *RetryPolicy) WithRetryableErrorTypes(types ...int) *RetryPolicy {
+	p.RetryableErrorTypes = types
+	return p
+}
+
+func (p *RetryPolicy) WithRetryOperations(ops ...string) *RetryPolicy {
+	p.RetryOperations = ops
+	return p
+}
+
+func (p *RetryPolicy) Clone() *RetryPolicy {
+	if p == nil {
+		return nil
+	}
+	np := *p
+	np.RetryableStatusCodes = append([]int(nil), p.RetryableStatusCodes...)
+	np.RetryableErrorTypes = append([]int(nil), p.RetryableErrorTypes...)
+	np.RetryOperations = append([]string(nil), p.RetryOperations...)
+	return &np
+}
+
+// Backoff 返回第 attempt 次重试(从1开始)前需要等待的时间，rnd 为[0,1)的随机数
+func (p *RetryPolicy) Backoff(attempt int, rnd float64) time.Duration {
+	if attempt < 1 || p.BaseBackoff <= 0 {
+		return 0
+	}
+	d := p.BaseBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	jitter := p.Jitter
+	if jitter > 1 {
+		jitter = 1
+	}
+	if jitter > 0 {
+		d -= time.Duration(float64(d) * jitter * rnd)
+	}
+	return d
+}
+
+func (p *RetryPolicy) IsRetryableOperation(opName, method string) bool {
+	switch method {
+	case base.MethodGet, base.MethodPut, base.MethodDelete:
+		return true
+	}
+	for _, op := range p.RetryOperations {
+		if op == opName {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) IsRetryableStatusCode(code int) bool {
+	for _, c := range p.RetryableStatusCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) IsRetryableErrorType(errorType int) bool {
+	for _, t := range p.RetryableErrorTypes {
+		if t == errorType {
+			return true
+		}
+	}
+	return false
+}
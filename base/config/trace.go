@@ -0,0 +1,28 @@
+package config
+
+import "context"
+
+// Tracer 为每个请求创建一个 span，可以适配 OpenTelemetry 等链路追踪系统，base/trace 提供了一个简单实现
+type Tracer interface {
+	// StartSpan 以 ctx 中的 span 为父 span 创建新的 span，返回的 ctx 中需要包含新 span
+	StartSpan(ctx context.Context, operation string) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttribute(key string, value interface{})
+	// TraceParent 返回 W3C Trace Context 格式的 traceparent 请求头，为空时不注入
+	TraceParent() string
+	// End 结束 span，err 为请求最终的错误
+	End(err error)
+}
+
+// span 属性名
+const (
+	SpanAttrOperation   = "pandora.operation"
+	SpanAttrRepo        = "pandora.repo"
+	SpanAttrRequestId   = "pandora.request_id"
+	SpanAttrAttempts    = "pandora.attempts"
+	SpanAttrMethod      = "http.method"
+	SpanAttrStatusCode  = "http.status_code"
+	SpanAttrRequestSize = "http.request_content_length"
+)
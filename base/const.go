@@ -8,13 +8,14 @@ const (
 )
 
 const (
-	HTTPHeaderAppId         string = "X-AppId"
-	HTTPHeaderContentType   string = "Content-Type"
-	HTTPHeaderContentLength string = "Content-Length"
-	HTTPHeaderContentMD5    string = "Content-MD5"
-	HTTPHeaderRequestId     string = "X-Reqid"
-	HTTPHeaderAuthorization string = "Authorization"
-	HTTPHeaderResourceOwner string = "X-Resource-Owner"
+	HTTPHeaderAppId           string = "X-AppId"
+	HTTPHeaderContentType     string = "Content-Type"
+	HTTPHeaderContentLength   string = "Content-Length"
+	HTTPHeaderContentMD5      string = "Content-MD5"
+	HTTPHeaderContentEncoding string = "Content-Encoding"
+	HTTPHeaderRequestId       string = "X-Reqid"
+	HTTPHeaderAuthorization   string = "Authorization"
+	HTTPHeaderResourceOwner   string = "X-Resource-Owner"
 )
 
 const (
@@ -0,0 +1,88 @@
+package base
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 结构化日志中 SDK 使用的字段名
+const (
+	FieldOperation = "operation"
+	FieldRepo      = "repo"
+	FieldRequestId = "request_id"
+	FieldStatus    = "status"
+	FieldLatency   = "latency"
+	FieldAttempt   = "attempt"
+	FieldError     = "error"
+)
+
+// Field 是结构化日志中的一个键值对
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// StructuredLogger 输出带键值对字段的日志，便于日志系统按 operation、repo、request_id 等字段检索
+type StructuredLogger interface {
+	Log(level LogLevelType, msg string, fields ...Field)
+	// Enabled 返回 level 级别的日志是否会被输出，用于跳过开销较大的日志内容构造
+	Enabled(level LogLevelType) bool
+}
+
+type fieldLogger struct {
+	l Logger
+}
+
+// NewFieldLogger 把 printf 风格的 Logger 适配为 StructuredLogger，字段以 key=value 的形式追加在消息之后
+func NewFieldLogger(l Logger) StructuredLogger {
+	return &fieldLogger{l: l}
+}
+
+func (f *fieldLogger) Enabled(level LogLevelType) bool {
+	if l, ok := f.l.(interface{ AtMost(LogLevelType) bool }); ok {
+		return l.AtMost(level)
+	}
+	return true
+}
+
+func (f *fieldLogger) Log(level LogLevelType, msg string, fields ...Field) {
+	if !f.Enabled(level) {
+		return
+	}
+	line := FormatFields(msg, fields...)
+	switch level {
+	case LogDebug:
+		f.l.Debug(line)
+	case LogInfo:
+		f.l.Info(line)
+	case LogWarn:
+		f.l.Warn(line)
+	case LogError:
+		f.l.Error(line)
+	case LogPanic:
+		f.l.Panic(line)
+	case LogFatal:
+		f.l.Fatal(line)
+	}
+}
+
+// FormatFields 把消息与字段格式化为 `msg key1=value1 key2="value 2"` 的形式
+func FormatFields(msg string, fields ...Field) string {
+	var b strings.Builder
+	b.WriteString(msg)
+	for _, field := range fields {
+		v := fmt.Sprint(field.Value)
+		if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+			v = fmt.Sprintf("%q", v)
+		}
+		b.WriteByte(' ')
+		b.WriteString(field.Key)
+		b.WriteByte('=')
+		b.WriteString(v)
+	}
+	return b.String()
+}
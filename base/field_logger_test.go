@@ -0,0 +1,28 @@
+package base
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldLogger(t *testing.T) {
+	assert.Equal(t, `send request failed operation=PostData status=500 latency=1.5s error="E9000: internal error"`,
+		FormatFields("send request failed", F(FieldOperation, "PostData"), F(FieldStatus, 500),
+			F(FieldLatency, 1500*time.Millisecond), F(FieldError, errors.New("E9000: internal error"))))
+	assert.Equal(t, `msg repo=""`, FormatFields("msg", F(FieldRepo, "")))
+
+	var buf bytes.Buffer
+	l := &DefaultLogger{Logger: log.New(&buf, "", 0), rwMu: &sync.RWMutex{}}
+	l.SetLoggerLevel(LogWarn)
+	fl := NewFieldLogger(l)
+	assert.False(t, fl.Enabled(LogInfo))
+	fl.Log(LogInfo, "ignored", F(FieldRepo, "repo"))
+	fl.Log(LogWarn, "retrying", F(FieldRepo, "repo"), F(FieldAttempt, 2))
+	assert.Equal(t, "WARN: retrying repo=repo attempt=2\n", buf.String())
+}
@@ -0,0 +1,233 @@
+// Package metrics 提供 config.MetricsCollector 的内置实现，以 Prometheus 文本格式输出 SDK 的请求指标
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qiniu/pandora-go-sdk/base/config"
+)
+
+const defaultNamespace = "pandora_sdk"
+
+// DefaultBuckets 是请求耗时直方图默认的分桶上界，单位为秒
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+type requestKey struct {
+	operation string
+	code      string
+}
+
+type limiterKey struct {
+	operation string
+	limiter   string
+}
+
+type histogram struct {
+	counts []uint64 // 与 buckets 一一对应，非累计
+	sum    float64
+	count  uint64
+}
+
+// PrometheusCollector 在内存中聚合请求指标，实现了 http.Handler，可以直接挂载到 /metrics 供 Prometheus 抓取
+type PrometheusCollector struct {
+	namespace string
+	buckets   []float64
+
+	mu            sync.Mutex
+	requests      map[requestKey]uint64
+	errors        map[string]uint64
+	latency       map[string]*histogram
+	bytesSent     map[string]uint64
+	bytesReceived map[string]uint64
+	retries       map[string]uint64
+	limiterWait   map[limiterKey]float64
+}
+
+var _ config.MetricsCollector = (*PrometheusCollector)(nil)
+
+// NewPrometheusCollector 创建指标收集器，namespace 为空时使用 pandora_sdk，buckets 为空时使用 DefaultBuckets
+func NewPrometheusCollector(namespace string, buckets ...float64) *PrometheusCollector {
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &PrometheusCollector{
+		namespace:     namespace,
+		buckets:       buckets,
+		requests:      make(map[requestKey]uint64),
+		errors:        make(map[string]uint64),
+		latency:       make(map[string]*histogram),
+		bytesSent:     make(map[string]uint64),
+		bytesReceived: make(map[string]uint64),
+		retries:       make(map[string]uint64),
+		limiterWait:   make(map[limiterKey]float64),
+	}
+}
+
+func (c *PrometheusCollector) ObserveRequest(m *config.RequestMetrics) {
+	code := "none"
+	if m.StatusCode != 0 {
+		code = strconv.Itoa(m.StatusCode)
+	}
+	seconds := m.Latency.Seconds()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests[requestKey{m.Operation, code}]++
+	if m.Error != nil {
+		c.errors[m.Operation]++
+	}
+	h, ok := c.latency[m.Operation]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(c.buckets))}
+		c.latency[m.Operation] = h
+	}
+	for i, upper := range c.buckets {
+		if seconds <= upper {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += seconds
+	h.count++
+	c.bytesSent[m.Operation] += uint64(m.BytesSent)
+	c.bytesReceived[m.Operation] += uint64(m.BytesReceived)
+}
+
+func (c *PrometheusCollector) ObserveRetry(operation string) {
+	c.mu.Lock()
+	c.retries[operation]++
+	c.mu.Unlock()
+}
+
+func (c *PrometheusCollector) ObserveLimiterWait(operation, limiter string, wait time.Duration) {
+	c.mu.Lock()
+	c.limiterWait[limiterKey{operation, limiter}] += wait.Seconds()
+	c.mu.Unlock()
+}
+
+// WriteTo 以 Prometheus 文本格式(version 0.0.4)输出当前所有指标
+func (c *PrometheusCollector) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: bufio.NewWriter(w)}
+	c.mu.Lock()
+	c.write(cw)
+	c.mu.Unlock()
+	if cw.err == nil {
+		cw.err = cw.w.(*bufio.Writer).Flush()
+	}
+	return cw.n, cw.err
+}
+
+func (c *PrometheusCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+func (c *PrometheusCollector) write(w *countWriter) {
+	name := c.namespace + "_requests_total"
+	w.header(name, "counter", "Total number of requests by operation and http status code.")
+	reqKeys := make([]requestKey, 0, len(c.requests))
+	for k := range c.requests {
+		reqKeys = append(reqKeys, k)
+	}
+	sort.Slice(reqKeys, func(i, j int) bool {
+		if reqKeys[i].operation != reqKeys[j].operation {
+			return reqKeys[i].operation < reqKeys[j].operation
+		}
+		return reqKeys[i].code < reqKeys[j].code
+	})
+	for _, k := range reqKeys {
+		w.printf("%s{operation=%q,code=%q} %d\n", name, k.operation, k.code, c.requests[k])
+	}
+
+	c.writeCounter(w, "_request_errors_total", "Total number of failed requests by operation.", c.errors)
+	c.writeCounter(w, "_request_retries_total", "Total number of retries by operation.", c.retries)
+	c.writeCounter(w, "_request_sent_bytes_total", "Total bytes of request bodies sent by operation, including retries.", c.bytesSent)
+	c.writeCounter(w, "_response_received_bytes_total", "Total bytes of response bodies received by operation.", c.bytesReceived)
+
+	name = c.namespace + "_request_duration_seconds"
+	w.header(name, "histogram", "Request latency in seconds by operation, including retries.")
+	for _, op := range sortedKeys(c.latency) {
+		h := c.latency[op]
+		var cumulative uint64
+		for i, upper := range c.buckets {
+			cumulative += h.counts[i]
+			w.printf("%s_bucket{operation=%q,le=%q} %d\n", name, op, formatFloat(upper), cumulative)
+		}
+		w.printf("%s_bucket{operation=%q,le=\"+Inf\"} %d\n", name, op, h.count)
+		w.printf("%s_sum{operation=%q} %s\n", name, op, formatFloat(h.sum))
+		w.printf("%s_count{operation=%q} %d\n", name, op, h.count)
+	}
+
+	name = c.namespace + "_ratelimit_wait_seconds_total"
+	w.header(name, "counter", "Total time in seconds requests spent blocked in rate limiters.")
+	waitKeys := make([]limiterKey, 0, len(c.limiterWait))
+	for k := range c.limiterWait {
+		waitKeys = append(waitKeys, k)
+	}
+	sort.Slice(waitKeys, func(i, j int) bool {
+		if waitKeys[i].operation != waitKeys[j].operation {
+			return waitKeys[i].operation < waitKeys[j].operation
+		}
+		return waitKeys[i].limiter < waitKeys[j].limiter
+	})
+	for _, k := range waitKeys {
+		w.printf("%s{operation=%q,limiter=%q} %s\n", name, k.operation, k.limiter, formatFloat(c.limiterWait[k]))
+	}
+}
+
+func (c *PrometheusCollector) writeCounter(w *countWriter, suffix, help string, values map[string]uint64) {
+	name := c.namespace + suffix
+	w.header(name, "counter", help)
+	ops := make([]string, 0, len(values))
+	for op := range values {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	for _, op := range ops {
+		w.printf("%s{operation=%q} %d\n", name, op, values[op])
+	}
+}
+
+func sortedKeys(m map[string]*histogram) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+type countWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (w *countWriter) header(name, typ, help string) {
+	w.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func (w *countWriter) printf(format string, args ...interface{}) {
+	if w.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(w.w, format, args...)
+	w.n += int64(n)
+	w.err = err
+}
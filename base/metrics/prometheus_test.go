@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/qiniu/pandora-go-sdk/base"
+	"github.com/qiniu/pandora-go-sdk/base/config"
+	"github.com/qiniu/pandora-go-sdk/base/ratelimit"
+	"github.com/qiniu/pandora-go-sdk/base/reqerr"
+	"github.com/qiniu/pandora-go-sdk/base/request"
+)
+
+type errBuilder struct{}
+
+func (errBuilder) Build(message, rawText, reqId string, statusCode int) error {
+	return reqerr.New(message, rawText, reqId, statusCode)
+}
+
+func TestPrometheusCollector(t *testing.T) {
+	fail := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if fail {
+			fail = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	collector := NewPrometheusCollector("", 0.5, 0.1)
+	cfg := (&config.Config{Endpoint: ts.URL}).
+		WithMetricsCollector(collector).
+		WithRetryPolicy(config.NewRetryPolicy().WithBackoff(time.Millisecond, time.Millisecond).WithRetryOperations(base.OpPostData))
+	op := &request.Operation{Name: base.OpPostData, Method: base.MethodPost, Path: "/v2/repos/repo/data"}
+	req := request.New(cfg, http.DefaultClient, op, "", errBuilder{}, nil)
+	req.SetBufferBody([]byte("a=1"))
+	req.SetReqLimiter(ratelimit.NewLimiter(1000))
+	assert.NoError(t, req.Send())
+
+	collector.ObserveRequest(&config.RequestMetrics{Operation: base.OpGetRepo, Error: reqerr.NewInvalidArgs("RepoName", "invalid"), Latency: 2 * time.Second})
+
+	var buf bytes.Buffer
+	_, err := collector.WriteTo(&buf)
+	assert.NoError(t, err)
+	out := buf.String()
+	for _, line := range []string{
+		`pandora_sdk_requests_total{operation="PostData",code="200"} 1`,
+		`pandora_sdk_requests_total{operation="GetRepo",code="none"} 1`,
+		`pandora_sdk_request_errors_total{operation="GetRepo"} 1`,
+		`pandora_sdk_request_retries_total{operation="PostData"} 1`,
+		`pandora_sdk_request_sent_bytes_total{operation="PostData"} 6`,
+		`pandora_sdk_response_received_bytes_total{operation="PostData"} 2`,
+		`pandora_sdk_request_duration_seconds_bucket{operation="PostData",le="0.1"} 1`,
+		`pandora_sdk_request_duration_seconds_bucket{operation="GetRepo",le="0.5"} 0`,
+		`pandora_sdk_request_duration_seconds_bucket{operation="GetRepo",le="+Inf"} 1`,
+		`pandora_sdk_request_duration_seconds_count{operation="GetRepo"} 1`,
+		`# TYPE pandora_sdk_ratelimit_wait_seconds_total counter`,
+		`pandora_sdk_ratelimit_wait_seconds_total{operation="PostData",limiter="request"}`,
+	} {
+		assert.Contains(t, out, line)
+	}
+}
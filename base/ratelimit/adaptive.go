@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultDecreaseFactor   = 0.5
+	defaultIncreaseInterval = time.Second
+)
+
+// AdaptiveOptions 描述 AIMD(加性增、乘性减) 调整速率的参数
+type AdaptiveOptions struct {
+	MinRate          int64         // 速率下限，默认为上限的 1/10
+	DecreaseFactor   float64       // 被限流时速率乘以该系数，默认 0.5
+	IncreaseStep     int64         // 每个 IncreaseInterval 内请求都成功时增加的速率，默认为上限的 1/20
+	IncreaseInterval time.Duration // 两次调整速率的最小间隔，默认 1s
+}
+
+type adaptiveState struct {
+	mu         sync.Mutex
+	opts       AdaptiveOptions
+	maxRate    int64
+	rate       int64
+	lastChange time.Time
+	throttled  bool // 上次调整后是否又被限流
+}
+
+// NewAdaptiveLimiter 创建根据服务端反馈调整速率的 Limiter，初始速率与上限均为 maxRate。
+// 调用方在服务端限流或返回 5xx 时调用 OnThrottled，请求成功时调用 OnSuccess
+func NewAdaptiveLimiter(maxRate int64, opts AdaptiveOptions) *Limiter {
+	if opts.MinRate <= 0 {
+		opts.MinRate = maxRate / 10
+	}
+	if opts.MinRate <= 0 {
+		opts.MinRate = 1
+	}
+	if opts.MinRate > maxRate {
+		opts.MinRate = maxRate
+	}
+	if opts.DecreaseFactor <= 0 || opts.DecreaseFactor >= 1 {
+		opts.DecreaseFactor = defaultDecreaseFactor
+	}
+	if opts.IncreaseStep <= 0 {
+		opts.IncreaseStep = maxRate / 20
+	}
+	if opts.IncreaseStep <= 0 {
+		opts.IncreaseStep = 1
+	}
+	if opts.IncreaseInterval <= 0 {
+		opts.IncreaseInterval = defaultIncreaseInterval
+	}
+	l := NewLimiter(maxRate)
+	l.adaptive = &adaptiveState{
+		opts:       opts,
+		maxRate:    maxRate,
+		rate:       maxRate,
+		lastChange: time.Now(),
+	}
+	return l
+}
+
+// OnThrottled 按 DecreaseFactor 降低速率，同一个 IncreaseInterval 内多个并发请求同时被限流只降低一次。
+// 不是 NewAdaptiveLimiter 创建的 Limiter 调用时不做任何事，Group 中的桶会把反馈传给全局限速器
+func (self *Limiter) OnThrottled() {
+	if self.bucket != nil && self.bucket.parent != nil {
+		self.bucket.parent.OnThrottled()
+	}
+	a := self.adaptive
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	now := time.Now()
+	if a.throttled && now.Sub(a.lastChange) < a.opts.IncreaseInterval {
+		a.mu.Unlock()
+		return
+	}
+	rate := int64(float64(a.rate) * a.opts.DecreaseFactor)
+	if rate < a.opts.MinRate {
+		rate = a.opts.MinRate
+	}
+	a.rate, a.lastChange, a.throttled = rate, now, true
+	a.mu.Unlock()
+	self.SetRateLimit(rate)
+}
+
+// OnSuccess 距离上次调整超过 IncreaseInterval 时按 IncreaseStep 提高速率，直到恢复到上限
+func (self *Limiter) OnSuccess() {
+	if self.bucket != nil && self.bucket.parent != nil {
+		self.bucket.parent.OnSuccess()
+	}
+	a := self.adaptive
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	now := time.Now()
+	if a.rate >= a.maxRate || now.Sub(a.lastChange) < a.opts.IncreaseInterval {
+		a.mu.Unlock()
+		return
+	}
+	rate := a.rate + a.opts.IncreaseStep
+	if rate > a.maxRate {
+		rate = a.maxRate
+	}
+	a.rate, a.lastChange, a.throttled = rate, now, false
+	a.mu.Unlock()
+	self.SetRateLimit(rate)
+}
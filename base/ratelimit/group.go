@@ -0,0 +1,169 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+)
+
+// bucketState 是 Group 中的桶相对普通 Limiter 多出的状态
+type bucketState struct {
+	group  *Group
+	parent *Limiter      // 全局上限，可以为 nil
+	turn   chan struct{} // 同一个桶同时只有一个请求在等待配额
+}
+
+// groupWaiter 是在全局上限前排队的一个桶，排在队首时 ready 被关闭
+type groupWaiter struct {
+	ready chan struct{}
+}
+
+// Group 按 key(如 repo 名称或 ResourceOwner) 维护相互独立的限速桶，所有桶共享 global 作为全局上限。
+// 每个桶同一时刻只有一个请求在全局上限前按先来先得排队，各个桶轮流获得全局配额，
+// 一个桶积压的大量请求不会挤占其他桶的配额
+type Group struct {
+	global *Limiter
+	rate   int64
+
+	mu      sync.Mutex
+	rates   map[string]int64
+	buckets map[string]*Limiter
+
+	queueMu sync.Mutex
+	queue   []*groupWaiter
+}
+
+// NewGroup 创建限速桶组，global 为 nil 表示没有全局上限；bucketRate 是每个桶默认的速率，小于等于 0 表示桶不单独限速，只参与公平调度
+func NewGroup(global *Limiter, bucketRate int64) *Group {
+	return &Group{
+		global:  global,
+		rate:    bucketRate,
+		rates:   make(map[string]int64),
+		buckets: make(map[string]*Limiter),
+	}
+}
+
+// SetBucketRate 单独设置某个桶的速率，桶已经创建时立即生效
+func (g *Group) SetBucketRate(key string, rate int64) {
+	g.mu.Lock()
+	g.rates[key] = rate
+	b, ok := g.buckets[key]
+	g.mu.Unlock()
+	if ok {
+		b.SetRateLimit(rate)
+	}
+}
+
+// Bucket 返回 key 对应的桶，不存在时创建。返回的 Limiter 先从桶中获取配额，再从全局上限中获取
+func (g *Group) Bucket(key string) *Limiter {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if b, ok := g.buckets[key]; ok {
+		return b
+	}
+	rate, ok := g.rates[key]
+	if !ok {
+		rate = g.rate
+	}
+	b := NewLimiter(rate)
+	b.bucket = &bucketState{group: g, parent: g.global, turn: make(chan struct{}, 1)}
+	g.buckets[key] = b
+	return b
+}
+
+// Close 停止所有桶，全局限速器由调用方负责关闭
+func (g *Group) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, b := range g.buckets {
+		b.Close()
+	}
+	return nil
+}
+
+func (self *Limiter) assignBucket(ctx context.Context, size int64) (int64, error) {
+	b := self.bucket
+	select {
+	case b.turn <- struct{}{}:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	defer func() { <-b.turn }()
+
+	limited := self.ownRateLimit() > 0
+	if limited {
+		var err error
+		if size, err = self.assign(ctx, size); err != nil {
+			return 0, err
+		}
+	}
+	if b.parent == nil {
+		return size, nil
+	}
+	w, err := b.group.wait(ctx)
+	if err != nil {
+		if limited {
+			self.fill(size)
+		}
+		return 0, err
+	}
+	n, err := b.parent.AssignWithContext(ctx, size)
+	b.group.done(w)
+	if limited && n < size {
+		self.fill(size - n)
+	}
+	return n, err
+}
+
+// wait 排队直到轮到调用方从全局上限中获取配额
+func (g *Group) wait(ctx context.Context) (*groupWaiter, error) {
+	w := &groupWaiter{ready: make(chan struct{})}
+	g.queueMu.Lock()
+	g.queue = append(g.queue, w)
+	if len(g.queue) == 1 {
+		close(w.ready)
+	}
+	g.queueMu.Unlock()
+	select {
+	case <-w.ready:
+		return w, nil
+	case <-ctx.Done():
+		g.done(w)
+		return nil, ctx.Err()
+	}
+}
+
+// done 把 w 移出队列，w 位于队首时唤醒下一个
+func (g *Group) done(w *groupWaiter) {
+	g.queueMu.Lock()
+	defer g.queueMu.Unlock()
+	for i, q := range g.queue {
+		if q != w {
+			continue
+		}
+		g.queue = append(g.queue[:i], g.queue[i+1:]...)
+		if i == 0 && len(g.queue) > 0 {
+			close(g.queue[0].ready)
+		}
+		return
+	}
+}
+
+func (self *Limiter) fillBucket(size int64) {
+	if self.ownRateLimit() > 0 {
+		self.fill(size)
+	}
+	if self.bucket.parent != nil {
+		self.bucket.parent.Fill(size)
+	}
+}
+
+// bucketRateLimit 返回桶实际生效的速率，即桶与全局上限中较小的一个，都不限速时返回 0
+func (self *Limiter) bucketRateLimit() int64 {
+	rate := self.ownRateLimit()
+	if parent := self.bucket.parent; parent != nil {
+		if p := parent.GetRateLimit(); rate <= 0 || (p > 0 && p < rate) {
+			rate = p
+		}
+	}
+	return rate
+}
@@ -2,6 +2,7 @@
 package ratelimit
 
 import (
+	"context"
 	"sync"
 	"time"
 )
@@ -15,27 +16,68 @@ type Limiter struct {
 	cond          *sync.Cond
 	done          chan struct{}
 	ratePerSecond int64
+	quantity      int64 // 每个 Window 补充的配额
 	closeOnce     *sync.Once
+	adaptive      *adaptiveState
+	bucket        *bucketState
 }
 
 // NewLimiter 限速的最小粒度是20
 func NewLimiter(ratePerSecond int64) *Limiter {
-	quantity := (ratePerSecond*int64(Window) + int64(time.Second)) / int64(time.Second)
 	self := &Limiter{
 		ratePerSecond: ratePerSecond,
 		threshold:     ratePerSecond,
 		capacity:      ratePerSecond,
+		quantity:      windowQuantity(ratePerSecond),
 		cond:          sync.NewCond(new(sync.Mutex)),
 		done:          make(chan struct{}, 1),
 		closeOnce:     &sync.Once{},
 	}
-	go self.run(quantity)
+	go self.run()
 	return self
 }
 
+func windowQuantity(ratePerSecond int64) int64 {
+	return (ratePerSecond*int64(Window) + int64(time.Second)) / int64(time.Second)
+}
+
 func (self *Limiter) Assign(size int64) int64 {
+	size, _ = self.AssignWithContext(context.Background(), size)
+	return size
+}
+
+// AssignWithContext 与 Assign 相同，但在等待配额时若 ctx 被取消或超时则立即返回 ctx.Err()
+func (self *Limiter) AssignWithContext(ctx context.Context, size int64) (int64, error) {
+	if self.bucket != nil {
+		return self.assignBucket(ctx, size)
+	}
+	return self.assign(ctx, size)
+}
+
+func (self *Limiter) assign(ctx context.Context, size int64) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
 	self.cond.L.Lock()
+	if self.capacity == 0 && ctx.Done() != nil {
+		stop := make(chan struct{})
+		defer close(stop)
+		go func() {
+			select {
+			case <-ctx.Done():
+				// 先拿锁保证等待方已经进入 Wait，再唤醒
+				self.cond.L.Lock()
+				self.cond.L.Unlock()
+				self.cond.Broadcast()
+			case <-stop:
+			}
+		}()
+	}
 	for self.capacity == 0 {
+		if err := ctx.Err(); err != nil {
+			self.cond.L.Unlock()
+			return 0, err
+		}
 		self.cond.Wait()
 	}
 	if size > self.capacity {
@@ -43,10 +85,18 @@ func (self *Limiter) Assign(size int64) int64 {
 	}
 	self.capacity -= size
 	self.cond.L.Unlock()
-	return size
+	return size, nil
 }
 
 func (self *Limiter) Fill(size int64) {
+	if self.bucket != nil {
+		self.fillBucket(size)
+		return
+	}
+	self.fill(size)
+}
+
+func (self *Limiter) fill(size int64) {
 	if size <= 0 {
 		return
 	}
@@ -60,13 +110,13 @@ func (self *Limiter) Fill(size int64) {
 	self.cond.Broadcast()
 }
 
-func (self *Limiter) run(quantity int64) {
+func (self *Limiter) run() {
 	t := time.NewTicker(Window)
 	for {
 		select {
 		case <-t.C:
 			self.cond.L.Lock()
-			self.capacity += quantity
+			self.capacity += self.quantity
 			if self.capacity >= self.threshold {
 				self.capacity = self.threshold
 			}
@@ -88,5 +138,26 @@ func (self *Limiter) Close() error {
 }
 
 func (self *Limiter) GetRateLimit() int64 {
+	if self.bucket != nil {
+		return self.bucketRateLimit()
+	}
+	return self.ownRateLimit()
+}
+
+func (self *Limiter) ownRateLimit() int64 {
+	self.cond.L.Lock()
+	defer self.cond.L.Unlock()
 	return self.ratePerSecond
 }
+
+// SetRateLimit 修改每秒的速率限制，已有的配额超过新的上限时被截断
+func (self *Limiter) SetRateLimit(ratePerSecond int64) {
+	self.cond.L.Lock()
+	self.ratePerSecond = ratePerSecond
+	self.threshold = ratePerSecond
+	self.quantity = windowQuantity(ratePerSecond)
+	if self.capacity > self.threshold {
+		self.capacity = self.threshold
+	}
+	self.cond.L.Unlock()
+}
@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignWithContext(t *testing.T) {
+	l := NewLimiter(10)
+	n, err := l.AssignWithContext(context.Background(), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+
+	// 停止补充配额后耗尽，之后的等待只能被 ctx 打断
+	l.Close()
+	time.Sleep(2 * Window)
+	l.Assign(l.GetRateLimit())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = l.AssignWithContext(ctx, 1)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = l.AssignWithContext(ctx, 1)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestAdaptiveLimiter(t *testing.T) {
+	l := NewAdaptiveLimiter(100, AdaptiveOptions{IncreaseInterval: 20 * time.Millisecond})
+	defer l.Close()
+	assert.Equal(t, int64(100), l.GetRateLimit())
+
+	// 同一个间隔内的多次限流只降速一次
+	l.OnThrottled()
+	l.OnThrottled()
+	assert.Equal(t, int64(50), l.GetRateLimit())
+	time.Sleep(30 * time.Millisecond)
+	l.OnThrottled()
+	assert.Equal(t, int64(25), l.GetRateLimit())
+	for i := 0; i < 5; i++ {
+		time.Sleep(30 * time.Millisecond)
+		l.OnThrottled()
+	}
+	assert.Equal(t, int64(10), l.GetRateLimit())
+
+	// 成功后按间隔逐步恢复，不超过上限
+	l.OnSuccess()
+	assert.Equal(t, int64(10), l.GetRateLimit())
+	time.Sleep(30 * time.Millisecond)
+	l.OnSuccess()
+	assert.Equal(t, int64(15), l.GetRateLimit())
+	for i := 0; i < 20; i++ {
+		time.Sleep(25 * time.Millisecond)
+		l.OnSuccess()
+	}
+	assert.Equal(t, int64(100), l.GetRateLimit())
+
+	// 普通的 Limiter 不受影响
+	fixed := NewLimiter(100)
+	defer fixed.Close()
+	fixed.OnThrottled()
+	assert.Equal(t, int64(100), fixed.GetRateLimit())
+}
+
+func TestGroup(t *testing.T) {
+	global := NewLimiter(100)
+	defer global.Close()
+	global.Assign(100)
+	g := NewGroup(global, 0)
+	g.SetBucketRate("limited", 20)
+	defer g.Close()
+
+	// 一个桶积压大量请求时，其他桶仍然轮流获得全局配额
+	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Millisecond)
+	defer cancel()
+	run := func(key string, workers int, total chan<- int) {
+		counts := make(chan int)
+		for i := 0; i < workers; i++ {
+			go func() {
+				n := 0
+				for {
+					if _, err := g.Bucket(key).AssignWithContext(ctx, 1); err != nil {
+						counts <- n
+						return
+					}
+					n++
+					time.Sleep(5 * time.Millisecond)
+				}
+			}()
+		}
+		sum := 0
+		for i := 0; i < workers; i++ {
+			sum += <-counts
+		}
+		total <- sum
+	}
+	noisy, quiet := make(chan int), make(chan int)
+	go run("noisy", 20, noisy)
+	go run("quiet", 2, quiet)
+	n1, n2 := <-noisy, <-quiet
+	assert.True(t, n2*3 > n1, "noisy %d quiet %d", n1, n2)
+
+	// 单独限速的桶不超过自身速率，也不超过全局上限
+	assert.Equal(t, int64(20), g.Bucket("limited").GetRateLimit())
+	assert.Equal(t, int64(100), g.Bucket("other").GetRateLimit())
+	n, err := g.Bucket("limited").AssignWithContext(context.Background(), 100)
+	assert.NoError(t, err)
+	assert.True(t, n <= 20)
+
+	// 没有全局上限、桶也不限速时不等待
+	free := NewGroup(nil, 0)
+	defer free.Close()
+	n, err = free.Bucket("any").AssignWithContext(context.Background(), 1<<20)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1<<20), n)
+}
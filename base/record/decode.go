@@ -0,0 +1,280 @@
+// Package record 把 pipeline 采样数据、logdb 查询结果等记录解码到结构体中，
+// 结构体字段的名称使用与 pipeline.SchemaFromStruct 相同的 pandora 标签
+package record
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TagName 是描述结构体字段对应的 repo 字段的标签，格式为 `pandora:"<字段名称>,<类型>,required"`，解码时只使用字段名称
+const TagName = "pandora"
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	ipType   = reflect.TypeOf(net.IP{})
+)
+
+// Unmarshal 解析 JSON 格式的记录数组并解码到 dst 指向的 slice，long 类型的数字不会经过 float64 转换而损失精度
+func Unmarshal(data []byte, dst interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var records []map[string]interface{}
+	if err := dec.Decode(&records); err != nil {
+		return err
+	}
+	return DecodeSlice(records, dst)
+}
+
+// DecodeSlice 把多条记录解码到 dst 指向的 slice，slice 的元素可以是结构体或结构体指针
+func DecodeSlice(records []map[string]interface{}, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("record: decode destination must be a non-nil pointer to slice, got %T", dst)
+	}
+	slice := rv.Elem()
+	out := reflect.MakeSlice(slice.Type(), len(records), len(records))
+	for i, r := range records {
+		if err := decodeValue(r, out.Index(i)); err != nil {
+			return fmt.Errorf("record %d: %v", i, err)
+		}
+	}
+	slice.Set(out)
+	return nil
+}
+
+// Decode 把一条记录解码到 dst 指向的结构体：
+// date 类型的字符串解码为 time.Time，jsonstring 类型的字符串可以解码为结构体、map 或 slice，ip 解码为 net.IP
+func Decode(record map[string]interface{}, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("record: decode destination must be a non-nil pointer, got %T", dst)
+	}
+	return decodeValue(record, rv.Elem())
+}
+
+func decodeValue(src interface{}, dv reflect.Value) (err error) {
+	if src == nil {
+		return nil
+	}
+	switch dv.Kind() {
+	case reflect.Ptr:
+		if dv.IsNil() {
+			dv.Set(reflect.New(dv.Type().Elem()))
+		}
+		return decodeValue(src, dv.Elem())
+	case reflect.Interface:
+		if dv.NumMethod() == 0 {
+			dv.Set(reflect.ValueOf(src))
+			return nil
+		}
+	}
+	switch dv.Type() {
+	case timeType:
+		return decodeTime(src, dv)
+	case ipType:
+		s, ok := src.(string)
+		if ip := net.ParseIP(s); ok && ip != nil {
+			dv.Set(reflect.ValueOf(ip))
+			return nil
+		}
+		return fmt.Errorf("can not decode %v as ip", src)
+	}
+
+	switch dv.Kind() {
+	case reflect.Bool:
+		switch v := src.(type) {
+		case bool:
+			dv.SetBool(v)
+			return nil
+		case string:
+			var b bool
+			if b, err = strconv.ParseBool(v); err == nil {
+				dv.SetBool(b)
+			}
+			return
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = toInt(src); err != nil {
+			return
+		}
+		if dv.OverflowInt(n) {
+			return fmt.Errorf("%v overflows %v", n, dv.Type())
+		}
+		dv.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n int64
+		if n, err = toInt(src); err != nil {
+			return
+		}
+		if n < 0 || dv.OverflowUint(uint64(n)) {
+			return fmt.Errorf("%v overflows %v", n, dv.Type())
+		}
+		dv.SetUint(uint64(n))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = toFloat(src); err != nil {
+			return
+		}
+		dv.SetFloat(f)
+		return nil
+	case reflect.String:
+		switch v := src.(type) {
+		case string:
+			dv.SetString(v)
+		case json.Number:
+			dv.SetString(v.String())
+		case bool, float64:
+			dv.SetString(fmt.Sprint(v))
+		default:
+			// map、array 等类型写入 string 字段时保存为 JSON
+			var b []byte
+			if b, err = json.Marshal(v); err == nil {
+				dv.SetString(string(b))
+			}
+		}
+		return
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		if s, ok := src.(string); ok {
+			if dv.Kind() == reflect.Slice && dv.Type().Elem().Kind() == reflect.Uint8 {
+				dv.SetBytes([]byte(s))
+				return nil
+			}
+			// jsonstring 类型的值
+			if src, err = parseJSON(s); err != nil {
+				return
+			}
+		}
+		switch dv.Kind() {
+		case reflect.Struct:
+			if m, ok := src.(map[string]interface{}); ok {
+				return decodeStruct(m, dv)
+			}
+		case reflect.Map:
+			if m, ok := src.(map[string]interface{}); ok {
+				return decodeMap(m, dv)
+			}
+		default:
+			if s, ok := src.([]interface{}); ok {
+				return decodeArray(s, dv)
+			}
+		}
+	}
+	return fmt.Errorf("can not decode %T into %v", src, dv.Type())
+}
+
+func decodeTime(src interface{}, dv reflect.Value) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("can not decode %T as date", src)
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return err
+	}
+	dv.Set(reflect.ValueOf(t))
+	return nil
+}
+
+func decodeStruct(m map[string]interface{}, dv reflect.Value) error {
+	for _, f := range Fields(dv.Type()) {
+		v, ok := m[f.Name]
+		if !ok || v == nil {
+			continue
+		}
+		fv, _ := FieldByIndex(dv, f.Index, true)
+		if err := decodeValue(v, fv); err != nil {
+			return fmt.Errorf("field %s: %v", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func decodeMap(m map[string]interface{}, dv reflect.Value) error {
+	t := dv.Type()
+	if t.Key().Kind() != reflect.String {
+		return fmt.Errorf("map key of %v must be string", t)
+	}
+	out := reflect.MakeMapWithSize(t, len(m))
+	for k, v := range m {
+		ev := reflect.New(t.Elem()).Elem()
+		if err := decodeValue(v, ev); err != nil {
+			return fmt.Errorf("key %s: %v", k, err)
+		}
+		out.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), ev)
+	}
+	dv.Set(out)
+	return nil
+}
+
+func decodeArray(s []interface{}, dv reflect.Value) error {
+	if dv.Kind() == reflect.Slice {
+		dv.Set(reflect.MakeSlice(dv.Type(), len(s), len(s)))
+	}
+	for i, v := range s {
+		if i >= dv.Len() {
+			break
+		}
+		if err := decodeValue(v, dv.Index(i)); err != nil {
+			return fmt.Errorf("index %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// toInt 转换 long 类型的值，json.Number 直接解析为 int64，避免大整数经过 float64 损失精度
+func toInt(src interface{}) (int64, error) {
+	switch v := src.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		return floatToInt(v.Float64())
+	case float64:
+		return floatToInt(v, nil)
+	case string:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n, nil
+		}
+		return floatToInt(strconv.ParseFloat(v, 64))
+	}
+	return 0, fmt.Errorf("can not decode %T as long", src)
+}
+
+func floatToInt(f float64, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	if f != float64(int64(f)) {
+		return 0, fmt.Errorf("%v is not an integer", f)
+	}
+	return int64(f), nil
+}
+
+func toFloat(src interface{}) (float64, error) {
+	switch v := src.(type) {
+	case json.Number:
+		return v.Float64()
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+	return 0, fmt.Errorf("can not decode %T as float", src)
+}
+
+func parseJSON(s string) (v interface{}, err error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	err = dec.Decode(&v)
+	return
+}
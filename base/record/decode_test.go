@@ -0,0 +1,115 @@
+package record
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type decodeTestMeta struct {
+	Host  string `pandora:"host"`
+	Ports []int  `pandora:"ports"`
+}
+
+type decodeTestBase struct {
+	Source string `pandora:"source"`
+}
+
+type decodeTestEvent struct {
+	decodeTestBase
+	ID      int64             `pandora:"id,long,required"`
+	Count   *uint32           `pandora:"count"`
+	Score   float64           `pandora:"score"`
+	OK      bool              `pandora:"ok"`
+	Time    time.Time         `pandora:"ts"`
+	IP      net.IP            `pandora:"ip"`
+	Meta    decodeTestMeta    `pandora:"meta"`
+	Nested  *decodeTestMeta   `pandora:"nested,jsonstring"`
+	Extra   map[string]string `pandora:"extra"`
+	Raw     string            `pandora:"raw"`
+	Tags    []string          `pandora:"tags"`
+	Ignored string            `pandora:"-"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	data := []byte(`[{
+		"source": "s",
+		"id": 9007199254740993,
+		"count": 3,
+		"score": 1.5,
+		"ok": true,
+		"ts": "2026-01-02T03:04:05.123+08:00",
+		"ip": "10.0.0.1",
+		"meta": {"host": "h", "ports": [80, 443]},
+		"nested": "{\"host\":\"n\",\"ports\":[1]}",
+		"extra": "{\"k\":\"v\"}",
+		"raw": {"a": 1},
+		"tags": ["x"],
+		"Ignored": "x",
+		"unknown": 1
+	}, {"id": "2", "score": "0.5"}]`)
+	var events []*decodeTestEvent
+	assert.NoError(t, Unmarshal(data, &events))
+	assert.Len(t, events, 2)
+
+	count := uint32(3)
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 123000000, time.FixedZone("", 8*3600))
+	e := events[0]
+	assert.Equal(t, "s", e.Source)
+	assert.Equal(t, int64(9007199254740993), e.ID)
+	assert.Equal(t, &count, e.Count)
+	assert.Equal(t, 1.5, e.Score)
+	assert.True(t, e.OK)
+	assert.True(t, ts.Equal(e.Time))
+	assert.Equal(t, "10.0.0.1", e.IP.String())
+	assert.Equal(t, decodeTestMeta{Host: "h", Ports: []int{80, 443}}, e.Meta)
+	assert.Equal(t, &decodeTestMeta{Host: "n", Ports: []int{1}}, e.Nested)
+	assert.Equal(t, map[string]string{"k": "v"}, e.Extra)
+	assert.Equal(t, `{"a":1}`, e.Raw)
+	assert.Equal(t, []string{"x"}, e.Tags)
+	assert.Empty(t, e.Ignored)
+	assert.Equal(t, &decodeTestEvent{ID: 2, Score: 0.5}, events[1])
+
+	var bad []decodeTestEvent
+	assert.Error(t, Unmarshal([]byte(`[{"id": 1.5}]`), &bad))
+	assert.Error(t, Unmarshal([]byte(`[{"ts": 1}]`), &bad))
+	assert.Error(t, Unmarshal([]byte(`[{"count": -1}]`), &bad))
+	assert.Error(t, DecodeSlice(nil, bad))
+}
+
+func TestDecode(t *testing.T) {
+	var e decodeTestEvent
+	assert.NoError(t, Decode(map[string]interface{}{"id": float64(1), "nested": map[string]interface{}{"host": "h"}}, &e))
+	assert.Equal(t, int64(1), e.ID)
+	assert.Equal(t, "h", e.Nested.Host)
+	assert.Error(t, Decode(map[string]interface{}{}, e))
+}
+
+type FieldsTestMeta decodeTestMeta
+
+type FieldsTestNode struct {
+	*FieldsTestNode
+	*FieldsTestMeta
+	Name string `pandora:"name,string,required"`
+}
+
+func TestFields(t *testing.T) {
+	fields := Fields(reflect.TypeOf(FieldsTestNode{}))
+	// 循环嵌入的结构体指针不会无限展开
+	assert.Len(t, fields, 3)
+	assert.Equal(t, "host", fields[0].Name)
+	assert.Equal(t, []int{1, 0}, fields[0].Index)
+	assert.Equal(t, "name", fields[2].Name)
+	assert.Equal(t, []string{"string", "required"}, fields[2].Options)
+
+	v := reflect.ValueOf(&FieldsTestNode{}).Elem()
+	_, ok := FieldByIndex(v, fields[0].Index, false)
+	assert.False(t, ok)
+	fv, ok := FieldByIndex(v, fields[0].Index, true)
+	assert.True(t, ok)
+	fv.SetString("h")
+	assert.Equal(t, "h", v.Interface().(FieldsTestNode).Host)
+}
@@ -0,0 +1,95 @@
+package record
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Field 是结构体中对应 repo 字段的 Go 字段
+type Field struct {
+	// Name 是标签中的字段名称，缺省时为结构体字段名
+	Name string
+	// Options 是标签中字段名称之后以逗号分隔的部分，如类型、required、omitempty
+	Options []string
+	// Index 是字段在结构体中的位置，匿名嵌入结构体的字段包含嵌入结构体的位置，用于 FieldByIndex
+	Index []int
+	// Type 是字段的 Go 类型
+	Type reflect.Type
+	// GoName 是结构体字段名
+	GoName string
+}
+
+// fieldsCache 缓存每个结构体类型的字段
+var fieldsCache sync.Map
+
+// Fields 返回结构体 t 中对应 repo 字段的 Go 字段，规则与 encoding/json 相同：
+// 忽略未导出与标签为 - 的字段，展开匿名嵌入且没有标签的结构体或结构体指针，忽略未导出类型的嵌入结构体指针。
+// 返回的 slice 会被缓存，调用方不能修改
+func Fields(t reflect.Type) []Field {
+	if fields, ok := fieldsCache.Load(t); ok {
+		return fields.([]Field)
+	}
+	fields, _ := fieldsCache.LoadOrStore(t, structFields(t, nil))
+	return fields.([]Field)
+}
+
+// structFields 展开 t 的字段，embedding 是正在展开的嵌入结构体类型，避免嵌入结构体指针循环引用时无限递归
+func structFields(t reflect.Type, embedding map[reflect.Type]bool) []Field {
+	if embedding == nil {
+		embedding = map[reflect.Type]bool{t: true}
+	}
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get(TagName)
+		if tag == "-" {
+			continue
+		}
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && tag == "" && ft.Kind() == reflect.Struct && ft != timeType {
+			// 无法为未导出类型的嵌入结构体指针分配内存，忽略
+			if (sf.PkgPath != "" && sf.Type.Kind() == reflect.Ptr) || embedding[ft] {
+				continue
+			}
+			embedding[ft] = true
+			for _, f := range structFields(ft, embedding) {
+				f.Index = append([]int{i}, f.Index...)
+				fields = append(fields, f)
+			}
+			delete(embedding, ft)
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		name := strings.TrimSpace(parts[0])
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, Field{Name: name, Options: parts[1:], Index: sf.Index, Type: sf.Type, GoName: sf.Name})
+	}
+	return fields
+}
+
+// FieldByIndex 与 reflect.Value.FieldByIndex 相同，但不会因为值为 nil 的嵌入结构体指针 panic：
+// alloc 为 true 时为其分配内存(v 必须可以修改)，否则返回 false
+func FieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
@@ -0,0 +1,169 @@
+package reqerr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// 以下哨兵错误用于 errors.Is 判断 RequestError 的类别，例如 errors.Is(err, reqerr.ErrNotFound)
+var (
+	ErrNotFound        = errors.New("pandora: resource not found")
+	ErrAlreadyExists   = errors.New("pandora: resource already exists")
+	ErrAuth            = errors.New("pandora: unauthorized or access denied")
+	ErrAccountDisabled = errors.New("pandora: account is frozen or in arrears protection")
+	ErrThrottled       = errors.New("pandora: request throttled")
+	ErrInvalidArgument = errors.New("pandora: invalid argument")
+	ErrEntityTooLarge  = errors.New("pandora: entity too large")
+	ErrServer          = errors.New("pandora: server error")
+	ErrTransport       = errors.New("pandora: transport error")
+)
+
+var notFoundTypes = map[int]bool{
+	NoSuchRepoError:        true,
+	NoSuchGroupError:       true,
+	NoSuchTransformError:   true,
+	NoSuchExportError:      true,
+	NoSuchPluginError:      true,
+	NoSuchRetentionError:   true,
+	NoSuchSeriesError:      true,
+	NoSuchViewError:        true,
+	ErrDBNotFoundError:     true,
+	ErrTableNotFoundError:  true,
+	ErrDataSourceNotExist:  true,
+	ErrJobNotExist:         true,
+	ErrJobExportNotExist:   true,
+	ErrJobSrcNotExist:      true,
+	ErrJobRunIdNotExist:    true,
+	ErrBucketNotExist:      true,
+	ErrNoSuchWorkflow:      true,
+	ErrNoSuchResourceOwner: true,
+	ErrVariableNotExist:    true,
+	ErrUdfJarNotExist:      true,
+	ErrNoSuchBucket:        true,
+	ErrNoSuchDatabase:      true,
+	ErrNoSuchTables:        true,
+	ErrNoSuchEntry:         true,
+	ErrNoSuchAgent:         true,
+	ErrNotFoundRecord:      true,
+	ErrRepoNotExistError:   true,
+	ErrNoSuchSeries:        true,
+}
+
+var alreadyExistsTypes = map[int]bool{
+	RepoAlreadyExistsError:      true,
+	GroupAlreadyExistsError:     true,
+	TransformAlreadyExistsError: true,
+	ExportAlreadyExistsError:    true,
+	PluginAlreadyExistsError:    true,
+	SeriesAlreadyExistsError:    true,
+	ViewAlreadyExistsError:      true,
+	ErrDataSourceExist:          true,
+	ErrJobExist:                 true,
+	ErrJobExportExist:           true,
+	ErrWorkflowAlreadyExists:    true,
+	ErrVariableAlreadyExist:     true,
+	ErrExistRecord:              true,
+}
+
+var invalidArgumentTypes = map[int]bool{
+	InvalidArgs:               true,
+	InvalidSliceArgumentError: true,
+	UnmatchedSchemaError:      true,
+	InvalidDataSchemaError:    true,
+	ErrInvalidParameterError:  true,
+	ErrRequestBodyInvalid:     true,
+	ErrParamsCheck:            true,
+}
+
+// Is 让 errors.Is 可以用本包的哨兵错误判断 RequestError 的类别
+func (r *RequestError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return notFoundTypes[r.ErrorType]
+	case ErrAlreadyExists:
+		return alreadyExistsTypes[r.ErrorType]
+	case ErrAuth:
+		return r.ErrorType == UnauthorizedError || r.ErrorType == ErrAccessDenied ||
+			r.StatusCode == http.StatusUnauthorized || r.StatusCode == http.StatusForbidden
+	case ErrAccountDisabled:
+		return r.ErrorType == ErrAccountFrozen || r.ErrorType == ErrAccountArrearsProtection
+	case ErrThrottled:
+		return r.StatusCode == http.StatusTooManyRequests
+	case ErrInvalidArgument:
+		return invalidArgumentTypes[r.ErrorType]
+	case ErrEntityTooLarge:
+		return r.ErrorType == EntityTooLargeError || r.StatusCode == http.StatusRequestEntityTooLarge
+	case ErrServer:
+		return r.ErrorType == InternalServerError || r.ErrorType == ErrInternalServerError || r.StatusCode >= 500
+	case ErrTransport:
+		return r.ErrorType == TransportError
+	}
+	return false
+}
+
+// Unwrap 返回 RequestError 包装的底层错误，如传输层的 *url.Error
+func (r *RequestError) Unwrap() error {
+	return r.Err
+}
+
+// NewTransportError 包装请求未得到服务端响应时的错误，errors.Is(err, context.Canceled) 等判断依然有效。
+// 之前直接对返回的错误做 err.(*url.Error) 或 err.(net.Error) 类型断言的调用方需要改用 errors.As
+func NewTransportError(err error) *RequestError {
+	return &RequestError{
+		Message:   err.Error(),
+		ErrorType: TransportError,
+		Component: "pandora",
+		Err:       err,
+	}
+}
+
+// WithCause 记录导致发送失败的错误，之后可以通过 errors.Is/As 判断
+func (e *SendError) WithCause(err error) *SendError {
+	e.cause = err
+	return e
+}
+
+func (e *SendError) Unwrap() error {
+	return e.cause
+}
+
+// IsThrottled 判断请求是否因服务端限流失败
+func IsThrottled(err error) bool {
+	return errors.Is(err, ErrThrottled)
+}
+
+// IsAuthError 判断请求是否因鉴权失败、无权限或账号被冻结失败
+func IsAuthError(err error) bool {
+	return errors.Is(err, ErrAuth) || errors.Is(err, ErrAccountDisabled)
+}
+
+// IsRetryable 判断相同的请求重试后是否可能成功：传输层错误(主动取消除外)、限流、服务端错误、错误码登记表中标记为可重试的错误，
+// 以及 schemafree 本地缓存过期导致的 SendError
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var se *SendError
+	if errors.As(err, &se) && se.ErrorType == TypeSchemaFreeRetry {
+		return true
+	}
+	if errors.Is(err, ErrTransport) || errors.Is(err, ErrThrottled) {
+		return true
+	}
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		switch reqErr.StatusCode {
+		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		if retryableCode(reqErr) {
+			return true
+		}
+		return reqErr.ErrorType == InternalServerError || reqErr.ErrorType == ErrInternalServerError
+	}
+	return false
+}
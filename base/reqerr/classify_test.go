@@ -0,0 +1,52 @@
+package reqerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	notFound := New("E18102: repo not found", "", "reqid", 404)
+	notFound.ErrorType = NoSuchRepoError
+	assert.True(t, errors.Is(notFound, ErrNotFound))
+	assert.False(t, errors.Is(notFound, ErrAlreadyExists))
+	assert.True(t, IsNoSuchResourceError(fmt.Errorf("wrapped: %w", notFound)))
+	assert.False(t, IsRetryable(notFound))
+
+	unauthorized := New("unauthorized", "", "reqid", 401)
+	unauthorized.ErrorType = UnauthorizedError
+	assert.True(t, IsAuthError(unauthorized))
+	frozen := New("E18670", "", "reqid", 403)
+	frozen.ErrorType = ErrAccountFrozen
+	assert.True(t, IsAuthError(frozen))
+	assert.True(t, errors.Is(frozen, ErrAccountDisabled))
+
+	throttled := New("too many requests", "", "reqid", 429)
+	assert.True(t, IsThrottled(throttled))
+	assert.True(t, IsRetryable(throttled))
+	assert.True(t, IsRetryable(New("bad gateway", "", "reqid", 502)))
+	assert.True(t, errors.Is(New("internal", "", "reqid", 500), ErrServer))
+
+	transport := NewTransportError(&url.Error{Op: "Post", URL: "http://localhost", Err: errors.New("connection refused")})
+	assert.True(t, errors.Is(transport, ErrTransport))
+	assert.True(t, IsRetryable(transport))
+	var urlErr *url.Error
+	assert.True(t, errors.As(transport, &urlErr))
+
+	canceled := NewTransportError(&url.Error{Op: "Post", URL: "http://localhost", Err: context.Canceled})
+	assert.True(t, errors.Is(canceled, context.Canceled))
+	assert.False(t, IsRetryable(canceled))
+
+	se := NewSendError("Cannot send data to pandora", nil, TypeDefault).WithCause(throttled)
+	assert.True(t, IsThrottled(se))
+	var reqErr *RequestError
+	assert.True(t, errors.As(se, &reqErr))
+	assert.Equal(t, 429, reqErr.StatusCode)
+	assert.True(t, IsRetryable(NewSendError("retry", nil, TypeSchemaFreeRetry)))
+	assert.False(t, IsRetryable(NewSendError("invalid", nil, TypeBinaryUnpack)))
+}
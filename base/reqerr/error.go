@@ -1,6 +1,9 @@
 package reqerr
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 const (
 	DefaultRequestError = iota
@@ -247,6 +250,9 @@ const (
 	// account
 	ErrAccountArrearsProtection
 	ErrAccountFrozen
+
+	// TransportError 表示请求没有得到服务端的响应，如连接失败、超时
+	TransportError
 )
 
 type ErrBuilder interface {
@@ -274,6 +280,8 @@ type RequestError struct {
 	RawMessage string `json:"-"`
 	ErrorType  int    `json:"-"`
 	Component  string `json:"-"`
+	Code       string `json:"-"` // 服务端返回的错误码，如 E18102
+	Err        error  `json:"-"`
 }
 
 func New(message, rawText, reqId string, statusCode int) *RequestError {
@@ -292,7 +300,8 @@ func (r RequestError) Error() string {
 }
 
 func IsExistError(err error) bool {
-	reqErr, ok := err.(*RequestError)
+	var reqErr *RequestError
+	ok := errors.As(err, &reqErr)
 	if !ok {
 		return false
 	}
@@ -309,7 +318,8 @@ func IsExistError(err error) bool {
 }
 
 func IsNoSuchWorkflow(err error) bool {
-	reqErr, ok := err.(*RequestError)
+	var reqErr *RequestError
+	ok := errors.As(err, &reqErr)
 	if !ok {
 		return false
 	}
@@ -320,7 +330,8 @@ func IsNoSuchWorkflow(err error) bool {
 }
 
 func IsWorkflowStatError(err error) bool {
-	reqErr, ok := err.(*RequestError)
+	var reqErr *RequestError
+	ok := errors.As(err, &reqErr)
 	if !ok {
 		return false
 	}
@@ -331,7 +342,8 @@ func IsWorkflowStatError(err error) bool {
 }
 
 func IsWorkflowNoExecutableJob(err error) bool {
-	reqErr, ok := err.(*RequestError)
+	var reqErr *RequestError
+	ok := errors.As(err, &reqErr)
 	if !ok {
 		return false
 	}
@@ -342,7 +354,8 @@ func IsWorkflowNoExecutableJob(err error) bool {
 }
 
 func IsNoSuchResourceError(err error) bool {
-	reqErr, ok := err.(*RequestError)
+	var reqErr *RequestError
+	ok := errors.As(err, &reqErr)
 	if !ok {
 		return false
 	}
@@ -377,7 +390,8 @@ func IsNoSuchResourceError(err error) bool {
 }
 
 func IsExportRemainUnchanged(err error) bool {
-	reqErr, ok := err.(*RequestError)
+	var reqErr *RequestError
+	ok := errors.As(err, &reqErr)
 	if !ok {
 		return false
 	}
@@ -408,6 +422,7 @@ type SendError struct {
 	failLines []string
 	isline    bool
 	msg       string
+	cause     error
 	ErrorType SendErrorType
 }
 
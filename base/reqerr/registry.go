@@ -0,0 +1,404 @@
+package reqerr
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	ComponentPipeline = "pipeline"
+	ComponentLogdb    = "logdb"
+	ComponentTsdb     = "tsdb"
+	ComponentReport   = "report"
+	ComponentLogkit   = "logkit"
+)
+
+// ErrorCode 描述服务端返回的一个错误码，Component 为空表示所有服务通用
+type ErrorCode struct {
+	Code        string
+	Component   string
+	ErrorType   int
+	Retryable   bool
+	Description string
+}
+
+// codes 是所有服务端错误码的登记表，各服务的 ErrBuilder 都由它驱动，新增错误码时只需要修改这里
+var codes = []ErrorCode{
+	// 所有服务通用
+	{"E18669", "", ErrAccountArrearsProtection, false, "account is in arrears protection"},
+	{"E18670", "", ErrAccountFrozen, false, "account is frozen"},
+
+	// pipeline
+	{"E18005", ComponentPipeline, EntityTooLargeError, false, "request entity too large"},
+	{"E18016", ComponentPipeline, ErrInvalidVariableType, false, "invalid variable type"},
+	{"E18017", ComponentPipeline, ErrInvalidVariableName, false, "invalid variable name"},
+	{"E18018", ComponentPipeline, ErrInvalidVariableValue, false, "invalid variable value"},
+	{"E18019", ComponentPipeline, ErrPathFilter, false, "invalid path filter"},
+	{"E18101", ComponentPipeline, RepoAlreadyExistsError, false, "repo already exists"},
+	{"E18102", ComponentPipeline, NoSuchRepoError, false, "no such repo"},
+	{"E18104", ComponentPipeline, ErrDuplicateField, false, "duplicate field in repo schema"},
+	{"E18107", ComponentPipeline, ErrUnsupportedFieldType, false, "unsupported field type"},
+	{"E18110", ComponentPipeline, InvalidDataSchemaError, false, "data does not match repo schema"},
+	{"E18111", ComponentPipeline, ErrSchemaFieldNotExist, true, "field key does not exist in repo schema, may be caused by a stale server cache"},
+	{"E18112", ComponentPipeline, RepoCascadingError, false, "repo has cascading transforms or exports"},
+	{"E18120", ComponentPipeline, NoSuchGroupError, false, "no such group"},
+	{"E18123", ComponentPipeline, InvalidDataSchemaError, false, "data does not match repo schema"},
+	{"E18124", ComponentPipeline, RepoInCreatingError, true, "repo is still being created"},
+	{"E18125", ComponentPipeline, InvalidDataSchemaError, false, "data does not match repo schema"},
+	{"E18128", ComponentPipeline, ErrIncompatibleRepoSchema, false, "incompatible repo schema"},
+	{"E18134", ComponentPipeline, ErrTooManySchema, false, "too many fields in repo schema"},
+	{"E18135", ComponentPipeline, ErrSchemaLimitUnderflow, false, "repo schema limit underflow"},
+	{"E18136", ComponentPipeline, ErrInvalidRepoDescription, false, "invalid repo description"},
+	{"E18137", ComponentPipeline, ErrInvalidRepoSchemaDescription, false, "invalid repo schema description"},
+	{"E18138", ComponentPipeline, ErrTagsDecodeError, false, "failed to decode tags"},
+	{"E18201", ComponentPipeline, TransformAlreadyExistsError, false, "transform already exists"},
+	{"E18202", ComponentPipeline, NoSuchTransformError, false, "no such transform"},
+	{"E18207", ComponentPipeline, InvalidTransformSpecError, false, "invalid transform spec"},
+	{"E18208", ComponentPipeline, ErrInvalidTransformInterval, false, "invalid transform interval"},
+	{"E18209", ComponentPipeline, ErrInvalidTransformSql, false, "invalid transform sql"},
+	{"E18210", ComponentPipeline, InvalidTransformSpecError, false, "invalid transform spec"},
+	{"E18211", ComponentPipeline, ErrInvalidTransformPluginOutput, false, "invalid transform plugin output"},
+	{"E18216", ComponentPipeline, NoSuchPluginError, false, "no such plugin"},
+	{"E18217", ComponentPipeline, PluginAlreadyExistsError, false, "plugin already exists"},
+	{"E18218", ComponentPipeline, GroupAlreadyExistsError, false, "group already exists"},
+	{"E18228", ComponentPipeline, ErrInvalidFieldInSQL, false, "invalid field in sql"},
+	{"E18229", ComponentPipeline, ErrInvalidDstRepoSchema, false, "invalid destination repo schema"},
+	{"E18230", ComponentPipeline, ErrInvalidDstRepoSchemaLength, false, "invalid destination repo schema length"},
+	{"E18301", ComponentPipeline, ExportAlreadyExistsError, false, "export already exists"},
+	{"E18302", ComponentPipeline, NoSuchExportError, false, "no such export"},
+	{"E18305", ComponentPipeline, InvalidExportSpecError, false, "invalid export spec"},
+	{"E18306", ComponentPipeline, ErrStartExport, true, "failed to start export"},
+	{"E18307", ComponentPipeline, ErrStopExport, true, "failed to stop export"},
+	{"E18308", ComponentPipeline, ErrInvalidSchemaKey, false, "invalid schema key"},
+	{"E18309", ComponentPipeline, ErrInvalidTimestamp, false, "invalid timestamp"},
+	{"E18310", ComponentPipeline, ErrInvalidUrl, false, "invalid url"},
+	{"E18311", ComponentPipeline, ErrInvalidDestinationField, false, "invalid destination field"},
+	{"E18312", ComponentPipeline, ErrRepoFieldNotExist, false, "repo field does not exist"},
+	{"E18313", ComponentPipeline, ErrEmptySourceField, false, "source field is empty"},
+	{"E18314", ComponentPipeline, ErrFieldNotExist, false, "field does not exist"},
+	{"E18315", ComponentPipeline, ErrIncompatibleTypes, false, "incompatible field types"},
+	{"E18316", ComponentPipeline, ErrInvalidFieldName, false, "invalid field name"},
+	{"E18317", ComponentPipeline, ErrFieldMissed, false, "required field missed"},
+	{"E18318", ComponentPipeline, ErrInvalidFieldType, false, "invalid field type"},
+	{"E18319", ComponentPipeline, ErrEmptyField, false, "field is empty"},
+	{"E18320", ComponentPipeline, ErrInvalidPrefix, false, "invalid prefix"},
+	{"E18321", ComponentPipeline, ErrInvalidFieldValue, false, "invalid field value"},
+	{"E18322", ComponentPipeline, ErrInvalidExportType, false, "invalid export type"},
+	{"E18323", ComponentPipeline, ErrNoSuchBucket, false, "no such bucket"},
+	{"E18324", ComponentPipeline, ErrSourceFieldInvalidPrefix, false, "source field has invalid prefix"},
+	{"E18325", ComponentPipeline, ErrFieldTypeError, false, "field type error"},
+	{"E18326", ComponentPipeline, ErrRepoNotExistError, false, "repo does not exist"},
+	{"E18327", ComponentPipeline, ErrNoSuchSeries, false, "no such series"},
+	{"E18328", ComponentPipeline, ErrInvalidTagName, false, "invalid tag name"},
+	{"E18329", ComponentPipeline, ErrDuplicatedKey, false, "duplicated key"},
+	{"E18330", ComponentPipeline, ErrMissingDelimiterForCsv, false, "missing delimiter for csv export"},
+	{"E18331", ComponentPipeline, ErrRotateSizeExceed, false, "rotate size exceeds limit"},
+	{"E18332", ComponentPipeline, ErrNoSuchDatabase, false, "no such database"},
+	{"E18333", ComponentPipeline, ErrNoSuchTables, false, "no such table"},
+	{"E18334", ComponentPipeline, ErrDestinationEmptyError, false, "export destination is empty"},
+	{"E18335", ComponentPipeline, ErrInvalidSourceField, false, "invalid source field"},
+	{"E18336", ComponentPipeline, ErrInvalidEmptySourceField, false, "source field should not be empty"},
+	{"E18337", ComponentPipeline, ErrConnectHdfsFailed, true, "failed to connect to hdfs"},
+	{"E18338", ComponentPipeline, ErrStatFileFailed, true, "failed to stat file"},
+	{"E18339", ComponentPipeline, ErrExportTypeDisabled, false, "export type is disabled"},
+	{"E18600", ComponentPipeline, ErrInvalidDataSourceName, false, "invalid datasource name"},
+	{"E18601", ComponentPipeline, ErrDataSourceExist, false, "datasource already exists"},
+	{"E18602", ComponentPipeline, ErrDataSourceNotExist, false, "no such datasource"},
+	{"E18603", ComponentPipeline, ErrDataSourceCascading, false, "datasource has cascading jobs"},
+	{"E18604", ComponentPipeline, ErrInvalidJobName, false, "invalid job name"},
+	{"E18605", ComponentPipeline, ErrJobExist, false, "job already exists"},
+	{"E18606", ComponentPipeline, ErrJobNotExist, false, "no such job"},
+	{"E18607", ComponentPipeline, ErrJobArgumentCount, false, "wrong number of job arguments"},
+	{"E18608", ComponentPipeline, ErrJobCascading, false, "job has cascading jobs or exports"},
+	{"E18609", ComponentPipeline, ErrInvalidJobExportName, false, "invalid job export name"},
+	{"E18610", ComponentPipeline, ErrJobExportExist, false, "job export already exists"},
+	{"E18611", ComponentPipeline, ErrJobExportNotExist, false, "no such job export"},
+	{"E18612", ComponentPipeline, ErrJobSrcNotExist, false, "job source does not exist"},
+	{"E18613", ComponentPipeline, ErrDuplicateTableName, false, "duplicate table name"},
+	{"E18614", ComponentPipeline, ErrInvalidBatchSpec, false, "invalid batch spec"},
+	{"E18615", ComponentPipeline, ErrIncompatibleSourceSchema, false, "incompatible source schema"},
+	{"E18617", ComponentPipeline, ErrInvalidTransformPlugin, false, "invalid transform plugin"},
+	{"E18618", ComponentPipeline, ErrInvalidJobSQL, false, "invalid job sql"},
+	{"E18619", ComponentPipeline, ErrBucketNotExist, false, "bucket does not exist"},
+	{"E18620", ComponentPipeline, ErrDatasourceNoFiles, false, "datasource has no files"},
+	{"E18621", ComponentPipeline, ErrStartJob, true, "failed to start job"},
+	{"E18622", ComponentPipeline, ErrStopJob, true, "failed to stop job"},
+	{"E18623", ComponentPipeline, ErrFileFormatMismatch, false, "file format mismatch"},
+	{"E18624", ComponentPipeline, ErrJobRunIdNotExist, false, "job run id does not exist"},
+	{"E18625", ComponentPipeline, ErrBatchCannotRerun, false, "batch job cannot be rerun"},
+	{"E18626", ComponentPipeline, ErrBatchStatusCannotStop, false, "batch job cannot be stopped in current status"},
+	{"E18627", ComponentPipeline, ErrUdfJarNotExist, false, "udf jar does not exist"},
+	{"E18628", ComponentPipeline, ErrInvalidUdfJarName, false, "invalid udf jar name"},
+	{"E18629", ComponentPipeline, ErrInvalidUdfFuncName, false, "invalid udf function name"},
+	{"E18630", ComponentPipeline, ErrInvalidJavaClassName, false, "invalid java class name"},
+	{"E18631", ComponentPipeline, ErrUdfClassTypeError, false, "udf class type error"},
+	{"E18632", ComponentPipeline, ErrUdfClassNotFound, false, "udf class not found"},
+	{"E18633", ComponentPipeline, ErrUdfFunctionNotImplement, false, "udf function not implemented"},
+	{"E18634", ComponentPipeline, ErrUdfFunctionNotFound, false, "udf function not found"},
+	{"E18635", ComponentPipeline, ErrUdfFuncExisted, false, "udf function already exists"},
+	{"E18636", ComponentPipeline, ErrUdfJarExisted, false, "udf jar already exists"},
+	{"E18637", ComponentPipeline, ErrDuplicationWithSystemFunc, false, "name duplicates a system function"},
+	{"E18638", ComponentPipeline, ErrIllegalCharacterInPath, false, "illegal character in path"},
+	{"E18639", ComponentPipeline, ErrInvalidWorkflowName, false, "invalid workflow name"},
+	{"E18640", ComponentPipeline, ErrWorkflowAlreadyExists, false, "workflow already exists"},
+	{"E18641", ComponentPipeline, ErrNoSuchWorkflow, false, "no such workflow"},
+	{"E18642", ComponentPipeline, ErrWorkflowSpecContent, false, "invalid workflow spec content"},
+	{"E18643", ComponentPipeline, ErrUpdateWorkflow, false, "workflow cannot be updated in current status"},
+	{"E18644", ComponentPipeline, ErrStartWorkflow, true, "failed to start workflow"},
+	{"E18645", ComponentPipeline, ErrStopWorkflow, true, "failed to stop workflow"},
+	{"E18646", ComponentPipeline, ErrWorkflowStructure, false, "invalid workflow structure"},
+	{"E18647", ComponentPipeline, ErrStartTransform, true, "failed to start transform"},
+	{"E18648", ComponentPipeline, ErrStopTransform, true, "failed to stop transform"},
+	{"E18649", ComponentPipeline, ErrBatchStatusCannotRerun, false, "batch job cannot be rerun in current status"},
+	{"E18650", ComponentPipeline, ErrNoExecutableJob, false, "workflow has no executable job"},
+	{"E18651", ComponentPipeline, ErrJobExportSpec, false, "invalid job export spec"},
+	{"E18652", ComponentPipeline, ErrWorkflowCreatingTooManyRepos, false, "workflow is creating too many repos"},
+	{"E18653", ComponentPipeline, ErrWorkflowJobsCoexist, false, "jobs cannot coexist in one workflow"},
+	{"E18654", ComponentPipeline, ErrVariableNotExist, false, "no such variable"},
+	{"E18655", ComponentPipeline, ErrVariableAlreadyExist, false, "variable already exists"},
+	{"E18656", ComponentPipeline, ErrSameToSystemVariable, false, "variable name duplicates a system variable"},
+	{"E18657", ComponentPipeline, ErrSQLWithUndefinedVariable, false, "sql uses undefined variable"},
+	{"E18658", ComponentPipeline, ErrTimeFormatInvalid, false, "invalid time format"},
+	{"E18660", ComponentPipeline, ErrTransformUpdate, false, "transform cannot be updated"},
+	{"E18661", ComponentPipeline, ErrWorkflowNameSameToRepoOrDatasource, false, "workflow name duplicates a repo or datasource"},
+	{"E18662", ComponentPipeline, ErrJobReRunOrCancel, false, "job cannot be rerun or canceled"},
+	{"E18663", ComponentPipeline, ErrStartOrStopBatchJob, true, "failed to start or stop batch job"},
+	{"E18664", ComponentPipeline, ErrNoSuchResourceOwner, false, "no such resource owner"},
+	{"E18665", ComponentPipeline, ErrAccessDenied, false, "access denied"},
+	{"E18703", ComponentPipeline, ErrTransformRepeatRestart, false, "transform is already restarting"},
+	{"E18704", ComponentPipeline, ErrFusionPathUsedStringVariable, false, "fusion path uses a string variable"},
+	{"E18705", ComponentPipeline, ErrFusionPathWithUndefinedVariable, false, "fusion path uses undefined variable"},
+	{"E8111", ComponentPipeline, NoSuchRepoError, false, "no such repo"},
+	{"E9000", ComponentPipeline, InternalServerError, true, "internal server error"},
+	{"E9001", ComponentPipeline, NotImplementedError, false, "function not implemented on server"},
+
+	// logdb
+	{"E8004", ComponentLogdb, InternalServerError, true, "internal server error"},
+	{"E8104", ComponentLogdb, UnmatchedSchemaError, false, "schema does not match repo"},
+	{"E8111", ComponentLogdb, NoSuchRepoError, false, "no such repo"},
+	{"E8112", ComponentLogdb, RepoAlreadyExistsError, false, "repo already exists"},
+	{"E8135", ComponentLogdb, ErrInvalidRepoDescription, false, "invalid repo description"},
+	{"E8136", ComponentLogdb, ErrInvalidRepoSchemaDescription, false, "invalid repo schema description"},
+	{"E8201", ComponentLogdb, InvalidSliceArgumentError, false, "invalid slice argument"},
+	{"E8202", ComponentLogdb, InvalidSliceArgumentError, false, "invalid slice argument"},
+	{"E8203", ComponentLogdb, InvalidSliceArgumentError, false, "invalid slice argument"},
+	{"E8204", ComponentLogdb, InvalidSliceArgumentError, false, "invalid slice argument"},
+	{"E8205", ComponentLogdb, InvalidSliceArgumentError, false, "invalid slice argument"},
+	{"E8206", ComponentLogdb, InvalidSliceArgumentError, false, "invalid slice argument"},
+	{"E8207", ComponentLogdb, InvalidSliceArgumentError, false, "invalid slice argument"},
+	{"E8208", ComponentLogdb, InvalidSliceArgumentError, false, "invalid slice argument"},
+	{"E8209", ComponentLogdb, InvalidSliceArgumentError, false, "invalid slice argument"},
+
+	// tsdb
+	{"E6102", ComponentTsdb, RepoAlreadyExistsError, false, "repo already exists"},
+	{"E6205", ComponentTsdb, NoSuchRetentionError, false, "no such retention"},
+	{"E6300", ComponentTsdb, InvalidSeriesNameError, false, "invalid series name"},
+	{"E6302", ComponentTsdb, SeriesAlreadyExistsError, false, "series already exists"},
+	{"E6303", ComponentTsdb, NoSuchSeriesError, false, "no such series"},
+	{"E6400", ComponentTsdb, InvalidViewNameError, false, "invalid view name"},
+	{"E6403", ComponentTsdb, InvalidViewSqlError, false, "invalid view sql"},
+	{"E6404", ComponentTsdb, ViewFuncNotSupportError, false, "function not supported in view"},
+	{"E6405", ComponentTsdb, ViewFuncNotSupportError, false, "function not supported in view"},
+	{"E6410", ComponentTsdb, NoSuchViewError, false, "no such view"},
+	{"E6411", ComponentTsdb, ViewAlreadyExistsError, false, "view already exists"},
+	{"E6412", ComponentTsdb, InvalidViewStatementError, false, "invalid view statement"},
+	{"E7100", ComponentTsdb, NoSuchRepoError, false, "no such repo"},
+	{"E7102", ComponentTsdb, PointsNotInSameRetentionError, false, "points are not in the same retention"},
+	{"E7103", ComponentTsdb, TimestampTooFarFromNowError, false, "timestamp is too far from now"},
+	{"E7200", ComponentTsdb, InvalidQuerySql, false, "invalid query sql"},
+	{"E7201", ComponentTsdb, InvalidQuerySql, false, "invalid query sql"},
+	{"E7204", ComponentTsdb, QueryInterruptError, true, "query interrupted"},
+	{"E7205", ComponentTsdb, InvalidQuerySql, false, "invalid query sql"},
+	{"E7206", ComponentTsdb, InvalidQuerySql, false, "invalid query sql"},
+	{"E7212", ComponentTsdb, ExecuteSqlError, false, "failed to execute sql"},
+	{"E9001", ComponentTsdb, InternalServerError, true, "internal server error"},
+	{"E9002", ComponentTsdb, QueryInterruptError, true, "query interrupted"},
+
+	// report
+	{"E8002", ComponentReport, ErrDBNameInvalidError, false, "invalid database name"},
+	{"E8003", ComponentReport, ErrInvalidSqlError, false, "invalid sql"},
+	{"E8005", ComponentReport, ErrInvalidParameterError, false, "invalid parameter"},
+	{"E8006", ComponentReport, ErrDBNotFoundError, false, "no such database"},
+	{"E8007", ComponentReport, ErrTableNotFoundError, false, "no such table"},
+	{"E9001", ComponentReport, InternalServerError, true, "internal server error"},
+
+	// logkit
+	{"E1001", ComponentLogkit, ErrRequestBodyInvalid, false, "invalid request body"},
+	{"E1002", ComponentLogkit, ErrInvalidArgs, false, "invalid arguments"},
+	{"E1005", ComponentLogkit, ErrJobInfoInvalid, false, "invalid job info"},
+	{"E1006", ComponentLogkit, ErrAgentRegister, true, "failed to register agent"},
+	{"E1007", ComponentLogkit, ErrAgentReportJobStates, true, "failed to report job states"},
+	{"E1009", ComponentLogkit, ErrUnknownAction, false, "unknown action"},
+	{"E1010", ComponentLogkit, ErrGetUserInformation, true, "failed to get user information"},
+	{"E1011", ComponentLogkit, ErrGetPagingInfo, false, "failed to get paging info"},
+	{"E1012", ComponentLogkit, ErrGetAgentInfo, true, "failed to get agent info"},
+	{"E1013", ComponentLogkit, ErrGetAgentIdList, true, "failed to get agent id list"},
+	{"E1015", ComponentLogkit, ErrGetRunnerInfo, true, "failed to get runner info"},
+	{"E1017", ComponentLogkit, ErrRemoveAgentInfo, true, "failed to remove agent info"},
+	{"E1018", ComponentLogkit, ErrRemoveRunner, true, "failed to remove runner"},
+	{"E1019", ComponentLogkit, ErrRemoveJobStates, true, "failed to remove job states"},
+	{"E1020", ComponentLogkit, ErrUpdateAgentInfo, true, "failed to update agent info"},
+	{"E1021", ComponentLogkit, ErrAgentReportMetrics, true, "failed to report agent metrics"},
+	{"E1023", ComponentLogkit, ErrGetVersionConfigItem, true, "failed to get version config item"},
+	{"E1024", ComponentLogkit, ErrNoValidAgentsFound, false, "no valid agents found"},
+	{"E1025", ComponentLogkit, ErrScheduleAgent, true, "failed to schedule agent"},
+	{"E1026", ComponentLogkit, ErrNoSuchAgent, false, "no such agent"},
+	{"E1027", ComponentLogkit, ErrGetMachineInfo, true, "failed to get machine info"},
+	{"E1029", ComponentLogkit, ErrDoLogDBMSearch, true, "failed to do logdb msearch"},
+	{"E1030", ComponentLogkit, ErrGetJobJnfos, true, "failed to get job infos"},
+	{"E1031", ComponentLogkit, ErrGetJobStates, true, "failed to get job states"},
+	{"E1032", ComponentLogkit, ErrGetUserToken, true, "failed to get user token"},
+	{"E1034", ComponentLogkit, ErrSystemNotSupport, false, "system not supported"},
+	{"E1035", ComponentLogkit, ErrGetSenderPandora, true, "failed to get pandora sender"},
+	{"E1037", ComponentLogkit, ErrStatusDecodeBase64, false, "failed to decode base64 status"},
+	{"E1038", ComponentLogkit, ErrGetGrokCheck, false, "grok check failed"},
+	{"E1039", ComponentLogkit, ErrParamsCheck, false, "invalid parameters"},
+	{"E1040", ComponentLogkit, ErrGetTagList, true, "failed to get tag list"},
+	{"E1041", ComponentLogkit, ErrGetConfigList, true, "failed to get config list"},
+	{"E1042", ComponentLogkit, ErrInsertConfigs, true, "failed to insert configs"},
+	{"E1043", ComponentLogkit, ErrAssignConfigs, true, "failed to assign configs"},
+	{"E1048", ComponentLogkit, ErrGetRunnerList, true, "failed to get runner list"},
+	{"E1049", ComponentLogkit, ErrUpdateAssignConfigs, true, "failed to update assigned configs"},
+	{"E1050", ComponentLogkit, ErrGetAgents, true, "failed to get agents"},
+	{"E1055", ComponentLogkit, ErrRawDataSize, false, "raw data size exceeds limit"},
+	{"E1056", ComponentLogkit, ErrHeadPattern, false, "invalid head pattern"},
+	{"E1057", ComponentLogkit, ErrConfig, false, "invalid config"},
+	{"E1058", ComponentLogkit, ErrUnmarshal, false, "failed to unmarshal"},
+	{"E1059", ComponentLogkit, ErrLogParser, false, "log parser error"},
+	{"E1060", ComponentLogkit, ErrTransformer, false, "transformer error"},
+	{"E1061", ComponentLogkit, ErrSender, false, "sender error"},
+	{"E1062", ComponentLogkit, ErrRouter, false, "router error"},
+	{"E1065", ComponentLogkit, ErrUpdateTags, true, "failed to update tags"},
+	{"E1066", ComponentLogkit, ErrAgentInfoNotFound, false, "agent info not found"},
+	{"E1067", ComponentLogkit, ErrGetAgentRelease, true, "failed to get agent release"},
+	{"E1068", ComponentLogkit, ErrJobRelease, true, "failed to release job"},
+	{"E1069", ComponentLogkit, ErrAgentsDisconnect, true, "agents disconnected"},
+	{"E1070", ComponentLogkit, ErrUpdateConfigs, true, "failed to update configs"},
+	{"E1071", ComponentLogkit, ErrRemoveConfigs, true, "failed to remove configs"},
+	{"E1072", ComponentLogkit, ErrUpdateRunners, true, "failed to update runners"},
+	{"E1074", ComponentLogkit, ErrAssignTags, true, "failed to assign tags"},
+	{"E1075", ComponentLogkit, ErrAddTags, true, "failed to add tags"},
+	{"E1076", ComponentLogkit, ErrRemoveTags, true, "failed to remove tags"},
+	{"E1078", ComponentLogkit, ErrNotFoundRecord, false, "record not found"},
+	{"E1079", ComponentLogkit, ErrExistRecord, false, "record already exists"},
+	{"E1080", ComponentLogkit, ErrNoRunnerInfo, false, "no runner info"},
+	{"E1081", ComponentLogkit, ErrMachineMetricNotOn, false, "machine metric is not enabled"},
+	{"E1082", ComponentLogkit, ErrDeleteRunner, true, "failed to delete runner"},
+	{"E1083", ComponentLogkit, ErrAddRunner, true, "failed to add runner"},
+	{"E1084", ComponentLogkit, ErrDisableDeleteMetrics, false, "deleting metrics is disabled"},
+	{"E1085", ComponentLogkit, ErrDisablePostMetrics, false, "posting metrics is disabled"},
+	{"E1086", ComponentLogkit, ErrUnprocessableEntity, false, "unprocessable entity"},
+	{"E1087", ComponentLogkit, ErrDisablePostMetricsLogdb, false, "posting metrics to logdb is disabled"},
+	{"E1088", ComponentLogkit, ErrDoLogDBJob, true, "failed to do logdb job"},
+	{"E1089", ComponentLogkit, ErrDoLogDBAnalysis, true, "failed to do logdb analysis"},
+	{"E1090", ComponentLogkit, ErrTimeStamp, false, "invalid timestamp"},
+}
+
+type codeKey struct {
+	component string
+	code      string
+}
+
+var codeIndex = make(map[codeKey]*ErrorCode, len(codes))
+
+func init() {
+	for i := range codes {
+		key := codeKey{codes[i].Component, codes[i].Code}
+		if _, ok := codeIndex[key]; ok {
+			panic(fmt.Sprintf("reqerr: duplicate error code %s of component %q", key.code, key.component))
+		}
+		codeIndex[key] = &codes[i]
+	}
+}
+
+// ParseCode 从服务端返回的错误信息中取出错误码，如 "E18102: no such repo" 返回 "E18102"，没有错误码时返回空字符串
+func ParseCode(message string) string {
+	if len(message) < 2 || message[0] != 'E' {
+		return ""
+	}
+	i := 1
+	for i < len(message) && message[i] >= '0' && message[i] <= '9' {
+		i++
+	}
+	if i == 1 {
+		return ""
+	}
+	return message[:i]
+}
+
+// Lookup 查找服务 component 的错误码 code，服务没有单独登记时使用通用的错误码
+func Lookup(component, code string) (ErrorCode, bool) {
+	if c, ok := codeIndex[codeKey{component, code}]; ok {
+		return *c, true
+	}
+	if c, ok := codeIndex[codeKey{"", code}]; ok {
+		return *c, true
+	}
+	return ErrorCode{}, false
+}
+
+// Describe 返回错误码 code 在各服务中的登记信息，供命令行等工具展示，code 也可以是完整的错误信息
+func Describe(code string) []ErrorCode {
+	if parsed := ParseCode(code); parsed != "" {
+		code = parsed
+	}
+	var ret []ErrorCode
+	for _, c := range codes {
+		if c.Code == code {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// Codes 返回服务 component 登记的所有错误码(包括通用错误码)，按错误码排序
+func Codes(component string) []ErrorCode {
+	var ret []ErrorCode
+	for _, c := range codes {
+		if c.Component == component || c.Component == "" {
+			ret = append(ret, c)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Code < ret[j].Code
+	})
+	return ret
+}
+
+// Builder 根据错误码登记表构建 RequestError，各服务的 ErrBuilder 都基于它实现
+type Builder struct {
+	Component string
+}
+
+func (b Builder) Build(msg, text, reqId string, statusCode int) error {
+	err := New(msg, text, reqId, statusCode)
+	if msg == "" && statusCode != 401 {
+		return err
+	}
+	if b.Component != "" {
+		err.Component = b.Component
+	}
+	errCode := ParseCode(msg)
+	err.Code = errCode
+	c, ok := Lookup(b.Component, errCode)
+	switch {
+	case ok:
+		err.ErrorType = c.ErrorType
+		if c.ErrorType == NotImplementedError {
+			err.Message = fmt.Sprintf("this function is not implemented on server, ask server admin for explain: %s", msg)
+		}
+	case statusCode == 401:
+		err.Message = fmt.Sprintf("unauthorized: %v. 1. Please check your qiniu access_key and secret_key are both correct and you're authorized qiniu pandora user. 2. Please check the local time to ensure the consistent with the server time. 3. If you are using the token, please make sure that token has not expired.", msg)
+		err.ErrorType = UnauthorizedError
+	}
+	return err
+}
+
+// retryableCode 判断 RequestError 的错误码是否在登记表中标记为可重试
+func retryableCode(r *RequestError) bool {
+	if r.Code == "" {
+		return false
+	}
+	c, ok := Lookup(r.Component, r.Code)
+	return ok && c.Retryable
+}
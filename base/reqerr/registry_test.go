@@ -0,0 +1,70 @@
+package reqerr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	pipeline := Builder{Component: ComponentPipeline}
+	err := pipeline.Build("E18102: no such repo", "", "reqid", 404)
+	var reqErr *RequestError
+	assert.True(t, errors.As(err, &reqErr))
+	assert.Equal(t, NoSuchRepoError, reqErr.ErrorType)
+	assert.Equal(t, "E18102", reqErr.Code)
+	assert.Equal(t, ComponentPipeline, reqErr.Component)
+	assert.False(t, IsRetryable(err))
+
+	err = pipeline.Build("E9001: not implemented", "", "reqid", 501)
+	assert.True(t, errors.As(err, &reqErr))
+	assert.Equal(t, NotImplementedError, reqErr.ErrorType)
+	assert.Contains(t, reqErr.Message, "not implemented on server")
+
+	err = pipeline.Build("E18124: repo is creating", "", "reqid", 400)
+	assert.True(t, IsRetryable(err))
+
+	// 同一个错误码在不同服务中含义不同
+	err = Builder{Component: ComponentTsdb}.Build("E9001: internal error", "", "reqid", 500)
+	assert.True(t, errors.As(err, &reqErr))
+	assert.Equal(t, InternalServerError, reqErr.ErrorType)
+
+	// 通用错误码
+	err = Builder{Component: ComponentLogdb}.Build("E18670: account frozen", "", "reqid", 403)
+	assert.True(t, errors.Is(err, ErrAccountDisabled))
+
+	err = Builder{Component: ComponentReport}.Build("signature mismatch", "", "reqid", 401)
+	assert.True(t, errors.As(err, &reqErr))
+	assert.Equal(t, UnauthorizedError, reqErr.ErrorType)
+	assert.Contains(t, reqErr.Message, "unauthorized: signature mismatch")
+
+	err = Builder{Component: ComponentLogkit}.Build("E1020: update agent info", "", "reqid", 500)
+	assert.True(t, errors.As(err, &reqErr))
+	assert.Equal(t, ErrUpdateAgentInfo, reqErr.ErrorType)
+}
+
+func TestDescribe(t *testing.T) {
+	assert.Equal(t, "E18102", ParseCode("E18102: no such repo"))
+	assert.Equal(t, "E8111", ParseCode("E8111"))
+	assert.Equal(t, "", ParseCode("Error"))
+	assert.Equal(t, "", ParseCode(""))
+
+	descs := Describe("E9001")
+	assert.Len(t, descs, 3)
+	descs = Describe("E18102: no such repo")
+	assert.Len(t, descs, 1)
+	assert.Equal(t, ComponentPipeline, descs[0].Component)
+	assert.Equal(t, "no such repo", descs[0].Description)
+	assert.Empty(t, Describe("E0"))
+
+	c, ok := Lookup(ComponentTsdb, "E18669")
+	assert.True(t, ok)
+	assert.Equal(t, ErrAccountArrearsProtection, c.ErrorType)
+	_, ok = Lookup(ComponentTsdb, "E18102")
+	assert.False(t, ok)
+
+	codes := Codes(ComponentReport)
+	assert.Len(t, codes, 8)
+	assert.Equal(t, "E18669", codes[0].Code)
+}
@@ -0,0 +1,59 @@
+package request
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/qiniu/pandora-go-sdk/base/config"
+)
+
+type operationKey struct{}
+
+// OperationFromContext 返回请求所属的 Operation，供 config.Middleware 使用
+func OperationFromContext(ctx context.Context) *Operation {
+	op, _ := ctx.Value(operationKey{}).(*Operation)
+	return op
+}
+
+func withOperation(ctx context.Context, op *Operation) context.Context {
+	if OperationFromContext(ctx) == op {
+		return ctx
+	}
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+// NewHTTPClient 根据配置创建各服务使用的 http.Client，
+// cfg.HTTPClient 为空时按 DialTimeout/ResponseTimeout/AllowInsecureServer 创建 Transport，
+// 最后用 cfg.Middlewares 包装 Transport
+func NewHTTPClient(cfg *config.Config) *http.Client {
+	var client http.Client
+	if cfg.HTTPClient != nil {
+		client = *cfg.HTTPClient
+	} else {
+		client.Transport = &http.Transport{
+			DialContext: (&net.Dialer{
+				Timeout:   cfg.DialTimeout,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			ResponseHeaderTimeout: cfg.ResponseTimeout,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: cfg.AllowInsecureServer,
+			},
+		}
+	}
+	if len(cfg.Middlewares) == 0 {
+		return &client
+	}
+	rt := client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(cfg.Middlewares) - 1; i >= 0; i-- {
+		rt = cfg.Middlewares[i](rt)
+	}
+	client.Transport = rt
+	return &client
+}
@@ -0,0 +1,96 @@
+package request
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/qiniu/pandora-go-sdk/base"
+	"github.com/qiniu/pandora-go-sdk/base/config"
+)
+
+// SetCompressible 标记请求的 body 为写入的数据，发送时按 Config.Compression 压缩
+func (r *Request) SetCompressible() {
+	r.compressible = true
+}
+
+func (r *Request) compression() *config.Compression {
+	if c := r.Config.Compression; c != nil {
+		if r.compressible && c.IsCompressOperation(r.Operation.Name) {
+			return c
+		}
+		return nil
+	}
+	// 兼容旧的 Gzip 配置，压缩所有 PostData 请求
+	if r.Config.Gzip && r.Operation.Name == base.OpPostData {
+		return &config.Compression{Type: config.CompressionGzip}
+	}
+	return nil
+}
+
+func (r *Request) compressBody() {
+	c := r.compression()
+	if c == nil || r.Body == nil {
+		return
+	}
+	if _, r.Error = r.Body.Seek(0, io.SeekStart); r.Error != nil {
+		return
+	}
+	var raw []byte
+	if raw, r.Error = ioutil.ReadAll(r.Body); r.Error != nil {
+		return
+	}
+	if int64(len(raw)) < c.MinSize {
+		r.Body.Seek(0, io.SeekStart)
+		return
+	}
+	var buf []byte
+	if buf, r.Error = compress(c, raw); r.Error != nil {
+		return
+	}
+	reader := bytes.NewReader(buf)
+	r.HTTPRequest.Body = newOffsetReader(reader, 0)
+	r.Body = reader
+	r.bodyLength = int64(len(buf))
+	r.HTTPRequest.Header.Set(base.HTTPHeaderContentEncoding, string(c.Type))
+}
+
+func compress(c *config.Compression, raw []byte) ([]byte, error) {
+	switch c.Type {
+	case config.CompressionGzip:
+		level := c.Level
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		var buf bytes.Buffer
+		g, err := gzip.NewWriterLevel(&buf, level)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = g.Write(raw); err != nil {
+			return nil, err
+		}
+		if err = g.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case config.CompressionZstd:
+		opts := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+		if c.Level != 0 {
+			opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(c.Level)))
+		}
+		enc, err := zstd.NewWriter(nil, opts...)
+		if err != nil {
+			return nil, err
+		}
+		defer enc.Close()
+		return enc.EncodeAll(raw, make([]byte, 0, len(raw)/2)), nil
+	case config.CompressionSnappy:
+		return snappy.Encode(nil, raw), nil
+	}
+	return nil, fmt.Errorf("unsupported compression type %q", c.Type)
+}
@@ -0,0 +1,73 @@
+package request
+
+import (
+	"errors"
+	"net"
+	"net/url"
+
+	"github.com/qiniu/pandora-go-sdk/base"
+)
+
+// SetRegion 在配置了 Config.ServiceEndpoints 时把请求发往 region 的 endpoint，需要在 Send 之前调用
+func (r *Request) SetRegion(region string) {
+	if r.endpoints == nil || region == "" {
+		return
+	}
+	candidates := r.endpoints.Candidates(region)
+	if len(candidates) == 0 {
+		return
+	}
+	if err := r.setEndpoint(candidates[0]); err != nil {
+		r.Error = err
+		return
+	}
+	r.candidates, r.endpointIdx = candidates, 0
+}
+
+func (r *Request) setEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint + r.Operation.Path)
+	if err != nil {
+		return err
+	}
+	r.HTTPRequest.URL = u
+	r.HTTPRequest.Host = u.Host
+	return nil
+}
+
+// sendWithFailover 与 send 相同，但在无法连接当前 endpoint 时依次尝试同一 region 的其他 endpoint
+func (r *Request) sendWithFailover() (networkErr bool) {
+	for {
+		networkErr = r.send()
+		if r.endpoints == nil || r.endpointIdx >= len(r.candidates) {
+			return
+		}
+		current := r.candidates[r.endpointIdx]
+		if r.HTTPResponse != nil {
+			r.endpoints.MarkSucceeded(current)
+			return
+		}
+		if !networkErr || !isDialError(r.Error) {
+			return
+		}
+		r.endpoints.MarkFailed(current)
+		if r.endpointIdx+1 >= len(r.candidates) {
+			return
+		}
+		r.endpointIdx++
+		next := r.candidates[r.endpointIdx]
+		r.log.Log(base.LogWarn, "endpoint unreachable, failover", append(r.fields(),
+			base.F(base.FieldError, r.Error), base.F("endpoint", current), base.F("next_endpoint", next))...)
+		if err := r.setEndpoint(next); err != nil {
+			return
+		}
+		if r.rewind(); r.Error != nil {
+			return false
+		}
+	}
+}
+
+// isDialError 判断请求是否在建立连接时失败，这时请求一定没有发到服务端，切换 endpoint 重发是安全的
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
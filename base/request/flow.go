@@ -0,0 +1,61 @@
+package request
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/qiniu/pandora-go-sdk/base/ratelimit"
+	"github.com/qiniu/pandora-go-sdk/base/reqerr"
+)
+
+// flowReader 在读取 body 的同时从流量限速器获取配额，超过每秒流量限制的 body 会分多个限速窗口发送
+type flowReader struct {
+	ctx     context.Context
+	body    io.ReadCloser
+	limiter *ratelimit.Limiter
+	wait    time.Duration
+}
+
+func (f *flowReader) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := f.limiter.AssignWithContext(f.ctx, int64(len(p)))
+	f.wait += time.Since(start)
+	if err != nil {
+		return 0, err
+	}
+	read, err := f.body.Read(p[:n])
+	if int64(read) < n {
+		// 归还没有用掉的配额
+		f.limiter.Fill(n - int64(read))
+	}
+	return read, err
+}
+
+func (f *flowReader) Close() error {
+	return f.body.Close()
+}
+
+// limiterFeedback 把本次请求的结果反馈给自适应限速器：服务端限流或返回 5xx 时降速，成功时逐步恢复
+func (r *Request) limiterFeedback() {
+	if r.reqlimiter == nil && r.flowlimiter == nil {
+		return
+	}
+	throttled := false
+	if r.HTTPResponse != nil {
+		code := r.HTTPResponse.StatusCode
+		throttled = code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+	}
+	throttled = throttled || reqerr.IsThrottled(r.Error)
+	for _, l := range []*ratelimit.Limiter{r.reqlimiter, r.flowlimiter} {
+		if l == nil {
+			continue
+		}
+		if throttled {
+			l.OnThrottled()
+		} else if r.Error == nil {
+			l.OnSuccess()
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package request
+
+import (
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/qiniu/pandora-go-sdk/base"
+	"github.com/qiniu/pandora-go-sdk/base/config"
+)
+
+// fields 返回当前请求的公共日志字段
+func (r *Request) fields() []base.Field {
+	fields := []base.Field{base.F(base.FieldOperation, r.Operation.Name)}
+	if repo := repoFromPath(r.Operation.Path); repo != "" {
+		fields = append(fields, base.F(base.FieldRepo, repo))
+	}
+	if r.HTTPResponse != nil {
+		fields = append(fields, base.F(base.FieldStatus, r.HTTPResponse.StatusCode))
+		if reqId := r.HTTPResponse.Header.Get(base.HTTPHeaderRequestId); reqId != "" {
+			fields = append(fields, base.F(base.FieldRequestId, reqId))
+		}
+	}
+	if r.attempts > 0 {
+		fields = append(fields, base.F(base.FieldAttempt, r.attempts))
+	}
+	if !r.start.IsZero() {
+		fields = append(fields, base.F(base.FieldLatency, time.Since(r.start)))
+	}
+	return fields
+}
+
+func (r *Request) logError(stage string, extra ...base.Field) {
+	if !r.log.Enabled(base.LogError) {
+		return
+	}
+	fields := append(r.fields(), base.F(base.FieldError, r.Error))
+	r.log.Log(base.LogError, stage+" failed", append(fields, extra...)...)
+}
+
+func (r *Request) bodyLogging() *config.BodyLogging {
+	if r.Config.BodyLogging == nil || !r.log.Enabled(base.LogDebug) {
+		return nil
+	}
+	return r.Config.BodyLogging
+}
+
+// captureRequestBody 在压缩前读取 body，用于 debug 日志。需要读取完整的 body，
+// 先截断再脱敏时被截断的字段值匹配不到脱敏规则，会把部分 token 写入日志
+func (r *Request) captureRequestBody() {
+	b := r.bodyLogging()
+	if b == nil || r.Body == nil {
+		return
+	}
+	size, err := r.Body.Seek(0, io.SeekEnd)
+	if err != nil {
+		return
+	}
+	if _, err = r.Body.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+	buf, err := ioutil.ReadAll(r.Body)
+	if _, serr := r.Body.Seek(0, io.SeekStart); err != nil || serr != nil {
+		return
+	}
+	r.loggedBody = buf
+	r.loggedBodySize = size
+}
+
+func (r *Request) logRequest() {
+	b := r.bodyLogging()
+	if b == nil {
+		return
+	}
+	fields := append(r.fields(),
+		base.F("method", r.HTTPRequest.Method),
+		base.F("url", r.HTTPRequest.URL.String()),
+		base.F("header", b.RedactHeader(r.HTTPRequest.Header)),
+	)
+	if r.loggedBody != nil {
+		fields = append(fields, base.F("body", b.RedactBody(r.loggedBody)), base.F("body_size", r.loggedBodySize))
+	}
+	r.log.Log(base.LogDebug, "send request", fields...)
+}
+
+func (r *Request) logResponse(buf []byte) {
+	b := r.bodyLogging()
+	if b == nil {
+		return
+	}
+	r.log.Log(base.LogDebug, "receive response", append(r.fields(), base.F("body", b.RedactBody(buf)))...)
+}
+
+// redactBody 脱敏需要写入错误日志的响应内容，没有配置 BodyLogging 时使用默认规则
+func (r *Request) redactBody(buf []byte) string {
+	b := r.Config.BodyLogging
+	if b == nil {
+		b = config.NewBodyLogging()
+	}
+	return b.RedactBody(buf)
+}
@@ -2,13 +2,14 @@ package request
 
 import (
 	"bytes"
-	"compress/gzip"
+	"context"
 	"crypto/md5"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"os"
@@ -40,6 +41,16 @@ type Request struct {
 	errBuilder       reqerr.ErrBuilder
 	reqlimiter       *ratelimit.Limiter
 	flowlimiter      *ratelimit.Limiter
+	compressible     bool
+	attempts         int
+	bytesReceived    int64
+	log              base.StructuredLogger
+	start            time.Time
+	loggedBody       []byte
+	loggedBodySize   int64
+	endpoints        *config.Endpoints
+	candidates       []string
+	endpointIdx      int
 }
 
 type Operation struct {
@@ -49,7 +60,15 @@ type Operation struct {
 }
 
 func New(cfg *config.Config, client *http.Client, op *Operation, token string, errBuilder reqerr.ErrBuilder, data interface{}) *Request {
-	httpReq, _ := http.NewRequest(op.Method, "", nil)
+	return NewWithContext(context.Background(), cfg, client, op, token, errBuilder, data)
+}
+
+// NewWithContext 创建绑定 ctx 的请求，ctx 被取消时，限速等待与 http 请求都会随之中止
+func NewWithContext(ctx context.Context, cfg *config.Config, client *http.Client, op *Operation, token string, errBuilder reqerr.ErrBuilder, data interface{}) *Request {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	httpReq, _ := http.NewRequestWithContext(withOperation(ctx, op), op.Method, "", nil)
 	var err error
 	var endpoint string
 	switch cfg.ConfigType {
@@ -64,6 +83,13 @@ func New(cfg *config.Config, client *http.Client, op *Operation, token string, e
 	default:
 		endpoint = cfg.Endpoint
 	}
+	endpoints := cfg.ServiceEndpoints[cfg.ConfigType]
+	var candidates []string
+	if endpoints != nil {
+		if candidates = endpoints.Candidates(cfg.DefaultRegion); len(candidates) > 0 {
+			endpoint = candidates[0]
+		}
+	}
 	httpReq.URL, err = url.Parse(endpoint + op.Path)
 	if err != nil {
 		cfg.Logger.Errorf("parse url failed, err: %v", err)
@@ -91,8 +117,14 @@ func New(cfg *config.Config, client *http.Client, op *Operation, token string, e
 		Data:        data,
 		Headers:     map[string]string{},
 		Logger:      logger,
+		log:         cfg.StructuredLogger,
 		token:       token,
 		errBuilder:  errBuilder,
+		endpoints:   endpoints,
+		candidates:  candidates,
+	}
+	if r.log == nil {
+		r.log = base.NewFieldLogger(logger)
 	}
 
 	return r
@@ -174,11 +206,11 @@ func (r *Request) SetVariantBody(v interface{}) error {
 	vv, ok := v.(base.Validator)
 	if !ok {
 		r.Error = fmt.Errorf("invalid type cast, cannot cast to validator")
-		r.Logger.Error(logFormatter(r, "cast to validator"))
+		r.logError("cast to validator")
 		return r.Error
 	}
 	if r.Error = vv.Validate(); r.Error != nil {
-		r.Logger.Error(logFormatter(r, "validate input"))
+		r.logError("validate input")
 		return r.Error
 	}
 
@@ -191,25 +223,22 @@ func (r *Request) SetVariantBody(v interface{}) error {
 
 func (r *Request) SetReaderBody(reader io.ReadSeeker) (err error) {
 	reader.Seek(0, 0)
-	if r.Config.Gzip && r.Operation.Name == base.OpPostData {
-		var buf bytes.Buffer
-		g := gzip.NewWriter(&buf)
-		_, err = io.Copy(g, reader)
-		if err != nil {
-			return
-		}
-		if err = g.Close(); err != nil {
-			return
-		}
-		r.SetHeader("Content-Encoding", "gzip")
-		r.bodyLength = int64(buf.Len())
-		reader = bytes.NewReader(buf.Bytes())
-	}
 	r.HTTPRequest.Body = newOffsetReader(reader, 0)
 	r.Body = reader
 	return
 }
 
+func (r *Request) Context() context.Context {
+	return r.HTTPRequest.Context()
+}
+
+func (r *Request) SetContext(ctx context.Context) {
+	if ctx == nil {
+		return
+	}
+	r.HTTPRequest = r.HTTPRequest.WithContext(withOperation(ctx, r.Operation))
+}
+
 func (r *Request) EnableContentMD5d() {
 	r.EnableContentMD5 = true
 }
@@ -235,62 +264,136 @@ func (r *Request) build() {
 		r.HTTPRequest.Header.Set(k, v)
 	}
 
-	r.HTTPRequest.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
-
+	r.captureRequestBody()
+	if r.compressBody(); r.Error != nil {
+		return
+	}
 	r.handleBody()
 	if r.Error != nil {
 		return
 	}
+	r.sign()
+}
+
+func (r *Request) sign() {
+	r.HTTPRequest.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
 
 	if r.token != "" {
 		r.HTTPRequest.Header.Set("Authorization", r.token)
 		return
 	}
 
-	r.Error = base.Sign(r.Config.Ak, r.Config.Sk, r.HTTPRequest)
+	creds, err := r.Config.GetCredentials()
+	if err != nil {
+		r.Error = err
+		return
+	}
+	r.Error = base.Sign(creds.Ak, creds.Sk, r.HTTPRequest)
 	if r.Error != nil {
 		return
 	}
 }
 
+// Send 发送请求，按照 RetryPolicy 重试。请求没有得到服务端响应时(连接失败、超时、context 取消等)，
+// 返回 ErrorType 为 reqerr.TransportError、StatusCode 为 0 的 *reqerr.RequestError，而不是原始的 *url.Error，
+// 原始错误可以通过 errors.As(err, &urlErr) 或 errors.Is(err, context.Canceled) 获取
 func (r *Request) Send() error {
+	if m := r.Config.MetricsCollector; m != nil {
+		defer r.observe(m, time.Now())
+	}
+	if t := r.Config.Tracer; t != nil {
+		defer r.endSpan(r.startSpan(t))
+	}
+	r.start = time.Now()
 	r.build()
 	if r.Error != nil {
-		r.Logger.Error(logFormatter(r, "build request"))
+		r.logError("build request")
 		return r.Error
 	}
+	r.logRequest()
+	policy := r.Config.RetryPolicy
+	for attempt := 1; ; attempt++ {
+		r.attempts = attempt
+		networkErr := r.sendWithFailover()
+		r.limiterFeedback()
+		if r.Error == nil || !r.shouldRetry(policy, attempt, networkErr) {
+			return r.Error
+		}
+		if m := r.Config.MetricsCollector; m != nil {
+			m.ObserveRetry(r.Operation.Name)
+		}
+		lastErr := r.Error
+		wait := policy.Backoff(attempt, rand.Float64())
+		r.log.Log(base.LogWarn, "send request failed, retrying", append(r.fields(),
+			base.F(base.FieldError, lastErr), base.F("retry_after", wait), base.F("max_attempts", policy.MaxAttempts))...)
+		if err := sleepWithContext(r.Context(), wait); err != nil {
+			return lastErr
+		}
+		if r.rewind(); r.Error != nil {
+			r.logError("rewind body")
+			return r.Error
+		}
+	}
+}
+
+// send 完成一次请求，networkErr 表示请求是否因为传输层错误而失败
+func (r *Request) send() (networkErr bool) {
+	r.HTTPResponse = nil
+	ctx := r.Context()
 	if r.reqlimiter != nil {
-		r.reqlimiter.Assign(1)
+		start := time.Now()
+		_, r.Error = r.reqlimiter.AssignWithContext(ctx, 1)
+		r.observeLimiterWait(config.LimiterRequest, start)
+		if r.Error != nil {
+			r.wrapContextError()
+			r.logError("request rate limit")
+			return
+		}
 	}
-	if r.flowlimiter != nil {
+	if r.flowlimiter != nil && r.bodyLength > r.flowlimiter.GetRateLimit() {
+		// body 超过每秒的流量限制时不能一次拿到全部配额，改为边发送边获取
+		body := r.HTTPRequest.Body
+		fr := &flowReader{ctx: ctx, body: body, limiter: r.flowlimiter}
+		r.HTTPRequest.Body = fr
+		defer func() {
+			r.HTTPRequest.Body = body
+			if m := r.Config.MetricsCollector; m != nil {
+				m.ObserveLimiterWait(r.Operation.Name, config.LimiterFlow, fr.wait)
+			}
+		}()
+	} else if r.flowlimiter != nil {
 		bandneed := r.bodyLength
-		if bandneed > r.flowlimiter.GetRateLimit() {
-			r.Error = r.errBuilder.Build("E18005",
-				fmt.Sprintf("can not send request, as body size %v larger than flow rate limit %v", bandneed, r.flowlimiter.GetRateLimit()),
-				"NOTSENDYET", 400)
-			r.Logger.Error(logFormatter(r, "flow rate limit"))
-			return r.Error
-		}
+		start := time.Now()
 		for bandneed > 0 {
-			ret := r.flowlimiter.Assign(bandneed)
+			var ret int64
+			if ret, r.Error = r.flowlimiter.AssignWithContext(ctx, bandneed); r.Error != nil {
+				r.wrapContextError()
+				r.observeLimiterWait(config.LimiterFlow, start)
+				r.logError("flow rate limit")
+				return
+			}
 			bandneed -= ret
 		}
+		r.observeLimiterWait(config.LimiterFlow, start)
 	}
 
 	r.HTTPResponse, r.Error = r.HTTPClient.Do(r.HTTPRequest)
 	if r.Error != nil {
-		r.Logger.Error(logFormatter(r, "send request"))
-		return r.Error
+		r.Error = reqerr.NewTransportError(r.Error)
+		r.logError("send request")
+		return true
 	}
 
 	buf := r.readResponse()
+	r.bytesReceived += int64(len(buf))
+	r.logResponse(buf)
 	if r.Error != nil {
-		r.Logger.Error(logFormatter(r, "read response"))
+		r.logError("read response")
 		r.Error = r.errBuilder.Build(r.Error.Error(),
 			r.Error.Error(),
 			r.HTTPResponse.Header.Get(base.HTTPHeaderRequestId),
 			r.HTTPResponse.StatusCode)
-		return r.Error
+		return
 	}
 	if r.HTTPResponse.StatusCode == 200 {
 		r.unmarshal(buf)
@@ -300,12 +403,93 @@ func (r *Request) Send() error {
 				string(buf),
 				r.HTTPResponse.Header.Get(base.HTTPHeaderRequestId),
 				r.HTTPResponse.StatusCode)
-			r.Logger.Error(logFormatter(r, "receive non-json response"))
-			return r.Error
+			r.logError("receive non-json response")
+			return
 		}
 		r.unmarshalError(buf)
 	}
-	return r.Error
+	return
+}
+
+// wrapContextError 把等待限速时 context 取消或超时的错误包装为传输层错误，与请求发出后被取消时返回的错误一致
+func (r *Request) wrapContextError() {
+	if r.Context().Err() != nil {
+		r.Error = reqerr.NewTransportError(r.Error)
+	}
+}
+
+func (r *Request) shouldRetry(policy *config.RetryPolicy, attempt int, networkErr bool) bool {
+	if policy == nil || attempt >= policy.MaxAttempts {
+		return false
+	}
+	if r.Context().Err() != nil {
+		return false
+	}
+	if !policy.IsRetryableOperation(r.Operation.Name, r.HTTPRequest.Method) {
+		return false
+	}
+	if networkErr {
+		return policy.RetryOnNetworkError
+	}
+	if r.HTTPResponse != nil && policy.IsRetryableStatusCode(r.HTTPResponse.StatusCode) {
+		return true
+	}
+	if reqErr, ok := r.Error.(*reqerr.RequestError); ok && r.HTTPResponse != nil {
+		return policy.IsRetryableErrorType(reqErr.ErrorType)
+	}
+	return false
+}
+
+// rewind 将 body 重置到开头并重新签名，以便下一次重试
+func (r *Request) rewind() {
+	r.Error = nil
+	if body, ok := r.HTTPRequest.Body.(*offsetReader); ok {
+		r.HTTPRequest.Body = body.CloseAndCopy(0)
+	}
+	r.sign()
+}
+
+func (r *Request) observe(m config.MetricsCollector, start time.Time) {
+	metrics := &config.RequestMetrics{
+		Operation:     r.Operation.Name,
+		Error:         r.Error,
+		Latency:       time.Since(start),
+		BytesReceived: r.bytesReceived,
+		Attempts:      r.attempts,
+	}
+	if r.HTTPResponse != nil {
+		metrics.StatusCode = r.HTTPResponse.StatusCode
+	}
+	metrics.BytesSent = r.sentBodyLength() * int64(r.attempts)
+	m.ObserveRequest(metrics)
+}
+
+// sentBodyLength 返回实际发送的 body 长度，压缩时为压缩后的长度
+func (r *Request) sentBodyLength() int64 {
+	if r.HTTPRequest.ContentLength > 0 {
+		return r.HTTPRequest.ContentLength
+	}
+	return r.bodyLength
+}
+
+func (r *Request) observeLimiterWait(limiter string, start time.Time) {
+	if m := r.Config.MetricsCollector; m != nil {
+		m.ObserveLimiterWait(r.Operation.Name, limiter, time.Since(start))
+	}
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (r *Request) unmarshal(buf []byte) {
@@ -316,7 +500,7 @@ func (r *Request) unmarshal(buf []byte) {
 	}
 	r.Error = json.Unmarshal(buf, &r.Data)
 	if r.Error != nil {
-		r.Logger.Error(logFormatter(r, fmt.Sprintf("unmarshal response: %s", string(buf))))
+		r.logError("unmarshal response", base.F("response", r.redactBody(buf)))
 		return
 	}
 }
@@ -327,7 +511,7 @@ func (r *Request) unmarshalError(buf []byte) {
 		err1 := json.Unmarshal(buf, &err)
 		if err1 != nil {
 			r.Error = err1
-			r.Logger.Error(logFormatter(r, "unmarshal error"))
+			r.logError("unmarshal error")
 			return
 		}
 	} else {
@@ -348,8 +532,3 @@ func (r *Request) readResponse() (out []byte) {
 	}
 	return
 }
-
-func logFormatter(r *Request, stage string) string {
-	return fmt.Sprintf("%s failed, operation %s, error %v",
-		stage, r.Operation.Name, r.Error)
-}
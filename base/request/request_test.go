@@ -1,10 +1,28 @@
 package request
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/qiniu/pandora-go-sdk/base"
 	"github.com/qiniu/pandora-go-sdk/base/config"
+	"github.com/qiniu/pandora-go-sdk/base/ratelimit"
+	"github.com/qiniu/pandora-go-sdk/base/reqerr"
 )
 
 func BenchmarkGzip(b *testing.B) {
@@ -32,3 +50,301 @@ func BenchmarkNoGzip(b *testing.B) {
 	}
 	//	fmt.Println(req.bodyLength)
 }
+
+func TestSendRetry(t *testing.T) {
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		bodies = append(bodies, string(b))
+		if len(bodies) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	policy := config.NewRetryPolicy().WithBackoff(time.Millisecond, 10*time.Millisecond)
+	cfg := &config.Config{Endpoint: ts.URL, RetryPolicy: policy}
+	op := &Operation{Name: base.OpPostData, Method: base.MethodPost, Path: "/v2/repos/repo/data"}
+
+	// POST 默认不重试
+	req := New(cfg, http.DefaultClient, op, "", errBuilder{}, nil)
+	req.SetStringBody("a=1")
+	assert.Error(t, req.Send())
+	assert.Equal(t, []string{"a=1"}, bodies)
+
+	bodies = nil
+	policy.WithRetryOperations(base.OpPostData)
+	req = New(cfg, http.DefaultClient, op, "", errBuilder{}, nil)
+	req.SetStringBody("a=1")
+	assert.NoError(t, req.Send())
+	assert.Equal(t, []string{"a=1", "a=1", "a=1"}, bodies)
+
+	bodies = nil
+	policy.WithMaxAttempts(2)
+	req = New(cfg, http.DefaultClient, op, "", errBuilder{}, nil)
+	req.SetStringBody("a=1")
+	err := req.Send()
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, err.(*reqerr.RequestError).StatusCode)
+	assert.Len(t, bodies, 2)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := config.NewRetryPolicy().WithBackoff(100*time.Millisecond, time.Second).WithJitter(0.5)
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1, 0))
+	assert.Equal(t, 400*time.Millisecond, policy.Backoff(3, 0))
+	assert.Equal(t, time.Second, policy.Backoff(10, 0))
+	assert.Equal(t, 500*time.Millisecond, policy.Backoff(10, 1))
+}
+
+type errBuilder struct{}
+
+func (errBuilder) Build(message, rawText, reqId string, statusCode int) error {
+	return reqerr.New(message, rawText, reqId, statusCode)
+}
+
+func TestMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "yes", req.Header.Get("X-Injected"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	var calls []string
+	record := func(name string) config.Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return config.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				op := OperationFromContext(req.Context())
+				req.Header.Set("X-Injected", "yes")
+				resp, err := next.RoundTrip(req)
+				calls = append(calls, fmt.Sprintf("%s:%s:%d", name, op.Name, resp.StatusCode))
+				return resp, err
+			})
+		}
+	}
+	cfg := (&config.Config{Endpoint: ts.URL}).WithMiddleware(record("outer"), record("inner"))
+	op := &Operation{Name: base.OpGetRepo, Method: base.MethodGet, Path: "/v2/repos/repo"}
+	req := New(cfg, NewHTTPClient(cfg), op, "", errBuilder{}, nil)
+	assert.NoError(t, req.Send())
+	assert.Equal(t, []string{"inner:GetRepo:200", "outer:GetRepo:200"}, calls)
+}
+
+func TestCompression(t *testing.T) {
+	var encoding string
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		encoding = req.Header.Get(base.HTTPHeaderContentEncoding)
+		body, _ = ioutil.ReadAll(req.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	data := strings.Repeat("a=1 b=2\n", 1000)
+	op := &Operation{Name: base.OpPostData, Method: base.MethodPost, Path: "/v2/repos/repo/data"}
+	send := func(cfg *config.Config, compressible bool, payload string) {
+		req := New(cfg, http.DefaultClient, op, "", errBuilder{}, nil)
+		req.SetBufferBody([]byte(payload))
+		if compressible {
+			req.SetCompressible()
+		}
+		assert.NoError(t, req.Send())
+	}
+
+	cases := []struct {
+		typ        config.CompressionType
+		decompress func([]byte) ([]byte, error)
+	}{
+		{config.CompressionGzip, func(b []byte) ([]byte, error) {
+			r, err := gzip.NewReader(bytes.NewReader(b))
+			if err != nil {
+				return nil, err
+			}
+			return ioutil.ReadAll(r)
+		}},
+		{config.CompressionZstd, func(b []byte) ([]byte, error) {
+			d, err := zstd.NewReader(nil)
+			if err != nil {
+				return nil, err
+			}
+			defer d.Close()
+			return d.DecodeAll(b, nil)
+		}},
+		{config.CompressionSnappy, func(b []byte) ([]byte, error) {
+			return snappy.Decode(nil, b)
+		}},
+	}
+	for _, c := range cases {
+		cfg := (&config.Config{Endpoint: ts.URL}).WithCompression(config.NewCompression(c.typ).WithLevel(3))
+		send(cfg, true, data)
+		assert.Equal(t, string(c.typ), encoding)
+		assert.True(t, len(body) < len(data))
+		raw, err := c.decompress(body)
+		assert.NoError(t, err)
+		assert.Equal(t, data, string(raw))
+
+		// 小于 MinSize 或者不是写入数据的请求不压缩
+		send(cfg, true, "a=1")
+		assert.Equal(t, "", encoding)
+		assert.Equal(t, "a=1", string(body))
+		send(cfg, false, data)
+		assert.Equal(t, "", encoding)
+		assert.Equal(t, data, string(body))
+	}
+
+	// 兼容旧的 Gzip 配置
+	send(&config.Config{Endpoint: ts.URL, Gzip: true}, false, "a=1")
+	assert.Equal(t, "gzip", encoding)
+}
+
+type recordLogger struct {
+	entries []string
+}
+
+func (l *recordLogger) Enabled(level base.LogLevelType) bool { return true }
+
+func (l *recordLogger) Log(level base.LogLevelType, msg string, fields ...base.Field) {
+	l.entries = append(l.entries, base.FormatFields(msg, fields...))
+}
+
+func TestBodyLogging(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set(base.HTTPHeaderRequestId, "reqid")
+		w.Header().Set(base.HTTPHeaderContentType, "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"E18102: no such repo","token":"secret"}`))
+	}))
+	defer ts.Close()
+
+	logger := &recordLogger{}
+	cfg := (&config.Config{Endpoint: ts.URL}).WithStructuredLogger(logger).WithBodyLogging(config.NewBodyLogging().WithMaxSize(64))
+	op := &Operation{Name: base.OpPostData, Method: base.MethodPost, Path: "/v2/repos/repo/data"}
+	req := New(cfg, http.DefaultClient, op, "Pandora token", errBuilder{}, nil)
+	req.SetBufferBody([]byte(`{"pandoraToken": "abc", "data": "` + strings.Repeat("x", 100) + `"}`))
+	assert.Error(t, req.Send())
+
+	assert.Len(t, logger.entries, 2)
+	sent := logger.entries[0]
+	assert.Contains(t, sent, "send request operation=PostData repo=repo")
+	assert.NotContains(t, sent, "Pandora token")
+	assert.NotContains(t, sent, "abc")
+	assert.Contains(t, sent, `\"pandoraToken\": \"******\"`)
+	assert.Contains(t, sent, "body_size=135")
+	received := logger.entries[1]
+	assert.Contains(t, received, "receive response operation=PostData repo=repo status=404 request_id=reqid attempt=1")
+	assert.NotContains(t, received, "secret")
+}
+
+func TestFlowLimit(t *testing.T) {
+	var received int
+	status := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		received = len(body)
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	cfg := &config.Config{Endpoint: ts.URL}
+	limiter := config.NewAdaptiveRateLimit().WithIncrease(0.05, time.Hour).NewLimiter(2000)
+	defer limiter.Close()
+	op := &Operation{Name: base.OpPostData, Method: base.MethodPost, Path: "/v2/repos/repo/data"}
+	send := func(size int) error {
+		req := New(cfg, http.DefaultClient, op, "", errBuilder{}, nil)
+		req.SetBufferBody(bytes.Repeat([]byte("a"), size))
+		req.SetFlowLimiter(limiter)
+		return req.Send()
+	}
+
+	// 超过每秒流量限制的 body 分多个窗口发送，而不是直接失败
+	start := time.Now()
+	assert.NoError(t, send(3000))
+	assert.Equal(t, 3000, received)
+	assert.True(t, time.Since(start) >= 400*time.Millisecond)
+
+	status = http.StatusTooManyRequests
+	assert.Error(t, send(10))
+	assert.Equal(t, int64(1000), limiter.GetRateLimit())
+}
+
+func TestEndpointFailover(t *testing.T) {
+	var hosts []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		hosts = append(hosts, req.Host+" "+string(b))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	// 监听后立即关闭，得到一个无法连接的地址
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	down := "http://" + l.Addr().String()
+	l.Close()
+
+	endpoints := config.NewEndpoints().
+		WithRegion("nb", down, ts.URL).
+		WithRegion("hd", ts.URL)
+	cfg := &config.Config{
+		Endpoint:         "http://127.0.0.1:1",
+		ConfigType:       config.TypePipeline,
+		DefaultRegion:    "nb",
+		ServiceEndpoints: map[string]*config.Endpoints{config.TypePipeline: endpoints},
+	}
+	op := &Operation{Name: base.OpPostData, Method: base.MethodPost, Path: "/v2/repos/repo/data"}
+	tsHost := strings.TrimPrefix(ts.URL, "http://")
+
+	req := New(cfg, http.DefaultClient, op, "", errBuilder{}, nil)
+	req.SetStringBody("a=1")
+	assert.NoError(t, req.Send())
+	assert.Equal(t, []string{tsHost + " a=1"}, hosts)
+	assert.False(t, endpoints.Healthy(down))
+	assert.Equal(t, []string{ts.URL, down}, endpoints.Candidates("nb"))
+
+	// 冷却中的 endpoint 排在后面，不再先尝试
+	hosts = nil
+	req = New(cfg, http.DefaultClient, op, "", errBuilder{}, nil)
+	assert.Equal(t, tsHost, req.HTTPRequest.Host)
+	req.SetStringBody("a=2")
+	assert.NoError(t, req.Send())
+	assert.Equal(t, []string{tsHost + " a=2"}, hosts)
+
+	// 只有一个 endpoint 且无法连接时返回传输层错误
+	endpoints.WithRegion("sh", down)
+	req = New(cfg, http.DefaultClient, op, "", errBuilder{}, nil)
+	req.SetRegion("sh")
+	req.SetStringBody("a=3")
+	err = req.Send()
+	assert.Equal(t, reqerr.TransportError, err.(*reqerr.RequestError).ErrorType)
+}
+
+func TestSendContextCanceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	cfg := &config.Config{Endpoint: ts.URL}
+	op := &Operation{Name: base.OpPostData, Method: base.MethodPost, Path: "/v2/repos/repo/data"}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// 请求发出时 context 已经取消
+	req := NewWithContext(ctx, cfg, http.DefaultClient, op, "", errBuilder{}, nil)
+	err := req.Send()
+	assert.Equal(t, reqerr.TransportError, err.(*reqerr.RequestError).ErrorType)
+	assert.True(t, errors.Is(err, context.Canceled))
+	var urlErr *url.Error
+	assert.True(t, errors.As(err, &urlErr))
+
+	// 等待限速时 context 取消，返回同样的错误
+	limiter := ratelimit.NewLimiter(1)
+	defer limiter.Close()
+	limiter.Assign(1)
+	req = NewWithContext(ctx, cfg, http.DefaultClient, op, "", errBuilder{}, nil)
+	req.SetReqLimiter(limiter)
+	err = req.Send()
+	assert.Equal(t, reqerr.TransportError, err.(*reqerr.RequestError).ErrorType)
+	assert.Equal(t, 0, err.(*reqerr.RequestError).StatusCode)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
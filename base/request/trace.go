@@ -0,0 +1,50 @@
+package request
+
+import (
+	"strings"
+
+	"github.com/qiniu/pandora-go-sdk/base"
+	"github.com/qiniu/pandora-go-sdk/base/config"
+)
+
+const headerTraceParent = "traceparent"
+
+func (r *Request) startSpan(t config.Tracer) config.Span {
+	ctx, span := t.StartSpan(r.Context(), r.Operation.Name)
+	r.SetContext(ctx)
+	span.SetAttribute(config.SpanAttrOperation, r.Operation.Name)
+	span.SetAttribute(config.SpanAttrMethod, r.Operation.Method)
+	if repo := repoFromPath(r.Operation.Path); repo != "" {
+		span.SetAttribute(config.SpanAttrRepo, repo)
+	}
+	if tp := span.TraceParent(); tp != "" {
+		r.SetHeader(headerTraceParent, tp)
+	}
+	return span
+}
+
+func (r *Request) endSpan(span config.Span) {
+	span.SetAttribute(config.SpanAttrAttempts, r.attempts)
+	span.SetAttribute(config.SpanAttrRequestSize, r.sentBodyLength())
+	if r.HTTPResponse != nil {
+		span.SetAttribute(config.SpanAttrStatusCode, r.HTTPResponse.StatusCode)
+		if reqId := r.HTTPResponse.Header.Get(base.HTTPHeaderRequestId); reqId != "" {
+			span.SetAttribute(config.SpanAttrRequestId, reqId)
+		}
+	}
+	span.End(r.Error)
+}
+
+// repoFromPath 从形如 /v2/repos/<repo>/data 的路径中取出 repo 名称
+func repoFromPath(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "repos" {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
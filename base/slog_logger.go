@@ -0,0 +1,51 @@
+//go:build go1.21
+// +build go1.21
+
+package base
+
+import (
+	"context"
+	"log/slog"
+)
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// NewSlogLogger 把标准库的 *slog.Logger 适配为 StructuredLogger，Panic 与 Fatal 级别按 Error 输出
+func NewSlogLogger(l *slog.Logger) StructuredLogger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return &slogLogger{l: l}
+}
+
+func slogLevel(level LogLevelType) slog.Level {
+	switch level {
+	case LogDebug:
+		return slog.LevelDebug
+	case LogInfo:
+		return slog.LevelInfo
+	case LogWarn:
+		return slog.LevelWarn
+	}
+	return slog.LevelError
+}
+
+func (s *slogLogger) Enabled(level LogLevelType) bool {
+	if level >= LogOff {
+		return false
+	}
+	return s.l.Enabled(context.Background(), slogLevel(level))
+}
+
+func (s *slogLogger) Log(level LogLevelType, msg string, fields ...Field) {
+	if !s.Enabled(level) {
+		return
+	}
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, f := range fields {
+		attrs = append(attrs, slog.Any(f.Key, f.Value))
+	}
+	s.l.LogAttrs(context.Background(), slogLevel(level), msg, attrs...)
+}
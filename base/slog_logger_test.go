@@ -0,0 +1,30 @@
+//go:build go1.21
+// +build go1.21
+
+package base
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	assert.False(t, l.Enabled(LogDebug))
+	assert.True(t, l.Enabled(LogError))
+	assert.False(t, l.Enabled(LogOff))
+
+	l.Log(LogDebug, "ignored")
+	l.Log(LogError, "send request failed", F(FieldOperation, "PostData"), F(FieldStatus, 500))
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "ERROR", entry["level"])
+	assert.Equal(t, "send request failed", entry["msg"])
+	assert.Equal(t, "PostData", entry["operation"])
+	assert.Equal(t, float64(500), entry["status"])
+}
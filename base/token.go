@@ -5,10 +5,12 @@ import (
 	"crypto/sha1"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -91,3 +93,86 @@ func MakeTokenInternal(ak, sk string, desc *TokenDesc) (token string, err error)
 
 	return fmt.Sprintf("Pandora %s:%s:%s", ak, encodedSign, encodedTokenDesc), nil
 }
+
+const tokenPrefix = "Pandora "
+
+var (
+	ErrInvalidToken      = errors.New("invalid pandora token")
+	ErrTokenAkMismatch   = errors.New("token is not issued by the access key")
+	ErrTokenSignMismatch = errors.New("token signature mismatch")
+	ErrTokenExpired      = errors.New("token has expired")
+)
+
+// TokenInfo 是从 token 中解析出的内容，Desc 的 Headers 只包含参与签名的 X-Qiniu- 开头的请求头
+type TokenInfo struct {
+	Ak   string
+	Sign string
+	Desc *TokenDesc
+
+	encodedDesc string
+}
+
+func (t *TokenInfo) ExpiresAt() time.Time {
+	return time.Unix(t.Desc.Expires, 0)
+}
+
+func (t *TokenInfo) Expired() bool {
+	return time.Now().Unix() > t.Desc.Expires
+}
+
+// ParseToken 解析 MakeTokenInternal 生成的 token，不校验签名与有效期
+func ParseToken(token string) (info *TokenInfo, err error) {
+	if !strings.HasPrefix(token, tokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+	parts := strings.Split(strings.TrimPrefix(token, tokenPrefix), ":")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		return nil, ErrInvalidToken
+	}
+	marshaled, err := base64.URLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%v: decode token description: %v", ErrInvalidToken, err)
+	}
+	var td tokenDesc
+	if err = json.Unmarshal(marshaled, &td); err != nil {
+		return nil, fmt.Errorf("%v: unmarshal token description: %v", ErrInvalidToken, err)
+	}
+	desc := &TokenDesc{
+		Url:         td.Resource,
+		Expires:     td.Expires,
+		ContentMD5:  td.ContentMD5,
+		ContentType: td.ContentType,
+		Method:      td.Method,
+	}
+	if i := strings.IndexByte(td.Resource, '?'); i >= 0 {
+		desc.Url = td.Resource[:i]
+		if desc.QueryString, err = url.ParseQuery(td.Resource[i+1:]); err != nil {
+			return nil, fmt.Errorf("%v: parse resource query: %v", ErrInvalidToken, err)
+		}
+	}
+	for _, line := range strings.Split(td.Headers, "\n") {
+		if kv := strings.SplitN(line, ":", 2); len(kv) == 2 {
+			desc.SetHeader(kv[0], kv[1])
+		}
+	}
+	return &TokenInfo{Ak: parts[0], Sign: parts[1], Desc: desc, encodedDesc: parts[2]}, nil
+}
+
+// VerifyToken 解析 token 并用 ak/sk 校验签名与有效期，token 过期时同时返回解析结果与 ErrTokenExpired
+func VerifyToken(ak, sk, token string) (info *TokenInfo, err error) {
+	if info, err = ParseToken(token); err != nil {
+		return
+	}
+	if info.Ak != ak {
+		return nil, ErrTokenAkMismatch
+	}
+	h := hmac.New(sha1.New, []byte(sk))
+	io.WriteString(h, info.encodedDesc)
+	if !hmac.Equal([]byte(base64.URLEncoding.EncodeToString(h.Sum(nil))), []byte(info.Sign)) {
+		return nil, ErrTokenSignMismatch
+	}
+	if info.Expired() {
+		return info, ErrTokenExpired
+	}
+	return
+}
@@ -0,0 +1,139 @@
+package base
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTokenTTL     = 24 * time.Hour
+	defaultTokenRefresh = 10 * time.Minute
+)
+
+// TokenMaker 根据 TokenDesc 签发 token，如 pipeline.Pipeline.MakeToken 或以 ak/sk 调用 MakeTokenInternal
+type TokenMaker func(desc *TokenDesc) (string, error)
+
+type cachedToken struct {
+	desc    TokenDesc
+	token   string
+	expires time.Time
+}
+
+// TokenManager 按 TokenDesc 模板签发并缓存 token，在 token 过期前 RefreshBefore 时间重新签发，
+// 模板中的 Expires 会被忽略，签发时设置为当前时间加 TTL
+type TokenManager struct {
+	maker         TokenMaker
+	TTL           time.Duration
+	RefreshBefore time.Duration
+
+	mu     sync.Mutex
+	tokens map[string]*cachedToken
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func NewTokenManager(maker TokenMaker) *TokenManager {
+	return &TokenManager{
+		maker:         maker,
+		TTL:           defaultTokenTTL,
+		RefreshBefore: defaultTokenRefresh,
+		tokens:        make(map[string]*cachedToken),
+	}
+}
+
+func tokenKey(desc *TokenDesc) string {
+	var headers []string
+	for k, vs := range desc.Headers {
+		headers = append(headers, k+"="+strings.Join(vs, ","))
+	}
+	sort.Strings(headers)
+	return fmt.Sprintf("%s %s?%s %s %s %s", desc.Method, desc.Url, desc.QueryString.Encode(),
+		desc.ContentType, desc.ContentMD5, strings.Join(headers, "&"))
+}
+
+// Token 返回 desc 对应的 token，缓存的 token 即将过期时重新签发
+func (m *TokenManager) Token(desc TokenDesc) (string, error) {
+	key := tokenKey(&desc)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t, ok := m.tokens[key]; ok && time.Now().Add(m.RefreshBefore).Before(t.expires) {
+		return t.token, nil
+	}
+	t, err := m.issue(desc)
+	if err != nil {
+		return "", err
+	}
+	m.tokens[key] = t
+	return t.token, nil
+}
+
+func (m *TokenManager) issue(desc TokenDesc) (*cachedToken, error) {
+	expires := time.Now().Add(m.TTL)
+	desc.Expires = expires.Unix()
+	token, err := m.maker(&desc)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedToken{desc: desc, token: token, expires: expires}, nil
+}
+
+// Refresh 重新签发所有即将过期的缓存 token，返回第一个签发失败的错误
+func (m *TokenManager) Refresh() (err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	deadline := time.Now().Add(m.RefreshBefore)
+	for key, t := range m.tokens {
+		if deadline.Before(t.expires) {
+			continue
+		}
+		nt, issueErr := m.issue(t.desc)
+		if issueErr != nil {
+			if err == nil {
+				err = issueErr
+			}
+			continue
+		}
+		m.tokens[key] = nt
+	}
+	return
+}
+
+// Start 启动后台 goroutine，每隔 interval 调用一次 Refresh，使分发出去的 token 始终有足够的有效期
+func (m *TokenManager) Start(interval time.Duration) {
+	m.mu.Lock()
+	if m.done != nil {
+		m.mu.Unlock()
+		return
+	}
+	m.done = make(chan struct{})
+	done := m.done
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.Refresh()
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+func (m *TokenManager) Close() {
+	m.mu.Lock()
+	if m.done != nil {
+		close(m.done)
+		m.done = nil
+	}
+	m.mu.Unlock()
+	m.wg.Wait()
+}
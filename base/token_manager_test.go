@@ -0,0 +1,44 @@
+package base
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenManager(t *testing.T) {
+	issued := 0
+	m := NewTokenManager(func(desc *TokenDesc) (string, error) {
+		issued++
+		return MakeTokenInternal("ak", "sk", desc)
+	})
+	desc := TokenDesc{Url: "/v2/repos/repo/data", Method: MethodPost, ContentType: ContentTypeText}
+
+	t1, err := m.Token(desc)
+	assert.NoError(t, err)
+	t2, err := m.Token(desc)
+	assert.NoError(t, err)
+	assert.Equal(t, t1, t2)
+	assert.Equal(t, 1, issued)
+
+	other := desc
+	other.Method = MethodGet
+	_, err = m.Token(other)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, issued)
+
+	// 剩余有效期不足 RefreshBefore 时重新签发
+	m.RefreshBefore = 2 * m.TTL
+	assert.NoError(t, m.Refresh())
+	assert.Equal(t, 4, issued)
+	_, err = m.Token(desc)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, issued)
+
+	m.RefreshBefore = time.Minute
+	m.Start(time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	m.Close()
+	assert.Equal(t, 5, issued)
+}
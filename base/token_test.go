@@ -0,0 +1,63 @@
+package base
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyToken(t *testing.T) {
+	desc := &TokenDesc{
+		Url:         "/v2/repos/repo/data",
+		Expires:     time.Now().Add(time.Hour).Unix(),
+		ContentType: ContentTypeText,
+		Method:      MethodPost,
+	}
+	desc.SetHeader("X-Qiniu-Owner", "alice")
+	token, err := MakeTokenInternal("ak", "sk", desc)
+	assert.NoError(t, err)
+
+	info, err := VerifyToken("ak", "sk", token)
+	assert.NoError(t, err)
+	assert.Equal(t, "ak", info.Ak)
+	assert.Equal(t, desc.Url, info.Desc.Url)
+	assert.Equal(t, desc.Method, info.Desc.Method)
+	assert.Equal(t, desc.ContentType, info.Desc.ContentType)
+	assert.Equal(t, desc.Expires, info.Desc.Expires)
+	assert.Equal(t, "alice", info.Desc.Headers.Get("X-Qiniu-Owner"))
+	assert.False(t, info.Expired())
+
+	_, err = VerifyToken("ak", "wrong", token)
+	assert.Equal(t, ErrTokenSignMismatch, err)
+	_, err = VerifyToken("other", "sk", token)
+	assert.Equal(t, ErrTokenAkMismatch, err)
+	_, err = ParseToken("Pandora ak:sign")
+	assert.Error(t, err)
+	_, err = ParseToken("Bearer x")
+	assert.Equal(t, ErrInvalidToken, err)
+
+	// 篡改 token 内容后签名校验失败
+	info, _ = ParseToken(token)
+	info.Desc.Method = MethodDelete
+	forged, _ := MakeTokenInternal("ak", "another-sk", info.Desc)
+	_, err = VerifyToken("ak", "sk", forged)
+	assert.Equal(t, ErrTokenSignMismatch, err)
+
+	// MakeTokenInternal 不允许生成已过期的 token，这里手动构造
+	td := newTokenDesc(desc)
+	td.Expires = time.Now().Add(-time.Hour).Unix()
+	marshaled, _ := json.Marshal(td)
+	encoded := base64.URLEncoding.EncodeToString(marshaled)
+	h := hmac.New(sha1.New, []byte("sk"))
+	h.Write([]byte(encoded))
+	expired := "Pandora ak:" + base64.URLEncoding.EncodeToString(h.Sum(nil)) + ":" + encoded
+	info, err = VerifyToken("ak", "sk", expired)
+	assert.Equal(t, ErrTokenExpired, err)
+	assert.True(t, info.Expired())
+	assert.Equal(t, td.Expires, info.ExpiresAt().Unix())
+}
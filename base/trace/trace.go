@@ -0,0 +1,108 @@
+// Package trace 提供 config.Tracer 的一个简单实现，生成 W3C Trace Context 格式的 trace id 与 span id，
+// 结束的 span 交给 Exporter 处理。接入 OpenTelemetry 时可以直接实现 config.Tracer 而不使用本包
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qiniu/pandora-go-sdk/base/config"
+)
+
+// SpanData 是一个已结束 span 的全部信息
+type SpanData struct {
+	Name         string
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Start        time.Time
+	End          time.Time
+	Attributes   map[string]interface{}
+	Err          error
+}
+
+// Exporter 处理结束的 span，会被多个 goroutine 并发调用
+type Exporter func(*SpanData)
+
+type Tracer struct {
+	exporter Exporter
+}
+
+var _ config.Tracer = (*Tracer)(nil)
+
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{exporter: exporter}
+}
+
+type spanKey struct{}
+
+type parentSpan struct {
+	traceID string
+	spanID  string
+}
+
+// ContextWithTraceParent 把上游传入的 traceparent 请求头放入 ctx，之后的请求会作为它的子 span
+func ContextWithTraceParent(ctx context.Context, traceParent string) (context.Context, error) {
+	parts := strings.Split(traceParent, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return ctx, fmt.Errorf("invalid traceparent %q", traceParent)
+	}
+	return context.WithValue(ctx, spanKey{}, &parentSpan{traceID: parts[1], spanID: parts[2]}), nil
+}
+
+func (t *Tracer) StartSpan(ctx context.Context, operation string) (context.Context, config.Span) {
+	s := &span{
+		tracer: t,
+		data: SpanData{
+			Name:       operation,
+			SpanID:     randomHex(8),
+			Start:      time.Now(),
+			Attributes: make(map[string]interface{}),
+		},
+	}
+	if p, ok := ctx.Value(spanKey{}).(*parentSpan); ok {
+		s.data.TraceID = p.traceID
+		s.data.ParentSpanID = p.spanID
+	} else {
+		s.data.TraceID = randomHex(16)
+	}
+	return context.WithValue(ctx, spanKey{}, &parentSpan{traceID: s.data.TraceID, spanID: s.data.SpanID}), s
+}
+
+type span struct {
+	tracer *Tracer
+	mu     sync.Mutex
+	data   SpanData
+}
+
+func (s *span) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	s.data.Attributes[key] = value
+	s.mu.Unlock()
+}
+
+func (s *span) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-01", s.data.TraceID, s.data.SpanID)
+}
+
+func (s *span) End(err error) {
+	s.mu.Lock()
+	s.data.End = time.Now()
+	s.data.Err = err
+	data := s.data
+	s.mu.Unlock()
+	if s.tracer.exporter != nil {
+		s.tracer.exporter(&data)
+	}
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,58 @@
+package trace
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/qiniu/pandora-go-sdk/base"
+	"github.com/qiniu/pandora-go-sdk/base/config"
+	"github.com/qiniu/pandora-go-sdk/base/reqerr"
+	"github.com/qiniu/pandora-go-sdk/base/request"
+)
+
+type errBuilder struct{}
+
+func (errBuilder) Build(message, rawText, reqId string, statusCode int) error {
+	return reqerr.New(message, rawText, reqId, statusCode)
+}
+
+func TestTracer(t *testing.T) {
+	var traceParent string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		traceParent = req.Header.Get("traceparent")
+		w.Header().Set(base.HTTPHeaderRequestId, "reqid-1")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	var spans []*SpanData
+	cfg := (&config.Config{Endpoint: ts.URL}).WithTracer(NewTracer(func(s *SpanData) { spans = append(spans, s) }))
+	parent := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	ctx, err := ContextWithTraceParent(context.Background(), parent)
+	assert.NoError(t, err)
+
+	op := &request.Operation{Name: base.OpPostData, Method: base.MethodPost, Path: "/v2/repos/my_repo/data"}
+	req := request.NewWithContext(ctx, cfg, http.DefaultClient, op, "", errBuilder{}, nil)
+	req.SetBufferBody([]byte("a=1"))
+	assert.NoError(t, req.Send())
+
+	assert.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal(t, base.OpPostData, s.Name)
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", s.TraceID)
+	assert.Equal(t, "b7ad6b7169203331", s.ParentSpanID)
+	assert.Equal(t, "my_repo", s.Attributes[config.SpanAttrRepo])
+	assert.Equal(t, 200, s.Attributes[config.SpanAttrStatusCode])
+	assert.Equal(t, "reqid-1", s.Attributes[config.SpanAttrRequestId])
+	assert.Equal(t, int64(3), s.Attributes[config.SpanAttrRequestSize])
+	assert.True(t, strings.HasPrefix(traceParent, "00-0af7651916cd43dd8448eb211c80319c-"+s.SpanID))
+	assert.NoError(t, s.Err)
+
+	_, err = ContextWithTraceParent(context.Background(), "invalid")
+	assert.Error(t, err)
+}
@@ -1,10 +1,13 @@
 module github.com/qiniu/pandora-go-sdk
 
-go 1.12
+go 1.13
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/davecgh/go-spew v1.1.1
 	github.com/google/go-querystring v1.0.0
+	github.com/klauspost/compress v1.11.13
 	github.com/qiniu/x v0.0.0-20190911131702-ec64d9399366
 	github.com/stretchr/testify v1.4.0
+	gopkg.in/yaml.v2 v2.2.2
 )
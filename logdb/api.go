@@ -1,12 +1,17 @@
 package logdb
 
 import (
+	"context"
 	"net/url"
 
 	"github.com/qiniu/pandora-go-sdk/base"
 )
 
 func (c *Logdb) CreateRepo(input *CreateRepoInput) (err error) {
+	return c.CreateRepoWithContext(context.Background(), input)
+}
+
+func (c *Logdb) CreateRepoWithContext(ctx context.Context, input *CreateRepoInput) (err error) {
 	if input.FullText.Enabled {
 		for i, v := range input.Schema {
 			if v.ValueType == TypeString {
@@ -16,7 +21,7 @@ func (c *Logdb) CreateRepo(input *CreateRepoInput) (err error) {
 	}
 	op := c.NewOperation(base.OpCreateRepo, input.RepoName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -25,11 +30,15 @@ func (c *Logdb) CreateRepo(input *CreateRepoInput) (err error) {
 }
 
 func (c *Logdb) CreateRepoFromDSL(input *CreateRepoDSLInput) (err error) {
+	return c.CreateRepoFromDSLWithContext(context.Background(), input)
+}
+
+func (c *Logdb) CreateRepoFromDSLWithContext(ctx context.Context, input *CreateRepoDSLInput) (err error) {
 	schemas, err := toSchema(input.DSL, 0)
 	if err != nil {
 		return
 	}
-	return c.CreateRepo(&CreateRepoInput{
+	return c.CreateRepoWithContext(ctx, &CreateRepoInput{
 		PandoraToken: input.PandoraToken,
 		RepoName:     input.RepoName,
 		Region:       input.Region,
@@ -39,9 +48,13 @@ func (c *Logdb) CreateRepoFromDSL(input *CreateRepoDSLInput) (err error) {
 }
 
 func (c *Logdb) UpdateRepo(input *UpdateRepoInput) (err error) {
+	return c.UpdateRepoWithContext(context.Background(), input)
+}
+
+func (c *Logdb) UpdateRepoWithContext(ctx context.Context, input *UpdateRepoInput) (err error) {
 	op := c.NewOperation(base.OpUpdateRepo, input.RepoName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -50,48 +63,69 @@ func (c *Logdb) UpdateRepo(input *UpdateRepoInput) (err error) {
 }
 
 func (c *Logdb) GetRepo(input *GetRepoInput) (output *GetRepoOutput, err error) {
+	return c.GetRepoWithContext(context.Background(), input)
+}
+
+func (c *Logdb) GetRepoWithContext(ctx context.Context, input *GetRepoInput) (output *GetRepoOutput, err error) {
 	op := c.NewOperation(base.OpGetRepo, input.RepoName)
 
 	output = &GetRepoOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Logdb) ListRepos(input *ListReposInput) (output *ListReposOutput, err error) {
+	return c.ListReposWithContext(context.Background(), input)
+}
+
+func (c *Logdb) ListReposWithContext(ctx context.Context, input *ListReposInput) (output *ListReposOutput, err error) {
 	op := c.NewOperation(base.OpListRepos)
 
 	output = &ListReposOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	return output, req.Send()
 }
 
 func (c *Logdb) DeleteRepo(input *DeleteRepoInput) (err error) {
+	return c.DeleteRepoWithContext(context.Background(), input)
+}
+
+func (c *Logdb) DeleteRepoWithContext(ctx context.Context, input *DeleteRepoInput) (err error) {
 	op := c.NewOperation(base.OpDeleteRepo, input.RepoName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Logdb) SendLog(input *SendLogInput) (output *SendLogOutput, err error) {
+	return c.SendLogWithContext(context.Background(), input)
+}
+
+func (c *Logdb) SendLogWithContext(ctx context.Context, input *SendLogInput) (output *SendLogOutput, err error) {
 	op := c.NewOperation(base.OpSendLog, input.RepoName, input.OmitInvalidLog)
 
 	output = &SendLogOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	buf, err := input.Logs.Buf()
 	if err != nil {
 		return
 	}
 	req.SetBufferBody(buf)
+	req.SetCompressible()
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return output, req.Send()
 }
 
 // 输入JSON样例数据，输出对应的LOGDB Schema
 func (c *Logdb) GetSampleDataSchema(input *SchemaRefInput) (output *SchemaRefOut, err error) {
+	return c.GetSampleDataSchemaWithContext(context.Background(), input)
+}
+
+func (c *Logdb) GetSampleDataSchemaWithContext(ctx context.Context, input *SchemaRefInput) (output *SchemaRefOut, err error) {
 	op := c.NewOperation(base.OpSchemaRef)
 
 	output = &SchemaRefOut{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 
 	data, err := input.Buf()
 	if err != nil {
@@ -104,6 +138,10 @@ func (c *Logdb) GetSampleDataSchema(input *SchemaRefInput) (output *SchemaRefOut
 }
 
 func (c *Logdb) QueryLog(input *QueryLogInput) (output *QueryLogOutput, err error) {
+	return c.QueryLogWithContext(context.Background(), input)
+}
+
+func (c *Logdb) QueryLogWithContext(ctx context.Context, input *QueryLogInput) (output *QueryLogOutput, err error) {
 	var highlight bool
 	if input.Highlight != nil {
 		highlight = true
@@ -111,7 +149,7 @@ func (c *Logdb) QueryLog(input *QueryLogInput) (output *QueryLogOutput, err erro
 	op := c.NewOperation(base.OpQueryLog, input.RepoName, url.QueryEscape(input.Query), input.Sort, input.From, input.Size, input.Scroll, highlight)
 
 	output = &QueryLogOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	if input.Highlight != nil {
 		if err = req.SetVariantBody(input.Highlight); err != nil {
 			return
@@ -122,9 +160,13 @@ func (c *Logdb) QueryLog(input *QueryLogInput) (output *QueryLogOutput, err erro
 }
 
 func (c *Logdb) QueryScroll(input *QueryScrollInput) (output *QueryLogOutput, err error) {
+	return c.QueryScrollWithContext(context.Background(), input)
+}
+
+func (c *Logdb) QueryScrollWithContext(ctx context.Context, input *QueryScrollInput) (output *QueryLogOutput, err error) {
 	op := c.NewOperation(base.OpQueryScroll, input.RepoName)
 	output = &QueryLogOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	buf, err := input.Buf()
 	if err != nil {
 		return
@@ -135,17 +177,25 @@ func (c *Logdb) QueryScroll(input *QueryScrollInput) (output *QueryLogOutput, er
 }
 
 func (c *Logdb) QueryHistogramLog(input *QueryHistogramLogInput) (output *QueryHistogramLogOutput, err error) {
+	return c.QueryHistogramLogWithContext(context.Background(), input)
+}
+
+func (c *Logdb) QueryHistogramLogWithContext(ctx context.Context, input *QueryHistogramLogInput) (output *QueryHistogramLogOutput, err error) {
 	op := c.NewOperation(base.OpQueryHistogramLog, input.RepoName, url.QueryEscape(input.Query), input.From, input.To, input.Field)
 
 	output = &QueryHistogramLogOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Logdb) PutRepoConfig(input *PutRepoConfigInput) (err error) {
+	return c.PutRepoConfigWithContext(context.Background(), input)
+}
+
+func (c *Logdb) PutRepoConfigWithContext(ctx context.Context, input *PutRepoConfigInput) (err error) {
 	op := c.NewOperation(base.OpPutRepoConfig, input.RepoName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -154,21 +204,33 @@ func (c *Logdb) PutRepoConfig(input *PutRepoConfigInput) (err error) {
 }
 
 func (c *Logdb) GetRepoConfig(input *GetRepoConfigInput) (output *GetRepoConfigOutput, err error) {
+	return c.GetRepoConfigWithContext(context.Background(), input)
+}
+
+func (c *Logdb) GetRepoConfigWithContext(ctx context.Context, input *GetRepoConfigInput) (output *GetRepoConfigOutput, err error) {
 	op := c.NewOperation(base.OpGetRepoConfig, input.RepoName)
 
 	output = &GetRepoConfigOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Logdb) MakeToken(desc *base.TokenDesc) (string, error) {
-	return base.MakeTokenInternal(c.Config.Ak, c.Config.Sk, desc)
+	creds, err := c.Config.GetCredentials()
+	if err != nil {
+		return "", err
+	}
+	return base.MakeTokenInternal(creds.Ak, creds.Sk, desc)
 }
 
 func (c *Logdb) PartialQuery(input *PartialQueryInput) (output *PartialQueryOutput, err error) {
+	return c.PartialQueryWithContext(context.Background(), input)
+}
+
+func (c *Logdb) PartialQueryWithContext(ctx context.Context, input *PartialQueryInput) (output *PartialQueryOutput, err error) {
 	op := c.NewOperation(base.OpPartialQuery, input.RepoName)
 	output = &PartialQueryOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	buf, err := input.Buf()
 	if err != nil {
 		return
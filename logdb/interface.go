@@ -1,35 +1,62 @@
 package logdb
 
 import (
+	"context"
 	"github.com/qiniu/pandora-go-sdk/base"
 )
 
 type LogdbAPI interface {
 	CreateRepo(*CreateRepoInput) error
 
+	CreateRepoWithContext(context.Context, *CreateRepoInput) error
+
 	GetRepo(*GetRepoInput) (*GetRepoOutput, error)
 
+	GetRepoWithContext(context.Context, *GetRepoInput) (*GetRepoOutput, error)
+
 	ListRepos(*ListReposInput) (*ListReposOutput, error)
 
+	ListReposWithContext(context.Context, *ListReposInput) (*ListReposOutput, error)
+
 	DeleteRepo(*DeleteRepoInput) error
 
+	DeleteRepoWithContext(context.Context, *DeleteRepoInput) error
+
 	UpdateRepo(*UpdateRepoInput) error
 
+	UpdateRepoWithContext(context.Context, *UpdateRepoInput) error
+
 	SendLog(*SendLogInput) (*SendLogOutput, error)
 
+	SendLogWithContext(context.Context, *SendLogInput) (*SendLogOutput, error)
+
 	QueryLog(*QueryLogInput) (*QueryLogOutput, error)
 
+	QueryLogWithContext(context.Context, *QueryLogInput) (*QueryLogOutput, error)
+
 	QueryScroll(*QueryScrollInput) (*QueryLogOutput, error)
 
+	QueryScrollWithContext(context.Context, *QueryScrollInput) (*QueryLogOutput, error)
+
 	QueryHistogramLog(*QueryHistogramLogInput) (*QueryHistogramLogOutput, error)
 
+	QueryHistogramLogWithContext(context.Context, *QueryHistogramLogInput) (*QueryHistogramLogOutput, error)
+
 	PutRepoConfig(*PutRepoConfigInput) error
 
+	PutRepoConfigWithContext(context.Context, *PutRepoConfigInput) error
+
 	GetRepoConfig(*GetRepoConfigInput) (*GetRepoConfigOutput, error)
 
+	GetRepoConfigWithContext(context.Context, *GetRepoConfigInput) (*GetRepoConfigOutput, error)
+
 	MakeToken(*base.TokenDesc) (string, error)
 
 	PartialQuery(input *PartialQueryInput) (output *PartialQueryOutput, err error)
 
+	PartialQueryWithContext(ctx context.Context, input *PartialQueryInput) (output *PartialQueryOutput, err error)
+
 	GetSampleDataSchema(input *SchemaRefInput) (output *SchemaRefOut, err error)
+
+	GetSampleDataSchemaWithContext(ctx context.Context, input *SchemaRefInput) (output *SchemaRefOut, err error)
 }
@@ -10,6 +10,7 @@ import (
 
 	"github.com/qiniu/pandora-go-sdk/base"
 	. "github.com/qiniu/pandora-go-sdk/base/models"
+	"github.com/qiniu/pandora-go-sdk/base/record"
 	"github.com/qiniu/pandora-go-sdk/base/reqerr"
 )
 
@@ -634,6 +635,35 @@ type QueryLogOutput struct {
 	Total          int                      `json:"total"`
 	PartialSuccess bool                     `json:"partialSuccess"`
 	Data           []map[string]interface{} `json:"data"`
+
+	raw json.RawMessage
+}
+
+type queryLogOutput QueryLogOutput
+
+// UnmarshalJSON 按默认规则解析 Data，同时保留其原始数据，Decode 时 long 类型的数字不会经过 float64 转换而损失精度
+func (o *QueryLogOutput) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		*queryLogOutput
+		Data json.RawMessage `json:"data"`
+	}{queryLogOutput: (*queryLogOutput)(o)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	o.raw = aux.Data
+	o.Data = nil
+	if len(aux.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(aux.Data, &o.Data)
+}
+
+// Decode 把查询结果解码到 v 指向的结构体 slice，结构体字段使用 pandora 标签指定名称，见 record.Decode
+func (o *QueryLogOutput) Decode(v interface{}) error {
+	if len(o.raw) > 0 {
+		return record.Unmarshal(o.raw, v)
+	}
+	return record.DecodeSlice(o.Data, v)
 }
 
 type QueryHistogramLogInput struct {
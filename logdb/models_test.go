@@ -1,7 +1,9 @@
 package logdb
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -234,3 +236,20 @@ func TestCheckRetention(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestQueryLogOutputDecode(t *testing.T) {
+	type hit struct {
+		ID   int64     `pandora:"id"`
+		Time time.Time `pandora:"timestamp"`
+	}
+	var output QueryLogOutput
+	assert.NoError(t, json.Unmarshal([]byte(`{"total":1,"data":[{"id":9007199254740993,"timestamp":"2026-01-02T03:04:05Z"}]}`), &output))
+	assert.Equal(t, 1, output.Total)
+	assert.Len(t, output.Data, 1)
+	// Data 的解析方式不变，数字仍然是 float64
+	assert.Equal(t, float64(9007199254740993), output.Data[0]["id"])
+
+	var hits []hit
+	assert.NoError(t, output.Decode(&hits))
+	assert.Equal(t, []hit{{ID: 9007199254740993, Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}}, hits)
+}
@@ -1,6 +1,7 @@
 package logkit
 
 import (
+	"context"
 	"encoding/json"
 	"strings"
 
@@ -93,6 +94,10 @@ type GetAgentsOptions struct {
 
 // GetAgents 返回符合条件的 agents 信息以及可获取的总数
 func (l *Logkit) GetAgents(opts *GetAgentsOptions) ([]*Agent, int, error) {
+	return l.GetAgentsWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) GetAgentsWithContext(ctx context.Context, opts *GetAgentsOptions) ([]*Agent, int, error) {
 	vals, err := query.Values(opts)
 	if err != nil {
 		return nil, 0, err
@@ -102,7 +107,7 @@ func (l *Logkit) GetAgents(opts *GetAgentsOptions) ([]*Agent, int, error) {
 		Agents    []*Agent `json:"agentList"`
 		TotalSize int      `json:"totalSize"`
 	}{}
-	return resp.Agents, resp.TotalSize, l.newRequest(op, opts.Token, resp).Send()
+	return resp.Agents, resp.TotalSize, l.newRequest(ctx, op, opts.Token, resp).Send()
 }
 
 type DeleteAgentsOptions struct {
@@ -115,12 +120,16 @@ type DeleteAgentsOptions struct {
 
 // DeleteAgents 删除符合条件的 agents
 func (l *Logkit) DeleteAgents(opts *DeleteAgentsOptions) error {
+	return l.DeleteAgentsWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) DeleteAgentsWithContext(ctx context.Context, opts *DeleteAgentsOptions) error {
 	vals, err := query.Values(opts)
 	if err != nil {
 		return err
 	}
 	op := newOperation(opDeleteAgents, vals.Encode())
-	return l.newRequest(op, opts.Token, nil).Send()
+	return l.newRequest(ctx, op, opts.Token, nil).Send()
 }
 
 type BatchDeleteAgentsOptions struct {
@@ -131,8 +140,12 @@ type BatchDeleteAgentsOptions struct {
 
 // BatchDeleteAgents 根据 ID 删除对应的 agents
 func (l *Logkit) BatchDeleteAgents(opts *BatchDeleteAgentsOptions) error {
+	return l.BatchDeleteAgentsWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) BatchDeleteAgentsWithContext(ctx context.Context, opts *BatchDeleteAgentsOptions) error {
 	op := newOperation(opBatchDeleteAgents, strings.Join(opts.IDs, ","))
-	return l.newRequest(op, opts.Token, nil).Send()
+	return l.newRequest(ctx, op, opts.Token, nil).Send()
 }
 
 type AssignAgentTagOptions struct {
@@ -144,8 +157,12 @@ type AssignAgentTagOptions struct {
 
 // AssignAgentTag 分配指定 tag 给 agent
 func (l *Logkit) AssignAgentTag(opts *AssignAgentTagOptions) error {
+	return l.AssignAgentTagWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) AssignAgentTagWithContext(ctx context.Context, opts *AssignAgentTagOptions) error {
 	op := newOperation(opAssignAgentTag, opts.AgentID, opts.Tag)
-	return l.newRequest(op, opts.Token, nil).Send()
+	return l.newRequest(ctx, op, opts.Token, nil).Send()
 }
 
 type AssignAgentTagsOptions struct {
@@ -157,13 +174,17 @@ type AssignAgentTagsOptions struct {
 
 // AssignAgentTags 分配指定 tags 给 agent
 func (l *Logkit) AssignAgentTags(opts *AssignAgentTagsOptions) error {
+	return l.AssignAgentTagsWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) AssignAgentTagsWithContext(ctx context.Context, opts *AssignAgentTagsOptions) error {
 	op := newOperation(opAssignAgentTags, opts.AgentID)
 	data, err := json.Marshal(opts)
 	if err != nil {
 		return err
 	}
 
-	req := l.newRequest(op, opts.Token, nil)
+	req := l.newRequest(ctx, op, opts.Token, nil)
 	req.SetBufferBody(data)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return req.Send()
@@ -171,8 +192,12 @@ func (l *Logkit) AssignAgentTags(opts *AssignAgentTagsOptions) error {
 
 // UnassignAgentTag 从 agent 删除指定 tag
 func (l *Logkit) UnassignAgentTag(opts *AssignAgentTagOptions) error {
+	return l.UnassignAgentTagWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) UnassignAgentTagWithContext(ctx context.Context, opts *AssignAgentTagOptions) error {
 	op := newOperation(opDeleteTagFromAgent, opts.AgentID, opts.Tag)
-	return l.newRequest(op, opts.Token, nil).Send()
+	return l.newRequest(ctx, op, opts.Token, nil).Send()
 }
 
 type MatchAgentsOptions struct {
@@ -199,13 +224,21 @@ type GetAgentReleasesOptions struct {
 
 // GetAgentReleases 返回 agent 版本信息
 func (l *Logkit) GetAgentReleases(opts *GetAgentReleasesOptions) ([]*AgentRelease, error) {
+	return l.GetAgentReleasesWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) GetAgentReleasesWithContext(ctx context.Context, opts *GetAgentReleasesOptions) ([]*AgentRelease, error) {
 	op := newOperation(opGetAgentReleases)
 	var releases []*AgentRelease
-	return releases, l.newRequest(op, opts.Token, &releases).Send()
+	return releases, l.newRequest(ctx, op, opts.Token, &releases).Send()
 }
 
 // UpgradeAgents 升级符合条件的 agents
 func (l *Logkit) UpgradeAgents(opts *MatchAgentsOptions) (string, error) {
+	return l.UpgradeAgentsWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) UpgradeAgentsWithContext(ctx context.Context, opts *MatchAgentsOptions) (string, error) {
 	op := newOperation(opUpgradeAgents)
 	data, err := json.Marshal(opts)
 	if err != nil {
@@ -213,7 +246,7 @@ func (l *Logkit) UpgradeAgents(opts *MatchAgentsOptions) (string, error) {
 	}
 
 	var jobID string
-	req := l.newRequest(op, opts.Token, &jobID)
+	req := l.newRequest(ctx, op, opts.Token, &jobID)
 	req.SetBufferBody(data)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return jobID, req.Send()
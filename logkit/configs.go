@@ -1,6 +1,7 @@
 package logkit
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 
@@ -33,6 +34,10 @@ type GetConfigsOptions struct {
 
 // GetConfigs 返回符合条件的 configs 信息以及可获取的总数
 func (l *Logkit) GetConfigs(opts *GetConfigsOptions) ([]*Config, int, error) {
+	return l.GetConfigsWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) GetConfigsWithContext(ctx context.Context, opts *GetConfigsOptions) ([]*Config, int, error) {
 	vals, err := query.Values(opts)
 	if err != nil {
 		return nil, 0, err
@@ -42,7 +47,7 @@ func (l *Logkit) GetConfigs(opts *GetConfigsOptions) ([]*Config, int, error) {
 		Configs   []*Config `json:"configs"`
 		TotalSize int       `json:"totalSize"`
 	}{}
-	return resp.Configs, resp.TotalSize, l.newRequest(op, opts.Token, resp).Send()
+	return resp.Configs, resp.TotalSize, l.newRequest(ctx, op, opts.Token, resp).Send()
 }
 
 type NewConfigOptions struct {
@@ -53,6 +58,10 @@ type NewConfigOptions struct {
 
 // NewConfig 添加新的 config
 func (l *Logkit) NewConfig(opts *NewConfigOptions) error {
+	return l.NewConfigWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) NewConfigWithContext(ctx context.Context, opts *NewConfigOptions) error {
 	if opts.Config == nil {
 		return errors.New("field 'Config' is nil")
 	}
@@ -63,7 +72,7 @@ func (l *Logkit) NewConfig(opts *NewConfigOptions) error {
 		return err
 	}
 
-	req := l.newRequest(op, opts.Token, nil)
+	req := l.newRequest(ctx, op, opts.Token, nil)
 	req.SetBufferBody(data)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return req.Send()
@@ -79,6 +88,10 @@ type UpdateConfigOptions struct {
 
 // UpdateConfig 更新指定名称的 config
 func (l *Logkit) UpdateConfig(opts *UpdateConfigOptions) error {
+	return l.UpdateConfigWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) UpdateConfigWithContext(ctx context.Context, opts *UpdateConfigOptions) error {
 	if opts.Config == nil {
 		return errors.New("field 'Config' is nil")
 	}
@@ -89,7 +102,7 @@ func (l *Logkit) UpdateConfig(opts *UpdateConfigOptions) error {
 		return err
 	}
 
-	req := l.newRequest(op, opts.Token, nil)
+	req := l.newRequest(ctx, op, opts.Token, nil)
 	req.SetBufferBody(data)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return req.Send()
@@ -103,13 +116,17 @@ type DeleteConfigsOptions struct {
 
 // DeleteConfigs 删除指定名称的 configs
 func (l *Logkit) DeleteConfigs(opts *DeleteConfigsOptions) error {
+	return l.DeleteConfigsWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) DeleteConfigsWithContext(ctx context.Context, opts *DeleteConfigsOptions) error {
 	op := newOperation(opDeleteConfigs)
 	data, err := json.Marshal(opts.Names)
 	if err != nil {
 		return err
 	}
 
-	req := l.newRequest(op, opts.Token, nil)
+	req := l.newRequest(ctx, op, opts.Token, nil)
 	req.SetBufferBody(data)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return req.Send()
@@ -123,8 +140,12 @@ type DeleteConfigOptions struct {
 
 // DeleteConfig 删除指定名称的 config
 func (l *Logkit) DeleteConfig(opts *DeleteConfigOptions) error {
+	return l.DeleteConfigWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) DeleteConfigWithContext(ctx context.Context, opts *DeleteConfigOptions) error {
 	op := newOperation(opDeleteConfig, opts.Name)
-	return l.newRequest(op, opts.Token, nil).Send()
+	return l.newRequest(ctx, op, opts.Token, nil).Send()
 }
 
 type AssignConfigTagsOptions struct {
@@ -136,13 +157,17 @@ type AssignConfigTagsOptions struct {
 
 // AssignConfigTags 分配指定 tags 给 config
 func (l *Logkit) AssignConfigTags(opts *AssignConfigTagsOptions) error {
+	return l.AssignConfigTagsWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) AssignConfigTagsWithContext(ctx context.Context, opts *AssignConfigTagsOptions) error {
 	op := newOperation(opAssignConfigTags, opts.Name)
 	data, err := json.Marshal(opts)
 	if err != nil {
 		return err
 	}
 
-	req := l.newRequest(op, opts.Token, nil)
+	req := l.newRequest(ctx, op, opts.Token, nil)
 	req.SetBufferBody(data)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return req.Send()
@@ -157,13 +182,17 @@ type AssignConfigAgentsOptions struct {
 
 // AssignConfigAgents 分配指定 config 到 agents
 func (l *Logkit) AssignConfigAgents(opts *AssignConfigAgentsOptions) error {
+	return l.AssignConfigAgentsWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) AssignConfigAgentsWithContext(ctx context.Context, opts *AssignConfigAgentsOptions) error {
 	op := newOperation(opAssignConfigAgents, opts.Name)
 	data, err := json.Marshal(opts)
 	if err != nil {
 		return err
 	}
 
-	req := l.newRequest(op, opts.Token, nil)
+	req := l.newRequest(ctx, op, opts.Token, nil)
 	req.SetBufferBody(data)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return req.Send()
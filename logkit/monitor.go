@@ -1,6 +1,7 @@
 package logkit
 
 import (
+	"context"
 	"github.com/google/go-querystring/query"
 
 	. "github.com/qiniu/pandora-go-sdk/base/models"
@@ -18,9 +19,13 @@ type GetMetricsInfoOptions struct {
 
 // GetMetricsInfo 返回当前 metrics 的设定信息
 func (l *Logkit) GetMetricsInfo(opts *GetMetricsInfoOptions) (*MetricsInfo, error) {
+	return l.GetMetricsInfoWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) GetMetricsInfoWithContext(ctx context.Context, opts *GetMetricsInfoOptions) (*MetricsInfo, error) {
 	op := newOperation(opGetMetricsInfo)
 	resp := &MetricsInfo{}
-	return resp, l.newRequest(op, opts.Token, resp).Send()
+	return resp, l.newRequest(ctx, op, opts.Token, resp).Send()
 }
 
 type AgentMetrics struct {
@@ -40,11 +45,15 @@ type GetAgentMetricsOptions struct {
 
 // GetAgentMetrics 返回指定 agent 的具体 metrics 数据
 func (l *Logkit) GetAgentMetrics(opts *GetAgentMetricsOptions) (*AgentMetrics, error) {
+	return l.GetAgentMetricsWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) GetAgentMetricsWithContext(ctx context.Context, opts *GetAgentMetricsOptions) (*AgentMetrics, error) {
 	vals, err := query.Values(opts)
 	if err != nil {
 		return nil, err
 	}
 	op := newOperation(opGetAgentMetrics, opts.AgentID, vals.Encode())
 	resp := &AgentMetrics{}
-	return resp, l.newRequest(op, opts.Token, resp).Send()
+	return resp, l.newRequest(ctx, op, opts.Token, resp).Send()
 }
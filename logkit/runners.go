@@ -1,6 +1,7 @@
 package logkit
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 
@@ -41,6 +42,10 @@ type GetRunnersOptions struct {
 
 // GetRunners 返回符合条件的 runners、agents（可选）信息以及可获取的总数
 func (l *Logkit) GetRunners(opts *GetRunnersOptions) ([]*Runner, map[string]interface{}, int, error) {
+	return l.GetRunnersWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) GetRunnersWithContext(ctx context.Context, opts *GetRunnersOptions) ([]*Runner, map[string]interface{}, int, error) {
 	vals, err := query.Values(opts)
 	if err != nil {
 		return nil, nil, 0, err
@@ -51,7 +56,7 @@ func (l *Logkit) GetRunners(opts *GetRunnersOptions) ([]*Runner, map[string]inte
 		Agents    map[string]interface{} `json:"agents"`
 		TotalSize int                    `json:"totalSize"`
 	}{}
-	return resp.Runners, resp.Agents, resp.TotalSize, l.newRequest(op, opts.Token, resp).Send()
+	return resp.Runners, resp.Agents, resp.TotalSize, l.newRequest(ctx, op, opts.Token, resp).Send()
 }
 
 type RunnerCond struct {
@@ -67,13 +72,17 @@ type BatchRunnersOptions struct {
 
 // StartRunners 启动指定 runners
 func (l *Logkit) StartRunners(opts *BatchRunnersOptions) error {
+	return l.StartRunnersWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) StartRunnersWithContext(ctx context.Context, opts *BatchRunnersOptions) error {
 	op := newOperation(opStartRunners)
 	data, err := json.Marshal(opts.RunnerConds)
 	if err != nil {
 		return err
 	}
 
-	req := l.newRequest(op, opts.Token, nil)
+	req := l.newRequest(ctx, op, opts.Token, nil)
 	req.SetBufferBody(data)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return req.Send()
@@ -81,13 +90,17 @@ func (l *Logkit) StartRunners(opts *BatchRunnersOptions) error {
 
 // StopRunners 停止指定 runners
 func (l *Logkit) StopRunners(opts *BatchRunnersOptions) error {
+	return l.StopRunnersWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) StopRunnersWithContext(ctx context.Context, opts *BatchRunnersOptions) error {
 	op := newOperation(opStopRunners)
 	data, err := json.Marshal(opts.RunnerConds)
 	if err != nil {
 		return err
 	}
 
-	req := l.newRequest(op, opts.Token, nil)
+	req := l.newRequest(ctx, op, opts.Token, nil)
 	req.SetBufferBody(data)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return req.Send()
@@ -95,13 +108,17 @@ func (l *Logkit) StopRunners(opts *BatchRunnersOptions) error {
 
 // ResetRunners 重置指定 runners
 func (l *Logkit) ResetRunners(opts *BatchRunnersOptions) error {
+	return l.ResetRunnersWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) ResetRunnersWithContext(ctx context.Context, opts *BatchRunnersOptions) error {
 	op := newOperation(opResetRunners)
 	data, err := json.Marshal(opts.RunnerConds)
 	if err != nil {
 		return err
 	}
 
-	req := l.newRequest(op, opts.Token, nil)
+	req := l.newRequest(ctx, op, opts.Token, nil)
 	req.SetBufferBody(data)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return req.Send()
@@ -109,13 +126,17 @@ func (l *Logkit) ResetRunners(opts *BatchRunnersOptions) error {
 
 // DeleteRunners 删除指定 runners
 func (l *Logkit) DeleteRunners(opts *BatchRunnersOptions) error {
+	return l.DeleteRunnersWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) DeleteRunnersWithContext(ctx context.Context, opts *BatchRunnersOptions) error {
 	op := newOperation(opDeleteRunners)
 	data, err := json.Marshal(opts.RunnerConds)
 	if err != nil {
 		return err
 	}
 
-	req := l.newRequest(op, opts.Token, nil)
+	req := l.newRequest(ctx, op, opts.Token, nil)
 	req.SetBufferBody(data)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return req.Send()
@@ -135,6 +156,10 @@ type GrokCheckOptions struct {
 
 // GrokCheck 用于测试 Gork 模式
 func (l *Logkit) GrokCheck(opts *GrokCheckOptions) (map[string]interface{}, error) {
+	return l.GrokCheckWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) GrokCheckWithContext(ctx context.Context, opts *GrokCheckOptions) (map[string]interface{}, error) {
 	if opts.GrokData == nil {
 		return nil, errors.New("field 'GrokData' is nil")
 	}
@@ -146,7 +171,7 @@ func (l *Logkit) GrokCheck(opts *GrokCheckOptions) (map[string]interface{}, erro
 	}
 
 	var resp map[string]interface{}
-	req := l.newRequest(op, opts.Token, resp)
+	req := l.newRequest(ctx, op, opts.Token, resp)
 	req.SetBufferBody(data)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return resp, req.Send()
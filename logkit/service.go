@@ -1,10 +1,8 @@
 package logkit
 
 import (
-	"crypto/tls"
-	"net"
+	"context"
 	"net/http"
-	"time"
 
 	"github.com/qiniu/pandora-go-sdk/base"
 	"github.com/qiniu/pandora-go-sdk/base/config"
@@ -33,23 +31,12 @@ func New(c *config.Config) (*Logkit, error) {
 	}
 	return &Logkit{
 		config: c,
-		client: &http.Client{
-			Transport: &http.Transport{
-				DialContext: (&net.Dialer{
-					Timeout:   c.DialTimeout,
-					KeepAlive: 30 * time.Second,
-				}).DialContext,
-				ResponseHeaderTimeout: c.ResponseTimeout,
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: c.AllowInsecureServer,
-				},
-			},
-		},
+		client: request.NewHTTPClient(c),
 	}, nil
 }
 
-func (l *Logkit) newRequest(op *request.Operation, token string, v interface{}) *request.Request {
-	req := request.New(l.config, l.client, op, token, builder, v)
+func (l *Logkit) newRequest(ctx context.Context, op *request.Operation, token string, v interface{}) *request.Request {
+	req := request.NewWithContext(ctx, l.config, l.client, op, token, builder, v)
 	req.Data = v
 	return req
 }
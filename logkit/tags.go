@@ -1,6 +1,7 @@
 package logkit
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"strings"
@@ -40,13 +41,17 @@ type TagList struct {
 
 // GetTags 返回符合条件的 tags、agents（可选）信息以及可获取的总数
 func (l *Logkit) GetTags(opts *GetTagsOptions) ([]*Tag, map[string]interface{}, int, error) {
+	return l.GetTagsWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) GetTagsWithContext(ctx context.Context, opts *GetTagsOptions) ([]*Tag, map[string]interface{}, int, error) {
 	vals, err := query.Values(opts)
 	if err != nil {
 		return nil, nil, 0, err
 	}
 	op := newOperation(opGetTags, vals.Encode())
 	resp := new(TagList)
-	return resp.Tags, resp.Agents, resp.TotalSize, l.newRequest(op, opts.Token, resp).Send()
+	return resp.Tags, resp.Agents, resp.TotalSize, l.newRequest(ctx, op, opts.Token, resp).Send()
 }
 
 type NewTagOptions struct {
@@ -57,6 +62,10 @@ type NewTagOptions struct {
 
 // NewTag 添加新的 tag
 func (l *Logkit) NewTag(opts *NewTagOptions) error {
+	return l.NewTagWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) NewTagWithContext(ctx context.Context, opts *NewTagOptions) error {
 	if opts.Tag == nil {
 		return errors.New("field 'Tag' is nil")
 	}
@@ -67,7 +76,7 @@ func (l *Logkit) NewTag(opts *NewTagOptions) error {
 		return err
 	}
 
-	req := l.newRequest(op, opts.Token, nil)
+	req := l.newRequest(ctx, op, opts.Token, nil)
 	req.SetBufferBody(data)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return req.Send()
@@ -82,13 +91,17 @@ type UpdateTagNoteOptions struct {
 
 // UpdateTagNote 更新 tag 的 note
 func (l *Logkit) UpdateTagNote(opts *UpdateTagNoteOptions) error {
+	return l.UpdateTagNoteWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) UpdateTagNoteWithContext(ctx context.Context, opts *UpdateTagNoteOptions) error {
 	op := newOperation(opUpdateTagNote, opts.Name)
 	data, err := json.Marshal(opts)
 	if err != nil {
 		return err
 	}
 
-	req := l.newRequest(op, opts.Token, nil)
+	req := l.newRequest(ctx, op, opts.Token, nil)
 	req.SetBufferBody(data)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return req.Send()
@@ -103,13 +116,17 @@ type AssignTagAgentsOptions struct {
 
 // AssignTagAgents 分配 tag 给指定的 agents
 func (l *Logkit) AssignTagAgents(opts *AssignTagAgentsOptions) error {
+	return l.AssignTagAgentsWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) AssignTagAgentsWithContext(ctx context.Context, opts *AssignTagAgentsOptions) error {
 	op := newOperation(opAssignTagAgents, opts.TagName)
 	data, err := json.Marshal(opts)
 	if err != nil {
 		return err
 	}
 
-	req := l.newRequest(op, opts.Token, nil)
+	req := l.newRequest(ctx, op, opts.Token, nil)
 	req.SetBufferBody(data)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return req.Send()
@@ -124,8 +141,12 @@ type UnassignTagAgentsOptions struct {
 
 // UnassignTagAgents 取消分配到指定 agents 的 tag
 func (l *Logkit) UnassignTagAgents(opts *UnassignTagAgentsOptions) error {
+	return l.UnassignTagAgentsWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) UnassignTagAgentsWithContext(ctx context.Context, opts *UnassignTagAgentsOptions) error {
 	op := newOperation(opUnassignTagAgents, opts.TagName, strings.Join(opts.AgentIDs, ","))
-	return l.newRequest(op, opts.Token, nil).Send()
+	return l.newRequest(ctx, op, opts.Token, nil).Send()
 }
 
 type UnassignTagConfigOptions struct {
@@ -137,8 +158,12 @@ type UnassignTagConfigOptions struct {
 
 // UnassignTagConfig 删除为 tags 分发的 configs
 func (l *Logkit) UnassignTagConfig(opts *UnassignTagConfigOptions) error {
+	return l.UnassignTagConfigWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) UnassignTagConfigWithContext(ctx context.Context, opts *UnassignTagConfigOptions) error {
 	op := newOperation(opUnassignTagConfig, opts.TagName, opts.ConfigName)
-	return l.newRequest(op, opts.Token, nil).Send()
+	return l.newRequest(ctx, op, opts.Token, nil).Send()
 }
 
 type DeleteTagOptions struct {
@@ -149,8 +174,12 @@ type DeleteTagOptions struct {
 
 // DeleteTag 删除指定的 tag
 func (l *Logkit) DeleteTag(opts *DeleteTagOptions) error {
+	return l.DeleteTagWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) DeleteTagWithContext(ctx context.Context, opts *DeleteTagOptions) error {
 	op := newOperation(opDeleteTag, opts.Name)
-	return l.newRequest(op, opts.Token, nil).Send()
+	return l.newRequest(ctx, op, opts.Token, nil).Send()
 }
 
 type DeleteTagsOptions struct {
@@ -161,6 +190,10 @@ type DeleteTagsOptions struct {
 
 // DeleteTags 删除指定的 tags
 func (l *Logkit) DeleteTags(opts *DeleteTagsOptions) error {
+	return l.DeleteTagsWithContext(context.Background(), opts)
+}
+
+func (l *Logkit) DeleteTagsWithContext(ctx context.Context, opts *DeleteTagsOptions) error {
 	op := newOperation(opDeleteTags, strings.Join(opts.Tags, ","))
-	return l.newRequest(op, opts.Token, nil).Send()
+	return l.newRequest(ctx, op, opts.Token, nil).Send()
 }
@@ -30,9 +30,13 @@ const (
 )
 
 func (c *Pipeline) CreateGroup(input *CreateGroupInput) (err error) {
+	return c.CreateGroupWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) CreateGroupWithContext(ctx context.Context, input *CreateGroupInput) (err error) {
 	op := c.NewOperation(base.OpCreateGroup, input.GroupName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -41,9 +45,13 @@ func (c *Pipeline) CreateGroup(input *CreateGroupInput) (err error) {
 }
 
 func (c *Pipeline) UpdateGroup(input *UpdateGroupInput) (err error) {
+	return c.UpdateGroupWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) UpdateGroupWithContext(ctx context.Context, input *UpdateGroupInput) (err error) {
 	op := c.NewOperation(base.OpUpdateGroup, input.GroupName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -52,45 +60,69 @@ func (c *Pipeline) UpdateGroup(input *UpdateGroupInput) (err error) {
 }
 
 func (c *Pipeline) StartGroupTask(input *StartGroupTaskInput) (err error) {
+	return c.StartGroupTaskWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) StartGroupTaskWithContext(ctx context.Context, input *StartGroupTaskInput) (err error) {
 	op := c.NewOperation(base.OpStartGroupTask, input.GroupName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Pipeline) StopGroupTask(input *StopGroupTaskInput) (err error) {
+	return c.StopGroupTaskWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) StopGroupTaskWithContext(ctx context.Context, input *StopGroupTaskInput) (err error) {
 	op := c.NewOperation(base.OpStopGroupTask, input.GroupName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Pipeline) ListGroups(input *ListGroupsInput) (output *ListGroupsOutput, err error) {
+	return c.ListGroupsWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) ListGroupsWithContext(ctx context.Context, input *ListGroupsInput) (output *ListGroupsOutput, err error) {
 	op := c.NewOperation(base.OpListGroups)
 
 	output = &ListGroupsOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) GetGroup(input *GetGroupInput) (output *GetGroupOutput, err error) {
+	return c.GetGroupWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) GetGroupWithContext(ctx context.Context, input *GetGroupInput) (output *GetGroupOutput, err error) {
 	op := c.NewOperation(base.OpGetGroup, input.GroupName)
 
 	output = &GetGroupOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) DeleteGroup(input *DeleteGroupInput) (err error) {
+	return c.DeleteGroupWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) DeleteGroupWithContext(ctx context.Context, input *DeleteGroupInput) (err error) {
 	op := c.NewOperation(base.OpDeleteGroup, input.GroupName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Pipeline) CreateRepo(input *CreateRepoInput) (err error) {
+	return c.CreateRepoWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) CreateRepoWithContext(ctx context.Context, input *CreateRepoInput) (err error) {
 	op := c.NewOperation(base.OpCreateRepo, input.RepoName)
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if input.Region == "" {
 		input.Region = c.defaultRegion
 	}
@@ -98,15 +130,24 @@ func (c *Pipeline) CreateRepo(input *CreateRepoInput) (err error) {
 		return
 	}
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
-	return req.Send()
+	req.SetRegion(input.Region)
+	if err = req.Send(); err != nil {
+		return
+	}
+	c.SetRepoRegion(input.RepoName, input.Region)
+	return
 }
 
 func (c *Pipeline) CreateRepoFromDSL(input *CreateRepoDSLInput) (err error) {
+	return c.CreateRepoFromDSLWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) CreateRepoFromDSLWithContext(ctx context.Context, input *CreateRepoDSLInput) (err error) {
 	schemas, err := toSchema(input.DSL, 0)
 	if err != nil {
 		return
 	}
-	return c.CreateRepo(&CreateRepoInput{
+	return c.CreateRepoWithContext(ctx, &CreateRepoInput{
 		PandoraToken: input.PandoraToken,
 		RepoName:     input.RepoName,
 		Region:       input.Region,
@@ -119,6 +160,10 @@ func (c *Pipeline) CreateRepoFromDSL(input *CreateRepoDSLInput) (err error) {
 }
 
 func (c *Pipeline) UpdateRepoWithTSDB(input *UpdateRepoInput, ex ExportDesc) error {
+	return c.UpdateRepoWithTSDBWithContext(context.Background(), input, ex)
+}
+
+func (c *Pipeline) UpdateRepoWithTSDBWithContext(ctx context.Context, input *UpdateRepoInput, ex ExportDesc) error {
 	repoName, ok := ex.Spec["destRepoName"].(string)
 	if !ok {
 		return fmt.Errorf("export tsdb spec destRepoName assert error %v is not string", ex.Spec["destRepoName"])
@@ -188,7 +233,7 @@ func (c *Pipeline) UpdateRepoWithTSDB(input *UpdateRepoInput, ex ExportDesc) err
 		seriesUpdateExportToken = models.PandoraToken{}
 	}
 
-	err := c.UpdateExport(&UpdateExportInput{
+	err := c.UpdateExportWithContext(ctx, &UpdateExportInput{
 		RepoName:     input.RepoName,
 		ExportName:   ex.Name,
 		Spec:         spec,
@@ -213,6 +258,10 @@ func schemaNotIn(key string, schemas []logdb.RepoSchemaEntry) bool {
 }
 
 func (c *Pipeline) UpdateRepoWithLogDB(input *UpdateRepoInput, ex ExportDesc) error {
+	return c.UpdateRepoWithLogDBWithContext(context.Background(), input, ex)
+}
+
+func (c *Pipeline) UpdateRepoWithLogDBWithContext(ctx context.Context, input *UpdateRepoInput, ex ExportDesc) error {
 	repoName, ok := ex.Spec["destRepoName"].(string)
 	if !ok {
 		return fmt.Errorf("export logdb spec destRepoName assert error %v is not string", ex.Spec["destRepoName"])
@@ -242,7 +291,7 @@ func (c *Pipeline) UpdateRepoWithLogDB(input *UpdateRepoInput, ex ExportDesc) er
 	if err != nil {
 		return err
 	}
-	repoInfo, err := logdbAPI.GetRepo(&logdb.GetRepoInput{
+	repoInfo, err := logdbAPI.GetRepoWithContext(ctx, &logdb.GetRepoInput{
 		RepoName:     repoName,
 		PandoraToken: input.Option.AutoExportLogDBTokens.GetLogDBRepoToken,
 	})
@@ -262,12 +311,12 @@ func (c *Pipeline) UpdateRepoWithLogDB(input *UpdateRepoInput, ex ExportDesc) er
 		if input.Option.AutoExportToLogDBInput.AnalyzerInfo.FullText {
 			linput.FullText = logdb.NewFullText(logdb.StandardAnalyzer)
 		}
-		err = logdbAPI.CreateRepo(linput)
+		err = logdbAPI.CreateRepoWithContext(ctx, linput)
 		if err != nil && !reqerr.IsExistError(err) {
 			log.Error("UpdateRepoWithLogDB create logdb repo error", err)
 			return err
 		}
-		repoInfo, err = logdbAPI.GetRepo(&logdb.GetRepoInput{
+		repoInfo, err = logdbAPI.GetRepoWithContext(ctx, &logdb.GetRepoInput{
 			RepoName:     repoName,
 			PandoraToken: input.Option.AutoExportLogDBTokens.GetLogDBRepoToken,
 		})
@@ -299,7 +348,7 @@ func (c *Pipeline) UpdateRepoWithLogDB(input *UpdateRepoInput, ex ExportDesc) er
 		return nil
 	}
 
-	if err = logdbAPI.UpdateRepo(&logdb.UpdateRepoInput{
+	if err = logdbAPI.UpdateRepoWithContext(ctx, &logdb.UpdateRepoInput{
 		RepoName:     repoName,
 		Retention:    repoInfo.Retention,
 		Schema:       repoInfo.Schema,
@@ -314,7 +363,7 @@ func (c *Pipeline) UpdateRepoWithLogDB(input *UpdateRepoInput, ex ExportDesc) er
 		ipConfig = input.Option.IPConfig
 	}
 	spec := &ExportLogDBSpec{DestRepoName: repoName, Doc: docs, OmitEmpty: omitEmpty, OmitInvalid: omitInvalid, LocateIPConfig: ipConfig}
-	err = c.UpdateExport(&UpdateExportInput{
+	err = c.UpdateExportWithContext(ctx, &UpdateExportInput{
 		RepoName:     input.RepoName,
 		ExportName:   ex.Name,
 		Spec:         spec,
@@ -330,6 +379,10 @@ func (c *Pipeline) UpdateRepoWithLogDB(input *UpdateRepoInput, ex ExportDesc) er
 }
 
 func (c *Pipeline) UpdateRepoWithKodo(input *UpdateRepoInput, ex ExportDesc) error {
+	return c.UpdateRepoWithKodoWithContext(context.Background(), input, ex)
+}
+
+func (c *Pipeline) UpdateRepoWithKodoWithContext(ctx context.Context, input *UpdateRepoInput, ex ExportDesc) error {
 	bucketName, ok := ex.Spec["bucket"].(string)
 	if !ok {
 		return fmt.Errorf("export kodo spec bucketName assert error %v is not string", ex.Spec["bucket"])
@@ -418,7 +471,7 @@ func (c *Pipeline) UpdateRepoWithKodo(input *UpdateRepoInput, ex ExportDesc) err
 	if input.Option.KodoFileType == 1 {
 		spec.KodoFileType = 1
 	}
-	err := c.UpdateExport(&UpdateExportInput{
+	err := c.UpdateExportWithContext(ctx, &UpdateExportInput{
 		RepoName:     input.RepoName,
 		ExportName:   ex.Name,
 		Spec:         spec,
@@ -434,11 +487,15 @@ func (c *Pipeline) UpdateRepoWithKodo(input *UpdateRepoInput, ex ExportDesc) err
 }
 
 func (c *Pipeline) UpdateRepo(input *UpdateRepoInput) (err error) {
-	err = c.getSchemaSorted(input)
+	return c.UpdateRepoWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) UpdateRepoWithContext(ctx context.Context, input *UpdateRepoInput) (err error) {
+	err = c.getSchemaSorted(ctx, input)
 	if err != nil {
 		return
 	}
-	if err = c.updateRepo(input); err != nil {
+	if err = c.updateRepo(ctx, input); err != nil {
 		log.Error("update pipeline repo error", err)
 		return err
 	}
@@ -462,7 +519,7 @@ func (c *Pipeline) UpdateRepo(input *UpdateRepoInput) (err error) {
 	if option.ToKODO {
 		listExportToken = option.AutoExportKodoTokens.ListExportToken
 	}
-	exports, err := c.ListExports(&ListExportsInput{
+	exports, err := c.ListExportsWithContext(ctx, &ListExportsInput{
 		RepoName:     input.RepoName,
 		PandoraToken: listExportToken,
 	})
@@ -576,12 +633,12 @@ func (c *Pipeline) UpdateRepo(input *UpdateRepoInput) (err error) {
 				err = fmt.Errorf("export name is %v but type is %v not %v", ex.Name, ex.Type, ExportTypeLogDB)
 				return
 			}
-			err = c.UpdateRepoWithLogDB(input, ex)
+			err = c.UpdateRepoWithLogDBWithContext(ctx, input, ex)
 			if err != nil {
 				return
 			}
 		} else {
-			err = c.AutoExportToLogDB(&option.AutoExportToLogDBInput)
+			err = c.AutoExportToLogDBWithContext(ctx, &option.AutoExportToLogDBInput)
 			if err != nil {
 				log.Error("update repo and AutoExportToLogDB err: ", err)
 				return
@@ -595,12 +652,12 @@ func (c *Pipeline) UpdateRepo(input *UpdateRepoInput) (err error) {
 				err = fmt.Errorf("export name is %v but type is %v not %v", ex.Name, ex.Type, ExportTypeKODO)
 				return
 			}
-			err = c.UpdateRepoWithKodo(input, ex)
+			err = c.UpdateRepoWithKodoWithContext(ctx, input, ex)
 			if err != nil {
 				return
 			}
 		} else {
-			err = c.AutoExportToKODO(&option.AutoExportToKODOInput)
+			err = c.AutoExportToKODOWithContext(ctx, &option.AutoExportToKODOInput)
 			if err != nil {
 				log.Error("update repo and AutoExportToKODO err: ", err)
 				return
@@ -615,12 +672,12 @@ func (c *Pipeline) UpdateRepo(input *UpdateRepoInput) (err error) {
 				err = fmt.Errorf("export name is %v but type is %v not %v", ex.Name, ex.Type, ExportTypeTSDB)
 				return
 			}
-			err = c.UpdateRepoWithTSDB(input, ex)
+			err = c.UpdateRepoWithTSDBWithContext(ctx, input, ex)
 			if err != nil {
 				return
 			}
 		} else {
-			err = c.AutoExportToTSDB(&option.AutoExportToTSDBInput)
+			err = c.AutoExportToTSDBWithContext(ctx, &option.AutoExportToTSDBInput)
 			if err != nil {
 				log.Error("update repo and AutoExportToTSDB err: ", err)
 				return
@@ -630,10 +687,10 @@ func (c *Pipeline) UpdateRepo(input *UpdateRepoInput) (err error) {
 	return nil
 }
 
-func (c *Pipeline) updateRepo(input *UpdateRepoInput) (err error) {
+func (c *Pipeline) updateRepo(ctx context.Context, input *UpdateRepoInput) (err error) {
 	op := c.NewOperation(base.OpUpdateRepo, input.RepoName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -642,22 +699,38 @@ func (c *Pipeline) updateRepo(input *UpdateRepoInput) (err error) {
 }
 
 func (c *Pipeline) GetRepo(input *GetRepoInput) (output *GetRepoOutput, err error) {
+	return c.GetRepoWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) GetRepoWithContext(ctx context.Context, input *GetRepoInput) (output *GetRepoOutput, err error) {
 	op := c.NewOperation(base.OpGetRepo, input.RepoName)
 
 	output = &GetRepoOutput{}
-	req := c.newRequest(op, input.Token, output)
-	return output, req.Send()
+	req := c.newRequest(ctx, op, input.Token, output)
+	if err = req.Send(); err != nil {
+		return
+	}
+	c.SetRepoRegion(input.RepoName, output.Region)
+	return
 }
 
 func (c *Pipeline) GetSampleData(input *GetSampleDataInput) (output *SampleDataOutput, err error) {
+	return c.GetSampleDataWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) GetSampleDataWithContext(ctx context.Context, input *GetSampleDataInput) (output *SampleDataOutput, err error) {
 	op := c.NewOperation(base.OpGetSampleData, input.RepoName, input.Count)
 
 	output = &SampleDataOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) ListRepos(input *ListReposInput) (output *ListReposOutput, err error) {
+	return c.ListReposWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) ListReposWithContext(ctx context.Context, input *ListReposInput) (output *ListReposOutput, err error) {
 	var op *request.Operation
 	if input.WithDag {
 		if input.Authorized {
@@ -669,14 +742,18 @@ func (c *Pipeline) ListRepos(input *ListReposInput) (output *ListReposOutput, er
 		op = c.NewOperation(base.OpListRepos)
 	}
 	output = &ListReposOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) DeleteRepo(input *DeleteRepoInput) (err error) {
+	return c.DeleteRepoWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) DeleteRepoWithContext(ctx context.Context, input *DeleteRepoInput) (err error) {
 	op := c.NewOperation(base.OpDeleteRepo, input.RepoName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 func getTagStr(tags map[string]interface{}) (tagStr []byte) {
@@ -691,6 +768,10 @@ func getTagStr(tags map[string]interface{}) (tagStr []byte) {
 
 // tags 和 rules 写了if else是为了兼容性，服务端鉴权兼容了请求参数变化可以使用同一个鉴权token后，就可以去掉这个兼容性
 func (c *Pipeline) PostData(input *PostDataInput) (err error) {
+	return c.PostDataWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) PostDataWithContext(ctx context.Context, input *PostDataInput) (err error) {
 	var op *request.Operation
 	if len(input.Tags) > 0 {
 		tagStr := getTagStr(input.Tags)
@@ -698,19 +779,24 @@ func (c *Pipeline) PostData(input *PostDataInput) (err error) {
 	} else {
 		op = c.NewOperation(base.OpPostData, input.RepoName)
 	}
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	req.SetBufferBody(input.Points.Buffer())
+	req.SetCompressible()
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeText)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
-	req.SetFlowLimiter(c.flowLimit)
-	req.SetReqLimiter(c.reqLimit)
+	c.setLimiters(req, input.RepoName, input.ResourceOwner)
+	c.routeToRepoRegion(req, input.RepoName)
 	return req.Send()
 }
 
 // tags 和 rules 写了if else是为了兼容性，服务端鉴权兼容了请求参数变化可以使用同一个鉴权token后，就可以去掉这个兼容性
 func (c *Pipeline) PostTextData(input *PostTextDataInput) error {
+	return c.PostTextDataWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) PostTextDataWithContext(ctx context.Context, input *PostTextDataInput) error {
 	var op *request.Operation
 	if len(input.Tags) == 0 && len(input.Rules) == 0 {
 		op = c.NewOperation(base.OpPostTextData, input.RepoName)
@@ -719,14 +805,14 @@ func (c *Pipeline) PostTextData(input *PostTextDataInput) error {
 		rules := strings.Join(input.Rules, ",")
 		op = c.NewOperation(base.OpPostTextData, input.RepoName, base64.URLEncoding.EncodeToString(tagStr), rules)
 	}
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	req.SetBufferBody(joinStrings(input.Text, '\n'))
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeText)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
-	req.SetFlowLimiter(c.flowLimit)
-	req.SetReqLimiter(c.reqLimit)
+	c.setLimiters(req, input.RepoName, input.ResourceOwner)
+	c.routeToRepoRegion(req, input.RepoName)
 	return req.Send()
 }
 
@@ -744,6 +830,10 @@ func joinStrings(strs []string, sep byte) []byte {
 
 // tags 和 rules 写了if else是为了兼容性，服务端鉴权兼容了请求参数变化可以使用同一个鉴权token后，就可以去掉这个兼容性
 func (c *Pipeline) PostRawtextData(input *PostRawtextDataInput) (err error) {
+	return c.PostRawtextDataWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) PostRawtextDataWithContext(ctx context.Context, input *PostRawtextDataInput) (err error) {
 
 	var op *request.Operation
 	if len(input.Tags) == 0 && len(input.Rules) == 0 {
@@ -759,14 +849,14 @@ func (c *Pipeline) PostRawtextData(input *PostRawtextDataInput) (err error) {
 		Message string `json:"message"`
 	}
 	output := &PortalRet{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	req.SetBufferBody(input.Rawtext)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeText)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
-	req.SetFlowLimiter(c.flowLimit)
-	req.SetReqLimiter(c.reqLimit)
+	c.setLimiters(req, input.RepoName, input.ResourceOwner)
+	c.routeToRepoRegion(req, input.RepoName)
 
 	err = req.Send()
 	if err != nil {
@@ -784,10 +874,15 @@ func (c *Pipeline) PostRawtextData(input *PostRawtextDataInput) (err error) {
 }
 
 func (c *Pipeline) PostLargeData(input *PostDataInput, timeout time.Duration) (datafailed Points, err error) {
-	deadline := time.Now().Add(timeout)
+	return c.PostLargeDataWithContext(context.Background(), input, timeout)
+}
+
+func (c *Pipeline) PostLargeDataWithContext(ctx context.Context, input *PostDataInput, timeout time.Duration) (datafailed Points, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 	packages := unpackPoints(input)
 	for i, pContext := range packages {
-		err = c.PostDataFromBytesWithDeadline(pContext.inputs, deadline)
+		err = c.PostDataFromBytesWithContext(ctx, pContext.inputs)
 		if err != nil {
 			for j := i; j < len(packages); j++ {
 				datafailed = append(datafailed, packages[j].datas...)
@@ -843,15 +938,24 @@ type pointContext struct {
 	inputs *PostDataFromBytesInput
 }
 
-func (c *Pipeline) unpack(input *SchemaFreeInput) (packages []pointContext, err error) {
+// unpack 把数据打包为不超过 PandoraMaxBatchSize 的 point，deadLetters 是存在类型冲突、需要写入死信 repo 的数据
+func (c *Pipeline) unpack(ctx context.Context, input *SchemaFreeInput) (packages []pointContext, deadLetters []deadLetter, err error) {
 	packages = []pointContext{}
 	var buf bytes.Buffer
-	var start = 0
-	repoUpdate := false
-	for i, d := range input.Datas {
-		point, update, err := c.generatePoint(d, input)
+	// datas 是已经写入 buf 的数据，写入死信 repo 与没有字段的数据不在其中，发送失败时不会重复返回
+	var datas Datas
+	repoUpdate, err := c.sampleSchemas(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, d := range input.Datas {
+		point, update, deadLetterKey, err := c.generatePoint(ctx, d, input)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		if deadLetterKey != "" {
+			deadLetters = append(deadLetters, deadLetter{key: deadLetterKey, data: d})
+			continue
 		}
 		if len(point.Fields) < 1 {
 			continue
@@ -861,11 +965,11 @@ func (c *Pipeline) unpack(input *SchemaFreeInput) (packages []pointContext, err
 		}
 		pointBytes := point.ToBytes()
 		// 当buf中有数据，并且加入该条数据后就超过了最大的限制，则提交这个input
-		if start < i && buf.Len() > 0 && buf.Len()+len(pointBytes) >= PandoraMaxBatchSize {
+		if len(datas) > 0 && buf.Len()+len(pointBytes) >= PandoraMaxBatchSize {
 			tmpBuff := make([]byte, buf.Len())
 			copy(tmpBuff, buf.Bytes())
 			packages = append(packages, pointContext{
-				datas: input.Datas[start:i],
+				datas: datas,
 				inputs: &PostDataFromBytesInput{
 					RepoName:     input.RepoName,
 					Buffer:       tmpBuff,
@@ -874,15 +978,16 @@ func (c *Pipeline) unpack(input *SchemaFreeInput) (packages []pointContext, err
 				},
 			})
 			buf.Reset()
-			start = i
+			datas = nil
 		}
 		buf.Write(pointBytes)
+		datas = append(datas, d)
 	}
 	if buf.Len() > 0 {
 		tmpBuff := make([]byte, buf.Len())
 		copy(tmpBuff, buf.Bytes())
 		packages = append(packages, pointContext{
-			datas: input.Datas[start:],
+			datas: datas,
 			inputs: &PostDataFromBytesInput{
 				RepoName:     input.RepoName,
 				Buffer:       tmpBuff,
@@ -911,7 +1016,7 @@ func (c *Pipeline) unpack(input *SchemaFreeInput) (packages []pointContext, err
 			SchemaFreeToken:  input.SchemaFreeToken,
 			Description:      input.Description,
 		}
-		if err = c.InitOrUpdateWorkflow(initOrUpdateInput); err != nil {
+		if err = c.InitOrUpdateWorkflowWithContext(ctx, initOrUpdateInput); err != nil {
 			return
 		}
 		newSchemas := RepoSchema{}
@@ -935,13 +1040,20 @@ func convertDatas(datas Datas) []map[string]interface{} {
 
 // PostDataSchemaFree 会更新schema，newSchemas不为nil时就表示更新了，error与否不影响
 func (c *Pipeline) PostDataSchemaFree(input *SchemaFreeInput) (newSchemas map[string]RepoSchemaEntry, err error) {
-	contexts, err := c.unpack(input)
+	return c.PostDataSchemaFreeWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) PostDataSchemaFreeWithContext(ctx context.Context, input *SchemaFreeInput) (newSchemas map[string]RepoSchemaEntry, err error) {
+	if err = input.Option.validateTypeConflict(); err != nil {
+		return
+	}
+	contexts, deadLetters, err := c.unpack(ctx, input)
 	if err != nil {
 		if reqErr, ok := err.(*reqerr.RequestError); ok && reqErr.ErrorType == reqerr.InvalidArgs {
-			err = reqerr.NewSendError("Cannot send data to pandora, "+err.Error(), convertDatas(input.Datas), reqerr.TypeContainInvalidPoint)
+			err = reqerr.NewSendError("Cannot send data to pandora, "+err.Error(), convertDatas(input.Datas), reqerr.TypeContainInvalidPoint).WithCause(err)
 			return
 		}
-		err = reqerr.NewSendError("Cannot send data to pandora, "+err.Error(), convertDatas(input.Datas), reqerr.TypeDefault)
+		err = reqerr.NewSendError("Cannot send data to pandora, "+err.Error(), convertDatas(input.Datas), reqerr.TypeDefault).WithCause(err)
 		return
 	}
 
@@ -952,7 +1064,7 @@ func (c *Pipeline) PostDataSchemaFree(input *SchemaFreeInput) (newSchemas map[st
 	newSchemas = c.repoSchemas[input.RepoName]
 	c.repoSchemaMux.Unlock()
 	for _, pContext := range contexts {
-		err := c.PostDataFromBytes(pContext.inputs)
+		err := c.PostDataFromBytesWithContext(ctx, pContext.inputs)
 		if err != nil {
 			reqErr, ok := err.(*reqerr.RequestError)
 			if ok {
@@ -976,17 +1088,29 @@ func (c *Pipeline) PostDataSchemaFree(input *SchemaFreeInput) (newSchemas map[st
 			lastErr = err
 		}
 	}
+	if len(deadLetters) > 0 {
+		if err := c.postDeadLetters(ctx, input, deadLetters); err != nil {
+			for _, d := range deadLetters {
+				failDatas = append(failDatas, d.data)
+			}
+			lastErr = err
+		}
+	}
 	if len(failDatas) > 0 {
-		err = reqerr.NewSendError("Cannot send data to pandora, "+lastErr.Error(), convertDatas(failDatas), errType)
+		err = reqerr.NewSendError("Cannot send data to pandora, "+lastErr.Error(), convertDatas(failDatas), errType).WithCause(lastErr)
 	}
 	return
 }
 
 func (c *Pipeline) PostDataFromFile(input *PostDataFromFileInput) (err error) {
+	return c.PostDataFromFileWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) PostDataFromFileWithContext(ctx context.Context, input *PostDataFromFileInput) (err error) {
 	tagStr := getTagStr(input.Tags)
 	op := c.NewOperation(base.OpPostData, input.RepoName, base64.URLEncoding.EncodeToString(tagStr))
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	file, err := os.Open(input.FilePath)
 	if err != nil {
 		return err
@@ -999,8 +1123,8 @@ func (c *Pipeline) PostDataFromFile(input *PostDataFromFileInput) (err error) {
 	req.SetBodyLength(stfile.Size())
 	req.SetReaderBody(file)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeText)
-	req.SetFlowLimiter(c.flowLimit)
-	req.SetReqLimiter(c.reqLimit)
+	c.setLimiters(req, input.RepoName, "")
+	c.routeToRepoRegion(req, input.RepoName)
 	return req.Send()
 }
 
@@ -1008,50 +1132,53 @@ func (c *Pipeline) PostDataFromFile(input *PostDataFromFileInput) (err error) {
 // 用户如果使用该接口，需要根据 pandora 打点协议将数据转换为bytes数据流，具体的转换方式见文档：
 // https://qiniu.github.io/pandora-docs/#/push_data_api
 func (c *Pipeline) PostDataFromReader(input *PostDataFromReaderInput) (err error) {
+	return c.PostDataFromReaderWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) PostDataFromReaderWithContext(ctx context.Context, input *PostDataFromReaderInput) (err error) {
 	tagStr := getTagStr(input.Tags)
 	op := c.NewOperation(base.OpPostData, input.RepoName, base64.URLEncoding.EncodeToString(tagStr))
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	req.SetReaderBody(input.Reader)
 	req.SetBodyLength(input.BodyLength)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeText)
-	req.SetFlowLimiter(c.flowLimit)
-	req.SetReqLimiter(c.reqLimit)
+	c.setLimiters(req, input.RepoName, "")
+	c.routeToRepoRegion(req, input.RepoName)
 	return req.Send()
 }
 
 func (c *Pipeline) PostDataFromBytes(input *PostDataFromBytesInput) (err error) {
+	return c.PostDataFromBytesWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) PostDataFromBytesWithContext(ctx context.Context, input *PostDataFromBytesInput) (err error) {
 	tagStr := getTagStr(input.Tags)
 	op := c.NewOperation(base.OpPostData, input.RepoName, base64.URLEncoding.EncodeToString(tagStr))
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	req.SetBufferBody(input.Buffer)
+	req.SetCompressible()
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeText)
-	req.SetFlowLimiter(c.flowLimit)
-	req.SetReqLimiter(c.reqLimit)
+	c.setLimiters(req, input.RepoName, "")
+	c.routeToRepoRegion(req, input.RepoName)
 	return req.Send()
 }
 
 func (c *Pipeline) PostDataFromBytesWithDeadline(input *PostDataFromBytesInput, deadline time.Time) (err error) {
-	tagStr := getTagStr(input.Tags)
-	op := c.NewOperation(base.OpPostData, input.RepoName, base64.URLEncoding.EncodeToString(tagStr))
-
-	req := c.newRequest(op, input.Token, nil)
-	req.SetBufferBody(input.Buffer)
-	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeText)
-	req.SetFlowLimiter(c.flowLimit)
-	req.SetReqLimiter(c.reqLimit)
-
-	ctx := req.HTTPRequest.Context()
-	ctx, _ = context.WithDeadline(ctx, deadline)
-	req.HTTPRequest = req.HTTPRequest.WithContext(ctx)
-	return req.Send()
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	return c.PostDataFromBytesWithContext(ctx, input)
 }
 
 func (c *Pipeline) UploadPlugin(input *UploadPluginInput) (err error) {
+	return c.UploadPluginWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) UploadPluginWithContext(ctx context.Context, input *UploadPluginInput) (err error) {
 	op := c.NewOperation(base.OpUploadPlugin, input.PluginName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	req.EnableContentMD5d()
 	req.SetBufferBody(input.Buffer.Bytes())
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJar)
@@ -1059,9 +1186,13 @@ func (c *Pipeline) UploadPlugin(input *UploadPluginInput) (err error) {
 }
 
 func (c *Pipeline) UploadPluginFromFile(input *UploadPluginFromFileInput) (err error) {
+	return c.UploadPluginFromFileWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) UploadPluginFromFileWithContext(ctx context.Context, input *UploadPluginFromFileInput) (err error) {
 	op := c.NewOperation(base.OpUploadPlugin, input.PluginName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	req.EnableContentMD5d()
 
 	file, err := os.Open(input.FilePath)
@@ -1075,10 +1206,14 @@ func (c *Pipeline) UploadPluginFromFile(input *UploadPluginFromFileInput) (err e
 }
 
 func (c *Pipeline) ListPlugins(input *ListPluginsInput) (output *ListPluginsOutput, err error) {
+	return c.ListPluginsWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) ListPluginsWithContext(ctx context.Context, input *ListPluginsInput) (output *ListPluginsOutput, err error) {
 	op := c.NewOperation(base.OpListPlugins)
 
 	output = &ListPluginsOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
@@ -1086,18 +1221,26 @@ func (c *Pipeline) ListPlugins(input *ListPluginsInput) (output *ListPluginsOutp
 }
 
 func (c *Pipeline) VerifyPlugin(input *VerifyPluginInput) (output *VerifyPluginOutput, err error) {
+	return c.VerifyPluginWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) VerifyPluginWithContext(ctx context.Context, input *VerifyPluginInput) (output *VerifyPluginOutput, err error) {
 	op := c.NewOperation(base.OpVerifyPlugin, input.PluginName)
 
 	output = &VerifyPluginOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) GetPlugin(input *GetPluginInput) (output *GetPluginOutput, err error) {
+	return c.GetPluginWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) GetPluginWithContext(ctx context.Context, input *GetPluginInput) (output *GetPluginOutput, err error) {
 	op := c.NewOperation(base.OpGetPlugin, input.PluginName)
 
 	output = &GetPluginOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
@@ -1105,16 +1248,24 @@ func (c *Pipeline) GetPlugin(input *GetPluginInput) (output *GetPluginOutput, er
 }
 
 func (c *Pipeline) DeletePlugin(input *DeletePluginInput) (err error) {
+	return c.DeletePluginWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) DeletePluginWithContext(ctx context.Context, input *DeletePluginInput) (err error) {
 	op := c.NewOperation(base.OpDeletePlugin, input.PluginName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Pipeline) CreateTransform(input *CreateTransformInput) (err error) {
+	return c.CreateTransformWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) CreateTransformWithContext(ctx context.Context, input *CreateTransformInput) (err error) {
 	op := c.NewOperation(base.OpCreateTransform, input.SrcRepoName, input.TransformName, input.DestRepoName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input.Spec); err != nil {
 		return
 	}
@@ -1123,9 +1274,13 @@ func (c *Pipeline) CreateTransform(input *CreateTransformInput) (err error) {
 }
 
 func (c *Pipeline) UpdateTransform(input *UpdateTransformInput) (err error) {
+	return c.UpdateTransformWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) UpdateTransformWithContext(ctx context.Context, input *UpdateTransformInput) (err error) {
 	op := c.NewOperation(base.OpUpdateTransform, input.SrcRepoName, input.TransformName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input.Spec); err != nil {
 		return
 	}
@@ -1134,32 +1289,48 @@ func (c *Pipeline) UpdateTransform(input *UpdateTransformInput) (err error) {
 }
 
 func (c *Pipeline) ListTransforms(input *ListTransformsInput) (output *ListTransformsOutput, err error) {
+	return c.ListTransformsWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) ListTransformsWithContext(ctx context.Context, input *ListTransformsInput) (output *ListTransformsOutput, err error) {
 	op := c.NewOperation(base.OpListTransforms, input.RepoName)
 
 	output = &ListTransformsOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) GetTransform(input *GetTransformInput) (output *GetTransformOutput, err error) {
+	return c.GetTransformWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) GetTransformWithContext(ctx context.Context, input *GetTransformInput) (output *GetTransformOutput, err error) {
 	op := c.NewOperation(base.OpGetTransform, input.RepoName, input.TransformName)
 
 	output = &GetTransformOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) DeleteTransform(input *DeleteTransformInput) (err error) {
+	return c.DeleteTransformWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) DeleteTransformWithContext(ctx context.Context, input *DeleteTransformInput) (err error) {
 	op := c.NewOperation(base.OpDeleteTransform, input.RepoName, input.TransformName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Pipeline) CreateExport(input *CreateExportInput) (err error) {
+	return c.CreateExportWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) CreateExportWithContext(ctx context.Context, input *CreateExportInput) (err error) {
 	op := c.NewOperation(base.OpCreateExport, input.RepoName, input.ExportName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -1168,9 +1339,13 @@ func (c *Pipeline) CreateExport(input *CreateExportInput) (err error) {
 }
 
 func (c *Pipeline) UpdateExport(input *UpdateExportInput) (err error) {
+	return c.UpdateExportWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) UpdateExportWithContext(ctx context.Context, input *UpdateExportInput) (err error) {
 	op := c.NewOperation(base.OpUpdateExport, input.RepoName, input.ExportName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -1179,32 +1354,48 @@ func (c *Pipeline) UpdateExport(input *UpdateExportInput) (err error) {
 }
 
 func (c *Pipeline) ListExports(input *ListExportsInput) (output *ListExportsOutput, err error) {
+	return c.ListExportsWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) ListExportsWithContext(ctx context.Context, input *ListExportsInput) (output *ListExportsOutput, err error) {
 	op := c.NewOperation(base.OpListExports, input.RepoName)
 
 	output = &ListExportsOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) GetExport(input *GetExportInput) (output *GetExportOutput, err error) {
+	return c.GetExportWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) GetExportWithContext(ctx context.Context, input *GetExportInput) (output *GetExportOutput, err error) {
 	op := c.NewOperation(base.OpGetExport, input.RepoName, input.ExportName)
 
 	output = &GetExportOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) DeleteExport(input *DeleteExportInput) (err error) {
+	return c.DeleteExportWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) DeleteExportWithContext(ctx context.Context, input *DeleteExportInput) (err error) {
 	op := c.NewOperation(base.OpDeleteExport, input.RepoName, input.ExportName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Pipeline) CreateDatasource(input *CreateDatasourceInput) (err error) {
+	return c.CreateDatasourceWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) CreateDatasourceWithContext(ctx context.Context, input *CreateDatasourceInput) (err error) {
 	op := c.NewOperation(base.OpCreateDatasource, input.DatasourceName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -1213,32 +1404,48 @@ func (c *Pipeline) CreateDatasource(input *CreateDatasourceInput) (err error) {
 }
 
 func (c *Pipeline) ListDatasources() (output *ListDatasourcesOutput, err error) {
+	return c.ListDatasourcesWithContext(context.Background())
+}
+
+func (c *Pipeline) ListDatasourcesWithContext(ctx context.Context) (output *ListDatasourcesOutput, err error) {
 	op := c.NewOperation(base.OpListDatasources)
 
 	output = &ListDatasourcesOutput{}
-	req := c.newRequest(op, "", &output)
+	req := c.newRequest(ctx, op, "", &output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) GetDatasource(input *GetDatasourceInput) (output *GetDatasourceOutput, err error) {
+	return c.GetDatasourceWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) GetDatasourceWithContext(ctx context.Context, input *GetDatasourceInput) (output *GetDatasourceOutput, err error) {
 	op := c.NewOperation(base.OpGetDatasource, input.DatasourceName)
 
 	output = &GetDatasourceOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) DeleteDatasource(input *DeleteDatasourceInput) (err error) {
+	return c.DeleteDatasourceWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) DeleteDatasourceWithContext(ctx context.Context, input *DeleteDatasourceInput) (err error) {
 	op := c.NewOperation(base.OpDeleteDatasource, input.DatasourceName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Pipeline) CreateJob(input *CreateJobInput) (err error) {
+	return c.CreateJobWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) CreateJobWithContext(ctx context.Context, input *CreateJobInput) (err error) {
 	op := c.NewOperation(base.OpCreateJob, input.JobName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -1247,6 +1454,10 @@ func (c *Pipeline) CreateJob(input *CreateJobInput) (err error) {
 }
 
 func (c *Pipeline) ListJobs(input *ListJobsInput) (output *ListJobsOutput, err error) {
+	return c.ListJobsWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) ListJobsWithContext(ctx context.Context, input *ListJobsInput) (output *ListJobsOutput, err error) {
 	query := ""
 	values := url.Values{}
 	if input.SrcJobName != "" {
@@ -1261,29 +1472,41 @@ func (c *Pipeline) ListJobs(input *ListJobsInput) (output *ListJobsOutput, err e
 	op := c.NewOperation(base.OpListJobs, query)
 
 	output = &ListJobsOutput{}
-	req := c.newRequest(op, "", &output)
+	req := c.newRequest(ctx, op, "", &output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) GetJob(input *GetJobInput) (output *GetJobOutput, err error) {
+	return c.GetJobWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) GetJobWithContext(ctx context.Context, input *GetJobInput) (output *GetJobOutput, err error) {
 	op := c.NewOperation(base.OpGetJob, input.JobName)
 
 	output = &GetJobOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) DeleteJob(input *DeleteJobInput) (err error) {
+	return c.DeleteJobWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) DeleteJobWithContext(ctx context.Context, input *DeleteJobInput) (err error) {
 	op := c.NewOperation(base.OpDeleteJob, input.JobName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Pipeline) StartJob(input *StartJobInput) (err error) {
+	return c.StartJobWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) StartJobWithContext(ctx context.Context, input *StartJobInput) (err error) {
 	op := c.NewOperation(base.OpStartJob, input.JobName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -1292,10 +1515,14 @@ func (c *Pipeline) StartJob(input *StartJobInput) (err error) {
 }
 
 func (c *Pipeline) GetJobHistory(input *GetJobHistoryInput) (output *GetJobHistoryOutput, err error) {
+	return c.GetJobHistoryWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) GetJobHistoryWithContext(ctx context.Context, input *GetJobHistoryInput) (output *GetJobHistoryOutput, err error) {
 	op := c.NewOperation(base.OpGetJobHistory, input.JobName)
 
 	output = &GetJobHistoryOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
@@ -1303,17 +1530,25 @@ func (c *Pipeline) GetJobHistory(input *GetJobHistoryInput) (output *GetJobHisto
 }
 
 func (c *Pipeline) StopJob(input *StopJobInput) (err error) {
+	return c.StopJobWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) StopJobWithContext(ctx context.Context, input *StopJobInput) (err error) {
 	op := c.NewOperation(base.OpStopJob, input.JobName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Pipeline) StopJobBatch(input *StopJobBatchInput) (output *StopJobBatchOutput, err error) {
+	return c.StopJobBatchWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) StopJobBatchWithContext(ctx context.Context, input *StopJobBatchInput) (output *StopJobBatchOutput, err error) {
 	op := c.NewOperation(base.OpStopJobBatch)
 
 	output = &StopJobBatchOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -1325,10 +1560,14 @@ func (c *Pipeline) StopJobBatch(input *StopJobBatchInput) (output *StopJobBatchO
 }
 
 func (c *Pipeline) RerunJobBatch(input *RerunJobBatchInput) (output *RerunJobBatchOutput, err error) {
+	return c.RerunJobBatchWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) RerunJobBatchWithContext(ctx context.Context, input *RerunJobBatchInput) (output *RerunJobBatchOutput, err error) {
 	op := c.NewOperation(base.OpRerunJobBatch)
 
 	output = &RerunJobBatchOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -1340,9 +1579,13 @@ func (c *Pipeline) RerunJobBatch(input *RerunJobBatchInput) (output *RerunJobBat
 }
 
 func (c *Pipeline) CreateJobExport(input *CreateJobExportInput) (err error) {
+	return c.CreateJobExportWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) CreateJobExportWithContext(ctx context.Context, input *CreateJobExportInput) (err error) {
 	op := c.NewOperation(base.OpCreateJobExport, input.JobName, input.ExportName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -1351,33 +1594,49 @@ func (c *Pipeline) CreateJobExport(input *CreateJobExportInput) (err error) {
 }
 
 func (c *Pipeline) ListJobExports(input *ListJobExportsInput) (output *ListJobExportsOutput, err error) {
+	return c.ListJobExportsWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) ListJobExportsWithContext(ctx context.Context, input *ListJobExportsInput) (output *ListJobExportsOutput, err error) {
 	op := c.NewOperation(base.OpListJobExports, input.JobName)
 
 	output = &ListJobExportsOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) GetJobExport(input *GetJobExportInput) (output *GetJobExportOutput, err error) {
+	return c.GetJobExportWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) GetJobExportWithContext(ctx context.Context, input *GetJobExportInput) (output *GetJobExportOutput, err error) {
 	op := c.NewOperation(base.OpGetJobExport, input.JobName, input.ExportName)
 
 	output = &GetJobExportOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) DeleteJobExport(input *DeleteJobExportInput) (err error) {
+	return c.DeleteJobExportWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) DeleteJobExportWithContext(ctx context.Context, input *DeleteJobExportInput) (err error) {
 	op := c.NewOperation(base.OpDeleteJobExport, input.JobName, input.ExportName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Pipeline) RetrieveSchema(input *RetrieveSchemaInput) (output *RetrieveSchemaOutput, err error) {
+	return c.RetrieveSchemaWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) RetrieveSchemaWithContext(ctx context.Context, input *RetrieveSchemaInput) (output *RetrieveSchemaOutput, err error) {
 	op := c.NewOperation(base.OpRetrieveSchema)
 
 	output = &RetrieveSchemaOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -1386,7 +1645,43 @@ func (c *Pipeline) RetrieveSchema(input *RetrieveSchemaInput) (output *RetrieveS
 }
 
 func (c *Pipeline) MakeToken(desc *base.TokenDesc) (string, error) {
-	return base.MakeTokenInternal(c.Config.Ak, c.Config.Sk, desc)
+	creds, err := c.Config.GetCredentials()
+	if err != nil {
+		return "", err
+	}
+	return base.MakeTokenInternal(creds.Ak, creds.Sk, desc)
+}
+
+// MakeSchemaFreeToken 通过 TokenManager 一次性生成 PostDataSchemaFree 需要的全部 token，token 即将过期时会重新签发
+func (c *Pipeline) MakeSchemaFreeToken(m *base.TokenManager, repoName, workflowName string) (tokens SchemaFreeToken, err error) {
+	ops := []struct {
+		token       *models.PandoraToken
+		op          string
+		arg         string
+		contentType string
+	}{
+		{&tokens.PipelineCreateRepoToken, base.OpCreateRepo, repoName, base.ContentTypeJson},
+		{&tokens.PipelinePostDataToken, base.OpPostData, repoName, base.ContentTypeText},
+		{&tokens.PipelineGetRepoToken, base.OpGetRepo, repoName, ""},
+		{&tokens.PipelineUpdateRepoToken, base.OpUpdateRepo, repoName, base.ContentTypeJson},
+		{&tokens.PipelineGetWorkflowToken, base.OpGetWorkflow, workflowName, ""},
+		{&tokens.PipelineCreateWorkflowToken, base.OpCreateWorkflow, workflowName, base.ContentTypeJson},
+		{&tokens.PipelineStartWorkflowToken, base.OpStartWorkflow, workflowName, ""},
+		{&tokens.PipelineStopWorkflowToken, base.OpStopWorkflow, workflowName, ""},
+		{&tokens.PipelineGetWorkflowStatusToken, base.OpGetWorkflowStatus, workflowName, ""},
+	}
+	for _, v := range ops {
+		op := c.NewOperation(v.op, v.arg)
+		v.token.Token, err = m.Token(base.TokenDesc{
+			Url:         op.Path,
+			Method:      op.Method,
+			ContentType: v.contentType,
+		})
+		if err != nil {
+			return SchemaFreeToken{}, err
+		}
+	}
+	return
 }
 
 func (c *Pipeline) GetDefault(entry RepoSchemaEntry) interface{} {
@@ -1394,11 +1689,19 @@ func (c *Pipeline) GetDefault(entry RepoSchemaEntry) interface{} {
 }
 
 func (c *Pipeline) GetUpdateSchemas(repoName string) (schemas map[string]RepoSchemaEntry, err error) {
-	return c.GetUpdateSchemasWithInput(&GetRepoInput{RepoName: repoName})
+	return c.GetUpdateSchemasWithContext(context.Background(), repoName)
+}
+
+func (c *Pipeline) GetUpdateSchemasWithContext(ctx context.Context, repoName string) (schemas map[string]RepoSchemaEntry, err error) {
+	return c.GetUpdateSchemasWithInputWithContext(ctx, &GetRepoInput{RepoName: repoName})
 }
 
 func (c *Pipeline) GetUpdateSchemasWithInput(input *GetRepoInput) (schemas map[string]RepoSchemaEntry, err error) {
-	repo, err := c.GetRepo(input)
+	return c.GetUpdateSchemasWithInputWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) GetUpdateSchemasWithInputWithContext(ctx context.Context, input *GetRepoInput) (schemas map[string]RepoSchemaEntry, err error) {
+	repo, err := c.GetRepoWithContext(ctx, input)
 
 	if err != nil {
 		return
@@ -1414,9 +1717,13 @@ func (c *Pipeline) GetUpdateSchemasWithInput(input *GetRepoInput) (schemas map[s
 }
 
 func (c *Pipeline) CreateForLogDB(input *CreateRepoForLogDBInput) error {
+	return c.CreateForLogDBWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) CreateForLogDBWithContext(ctx context.Context, input *CreateRepoForLogDBInput) error {
 	pinput := formPipelineRepoInput(input.RepoName, input.Region, input.Schema)
 	pinput.PandoraToken = input.PipelineCreateRepoToken
-	err := c.CreateRepo(pinput)
+	err := c.CreateRepoWithContext(ctx, pinput)
 	if err != nil && !reqerr.IsExistError(err) {
 		return err
 	}
@@ -1426,17 +1733,21 @@ func (c *Pipeline) CreateForLogDB(input *CreateRepoForLogDBInput) error {
 		return err
 	}
 	linput.PandoraToken = input.CreateLogDBRepoToken
-	err = logdbapi.CreateRepo(linput)
+	err = logdbapi.CreateRepoWithContext(ctx, linput)
 	if err != nil && !reqerr.IsExistError(err) {
 		return err
 	}
 	logDBSpec := c.FormLogDBSpec(input)
 	exportInput := c.FormExportInput(input.RepoName, ExportTypeLogDB, logDBSpec)
 	exportInput.PandoraToken = input.CreateExportToken
-	return c.CreateExport(exportInput)
+	return c.CreateExportWithContext(ctx, exportInput)
 }
 
 func (c *Pipeline) CreateForLogDBDSL(input *CreateRepoForLogDBDSLInput) error {
+	return c.CreateForLogDBDSLWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) CreateForLogDBDSLWithContext(ctx context.Context, input *CreateRepoForLogDBDSLInput) error {
 	schemas, err := toSchema(input.Schema, 0)
 	if err != nil {
 		return err
@@ -1449,11 +1760,15 @@ func (c *Pipeline) CreateForLogDBDSL(input *CreateRepoForLogDBDSLInput) error {
 		Retention:             input.Retention,
 		AutoExportLogDBTokens: input.AutoExportLogDBTokens,
 	}
-	return c.CreateForLogDB(ci)
+	return c.CreateForLogDBWithContext(ctx, ci)
 }
 
 func (c *Pipeline) CreateForTSDB(input *CreateRepoForTSDBInput) error {
-	_, err := c.GetRepo(&GetRepoInput{
+	return c.CreateForTSDBWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) CreateForTSDBWithContext(ctx context.Context, input *CreateRepoForTSDBInput) error {
+	_, err := c.GetRepoWithContext(ctx, &GetRepoInput{
 		RepoName:     input.RepoName,
 		PandoraToken: input.PipelineGetRepoToken,
 	})
@@ -1467,7 +1782,7 @@ func (c *Pipeline) CreateForTSDB(input *CreateRepoForTSDBInput) error {
 	if input.TSDBRepoName == "" {
 		input.TSDBRepoName = input.RepoName
 	}
-	err = tsdbapi.CreateRepo(&tsdb.CreateRepoInput{
+	err = tsdbapi.CreateRepoWithContext(ctx, &tsdb.CreateRepoInput{
 		RepoName:     input.TSDBRepoName,
 		Region:       input.Region,
 		PandoraToken: input.CreateTSDBRepoToken,
@@ -1483,7 +1798,7 @@ func (c *Pipeline) CreateForTSDB(input *CreateRepoForTSDBInput) error {
 	if !ok {
 		seriesToken = models.PandoraToken{}
 	}
-	err = tsdbapi.CreateSeries(&tsdb.CreateSeriesInput{
+	err = tsdbapi.CreateSeriesWithContext(ctx, &tsdb.CreateSeriesInput{
 		RepoName:     input.TSDBRepoName,
 		SeriesName:   input.SeriesName,
 		Retention:    input.Retention,
@@ -1501,13 +1816,13 @@ func (c *Pipeline) CreateForTSDB(input *CreateRepoForTSDBInput) error {
 		createExportToken = models.PandoraToken{}
 	}
 	exportInput.PandoraToken = createExportToken
-	err = c.CreateExport(exportInput)
+	err = c.CreateExportWithContext(ctx, exportInput)
 	if err != nil && reqerr.IsExistError(err) {
 		updateExportToken, ok := input.AutoExportTSDBTokens.UpdateExportToken[exportInput.ExportName]
 		if !ok {
 			updateExportToken = models.PandoraToken{}
 		}
-		err = c.UpdateExport(&UpdateExportInput{
+		err = c.UpdateExportWithContext(ctx, &UpdateExportInput{
 			RepoName:     exportInput.RepoName,
 			ExportName:   exportInput.ExportName,
 			Spec:         exportInput.Spec,
@@ -1521,7 +1836,11 @@ func (c *Pipeline) CreateForTSDB(input *CreateRepoForTSDBInput) error {
 }
 
 func (c *Pipeline) CreateForMutiExportTSDB(input *CreateRepoForMutiExportTSDBInput) error {
-	_, err := c.GetRepo(&GetRepoInput{
+	return c.CreateForMutiExportTSDBWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) CreateForMutiExportTSDBWithContext(ctx context.Context, input *CreateRepoForMutiExportTSDBInput) error {
+	_, err := c.GetRepoWithContext(ctx, &GetRepoInput{
 		RepoName:     input.RepoName,
 		PandoraToken: input.PipelineGetRepoToken,
 	})
@@ -1535,7 +1854,7 @@ func (c *Pipeline) CreateForMutiExportTSDB(input *CreateRepoForMutiExportTSDBInp
 	if input.TSDBRepoName == "" {
 		input.TSDBRepoName = input.RepoName
 	}
-	err = tsdbapi.CreateRepo(&tsdb.CreateRepoInput{
+	err = tsdbapi.CreateRepoWithContext(ctx, &tsdb.CreateRepoInput{
 		RepoName:     input.TSDBRepoName,
 		Region:       input.Region,
 		PandoraToken: input.CreateTSDBRepoToken,
@@ -1550,7 +1869,7 @@ func (c *Pipeline) CreateForMutiExportTSDB(input *CreateRepoForMutiExportTSDBInp
 			seriesToken = models.PandoraToken{}
 		}
 
-		err = tsdbapi.CreateSeries(&tsdb.CreateSeriesInput{
+		err = tsdbapi.CreateSeriesWithContext(ctx, &tsdb.CreateSeriesInput{
 			RepoName:     input.TSDBRepoName,
 			SeriesName:   series.SeriesName,
 			Retention:    input.Retention,
@@ -1579,13 +1898,13 @@ func (c *Pipeline) CreateForMutiExportTSDB(input *CreateRepoForMutiExportTSDBInp
 			createExportToken = models.PandoraToken{}
 		}
 		exportInput.PandoraToken = createExportToken
-		err = c.CreateExport(exportInput)
+		err = c.CreateExportWithContext(ctx, exportInput)
 		if err != nil && reqerr.IsExistError(err) {
 			updateExportToken, ok := input.AutoExportTSDBTokens.UpdateExportToken[exportInput.ExportName]
 			if !ok {
 				updateExportToken = models.PandoraToken{}
 			}
-			err = c.UpdateExport(&UpdateExportInput{
+			err = c.UpdateExportWithContext(ctx, &UpdateExportInput{
 				RepoName:     exportInput.RepoName,
 				ExportName:   exportInput.ExportName,
 				Spec:         exportInput.Spec,
@@ -1604,9 +1923,13 @@ func (c *Pipeline) CreateForMutiExportTSDB(input *CreateRepoForMutiExportTSDBInp
 }
 
 func (c *Pipeline) UploadUdf(input *UploadUdfInput) (err error) {
+	return c.UploadUdfWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) UploadUdfWithContext(ctx context.Context, input *UploadUdfInput) (err error) {
 	op := c.NewOperation(base.OpUploadUdf, input.UdfName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	req.EnableContentMD5d()
 	req.SetBufferBody(input.Buffer.Bytes())
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJar)
@@ -1614,9 +1937,13 @@ func (c *Pipeline) UploadUdf(input *UploadUdfInput) (err error) {
 }
 
 func (c *Pipeline) UploadUdfFromFile(input *UploadUdfFromFileInput) (err error) {
+	return c.UploadUdfFromFileWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) UploadUdfFromFileWithContext(ctx context.Context, input *UploadUdfFromFileInput) (err error) {
 	op := c.NewOperation(base.OpUploadUdf, input.UdfName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	req.EnableContentMD5d()
 
 	file, err := os.Open(input.FilePath)
@@ -1630,9 +1957,13 @@ func (c *Pipeline) UploadUdfFromFile(input *UploadUdfFromFileInput) (err error)
 }
 
 func (c *Pipeline) PutUdfMeta(input *PutUdfMetaInput) (err error) {
+	return c.PutUdfMetaWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) PutUdfMetaWithContext(ctx context.Context, input *PutUdfMetaInput) (err error) {
 	op := c.NewOperation(base.OpPutUdfMeta, input.UdfName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -1641,9 +1972,13 @@ func (c *Pipeline) PutUdfMeta(input *PutUdfMetaInput) (err error) {
 }
 
 func (c *Pipeline) DeleteUdf(input *DeleteUdfInfoInput) (err error) {
+	return c.DeleteUdfWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) DeleteUdfWithContext(ctx context.Context, input *DeleteUdfInfoInput) (err error) {
 	op := c.NewOperation(base.OpDeleteUdf, input.UdfName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
@@ -1652,6 +1987,10 @@ const PageSize = "size"
 const PageSort = "sort"
 
 func (c *Pipeline) ListUdfs(input *ListUdfsInput) (output *ListUdfsOutput, err error) {
+	return c.ListUdfsWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) ListUdfsWithContext(ctx context.Context, input *ListUdfsInput) (output *ListUdfsOutput, err error) {
 	query := ""
 	values := url.Values{}
 	if input.From > 0 && input.Size > 0 {
@@ -1667,7 +2006,7 @@ func (c *Pipeline) ListUdfs(input *ListUdfsInput) (output *ListUdfsOutput, err e
 	op := c.NewOperation(base.OpListUdfs, query)
 
 	output = &ListUdfsOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
@@ -1675,9 +2014,13 @@ func (c *Pipeline) ListUdfs(input *ListUdfsInput) (output *ListUdfsOutput, err e
 }
 
 func (c *Pipeline) RegisterUdfFunction(input *RegisterUdfFunctionInput) (err error) {
+	return c.RegisterUdfFunctionWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) RegisterUdfFunctionWithContext(ctx context.Context, input *RegisterUdfFunctionInput) (err error) {
 	op := c.NewOperation(base.OpRegUdfFunc, input.FuncName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -1686,14 +2029,22 @@ func (c *Pipeline) RegisterUdfFunction(input *RegisterUdfFunctionInput) (err err
 }
 
 func (c *Pipeline) DeRegisterUdfFunction(input *DeregisterUdfFunctionInput) (err error) {
+	return c.DeRegisterUdfFunctionWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) DeRegisterUdfFunctionWithContext(ctx context.Context, input *DeregisterUdfFunctionInput) (err error) {
 	op := c.NewOperation(base.OpDeregUdfFunc, input.FuncName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	req.SetHeader(base.HTTPHeaderContentType, base.ContentTypeJson)
 	return req.Send()
 }
 
 func (c *Pipeline) ListUdfFunctions(input *ListUdfFunctionsInput) (output *ListUdfFunctionsOutput, err error) {
+	return c.ListUdfFunctionsWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) ListUdfFunctionsWithContext(ctx context.Context, input *ListUdfFunctionsInput) (output *ListUdfFunctionsOutput, err error) {
 	query := ""
 	values := url.Values{}
 	if input.From > 0 && input.Size > 0 {
@@ -1715,7 +2066,7 @@ func (c *Pipeline) ListUdfFunctions(input *ListUdfFunctionsInput) (output *ListU
 	op := c.NewOperation(base.OpListUdfFuncs, query)
 
 	output = &ListUdfFunctionsOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
@@ -1723,6 +2074,10 @@ func (c *Pipeline) ListUdfFunctions(input *ListUdfFunctionsInput) (output *ListU
 }
 
 func (c *Pipeline) ListBuiltinUdfFunctions(input *ListBuiltinUdfFunctionsInput) (output *ListUdfBuiltinFunctionsOutput, err error) {
+	return c.ListBuiltinUdfFunctionsWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) ListBuiltinUdfFunctionsWithContext(ctx context.Context, input *ListBuiltinUdfFunctionsInput) (output *ListUdfBuiltinFunctionsOutput, err error) {
 	query := ""
 	values := url.Values{}
 	if input.From > 0 && input.Size > 0 {
@@ -1741,14 +2096,18 @@ func (c *Pipeline) ListBuiltinUdfFunctions(input *ListBuiltinUdfFunctionsInput)
 	op := c.NewOperation(base.OpListUdfBuiltinFuncs, query)
 
 	output = &ListUdfBuiltinFunctionsOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) CreateWorkflow(input *CreateWorkflowInput) (err error) {
+	return c.CreateWorkflowWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) CreateWorkflowWithContext(ctx context.Context, input *CreateWorkflowInput) (err error) {
 	op := c.NewOperation(base.OpCreateWorkflow, input.WorkflowName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if input.Region == "" {
 		input.Region = c.defaultRegion
 	}
@@ -1760,9 +2119,13 @@ func (c *Pipeline) CreateWorkflow(input *CreateWorkflowInput) (err error) {
 }
 
 func (c *Pipeline) UpdateWorkflow(input *UpdateWorkflowInput) (err error) {
+	return c.UpdateWorkflowWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) UpdateWorkflowWithContext(ctx context.Context, input *UpdateWorkflowInput) (err error) {
 	op := c.NewOperation(base.OpUpdateWorkflow, input.WorkflowName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -1774,12 +2137,16 @@ func (c *Pipeline) UpdateWorkflow(input *UpdateWorkflowInput) (err error) {
 }
 
 func (c *Pipeline) GetWorkflow(input *GetWorkflowInput) (output *GetWorkflowOutput, err error) {
+	return c.GetWorkflowWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) GetWorkflowWithContext(ctx context.Context, input *GetWorkflowInput) (output *GetWorkflowOutput, err error) {
 	if err = input.Validate(); err != nil {
 		return
 	}
 	op := c.NewOperation(base.OpGetWorkflow, input.WorkflowName)
 	output = &GetWorkflowOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
@@ -1787,12 +2154,16 @@ func (c *Pipeline) GetWorkflow(input *GetWorkflowInput) (output *GetWorkflowOutp
 }
 
 func (c *Pipeline) GetWorkflowStatus(input *GetWorkflowStatusInput) (output *GetWorkflowStatusOutput, err error) {
+	return c.GetWorkflowStatusWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) GetWorkflowStatusWithContext(ctx context.Context, input *GetWorkflowStatusInput) (output *GetWorkflowStatusOutput, err error) {
 	if err = input.Validate(); err != nil {
 		return
 	}
 	op := c.NewOperation(base.OpGetWorkflowStatus, input.WorkflowName)
 	output = &GetWorkflowStatusOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
@@ -1800,9 +2171,13 @@ func (c *Pipeline) GetWorkflowStatus(input *GetWorkflowStatusInput) (output *Get
 }
 
 func (c *Pipeline) DeleteWorkflow(input *DeleteWorkflowInput) (err error) {
+	return c.DeleteWorkflowWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) DeleteWorkflowWithContext(ctx context.Context, input *DeleteWorkflowInput) (err error) {
 	op := c.NewOperation(base.OpDeleteWorkflow, input.WorkflowName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
@@ -1810,10 +2185,14 @@ func (c *Pipeline) DeleteWorkflow(input *DeleteWorkflowInput) (err error) {
 }
 
 func (c *Pipeline) ListWorkflows(input *ListWorkflowInput) (output *ListWorkflowOutput, err error) {
+	return c.ListWorkflowsWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) ListWorkflowsWithContext(ctx context.Context, input *ListWorkflowInput) (output *ListWorkflowOutput, err error) {
 	op := c.NewOperation(base.OpListWorkflows)
 
 	output = &ListWorkflowOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
@@ -1821,9 +2200,13 @@ func (c *Pipeline) ListWorkflows(input *ListWorkflowInput) (output *ListWorkflow
 }
 
 func (c *Pipeline) StopWorkflow(input *StopWorkflowInput) (err error) {
+	return c.StopWorkflowWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) StopWorkflowWithContext(ctx context.Context, input *StopWorkflowInput) (err error) {
 	op := c.NewOperation(base.OpStopWorkflow, input.WorkflowName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
@@ -1834,9 +2217,13 @@ func (c *Pipeline) StopWorkflow(input *StopWorkflowInput) (err error) {
 }
 
 func (c *Pipeline) StartWorkflow(input *StartWorkflowInput) (err error) {
+	return c.StartWorkflowWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) StartWorkflowWithContext(ctx context.Context, input *StartWorkflowInput) (err error) {
 	op := c.NewOperation(base.OpStartWorkflow, input.WorkflowName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
@@ -1847,10 +2234,14 @@ func (c *Pipeline) StartWorkflow(input *StartWorkflowInput) (err error) {
 }
 
 func (c *Pipeline) SearchWorkflow(input *DagLogSearchInput) (ret *WorkflowSearchRet, err error) {
+	return c.SearchWorkflowWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) SearchWorkflowWithContext(ctx context.Context, input *DagLogSearchInput) (ret *WorkflowSearchRet, err error) {
 	op := c.NewOperation(base.OpSearchDAGlog, input.WorkflowName)
 
 	ret = &WorkflowSearchRet{}
-	req := c.newRequest(op, input.Token, ret)
+	req := c.newRequest(ctx, op, input.Token, ret)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -1861,74 +2252,102 @@ func (c *Pipeline) SearchWorkflow(input *DagLogSearchInput) (ret *WorkflowSearch
 }
 
 func (c *Pipeline) RepoExist(input *RepoExistInput) (output *RepoExistOutput, err error) {
+	return c.RepoExistWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) RepoExistWithContext(ctx context.Context, input *RepoExistInput) (output *RepoExistOutput, err error) {
 	if err = input.Validate(); err != nil {
 		return
 	}
 	op := c.NewOperation(base.OpRepoExists, input.RepoName)
 
 	output = &RepoExistOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) TransformExist(input *TransformExistInput) (output *TransformExistOutput, err error) {
+	return c.TransformExistWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) TransformExistWithContext(ctx context.Context, input *TransformExistInput) (output *TransformExistOutput, err error) {
 	if err = input.Validate(); err != nil {
 		return
 	}
 	op := c.NewOperation(base.OpTransformExists, input.RepoName, input.TransformName)
 
 	output = &TransformExistOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 func (c *Pipeline) ExportExist(input *ExportExistInput) (output *ExportExistOutput, err error) {
+	return c.ExportExistWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) ExportExistWithContext(ctx context.Context, input *ExportExistInput) (output *ExportExistOutput, err error) {
 	if err = input.Validate(); err != nil {
 		return
 	}
 	op := c.NewOperation(base.OpExportExists, input.RepoName, input.ExportName)
 
 	output = &ExportExistOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) DatasourceExist(input *DatasourceExistInput) (output *DatasourceExistOutput, err error) {
+	return c.DatasourceExistWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) DatasourceExistWithContext(ctx context.Context, input *DatasourceExistInput) (output *DatasourceExistOutput, err error) {
 	if err = input.Validate(); err != nil {
 		return
 	}
 	op := c.NewOperation(base.OpDatasourceExists, input.DatasourceName)
 
 	output = &DatasourceExistOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) JobExist(input *JobExistInput) (output *JobExistOutput, err error) {
+	return c.JobExistWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) JobExistWithContext(ctx context.Context, input *JobExistInput) (output *JobExistOutput, err error) {
 	if err = input.Validate(); err != nil {
 		return
 	}
 	op := c.NewOperation(base.OpJobExists, input.JobName)
 
 	output = &JobExistOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) JobExportExist(input *JobExportExistInput) (output *JobExportExistOutput, err error) {
+	return c.JobExportExistWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) JobExportExistWithContext(ctx context.Context, input *JobExportExistInput) (output *JobExportExistOutput, err error) {
 	if err = input.Validate(); err != nil {
 		return
 	}
 	op := c.NewOperation(base.OpJobExportExists, input.JobName, input.ExportName)
 
 	output = &JobExportExistOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Pipeline) CreateVariable(input *CreateVariableInput) (err error) {
+	return c.CreateVariableWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) CreateVariableWithContext(ctx context.Context, input *CreateVariableInput) (err error) {
 	op := c.NewOperation(base.OpCreateVariable, input.Name)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -1936,9 +2355,13 @@ func (c *Pipeline) CreateVariable(input *CreateVariableInput) (err error) {
 }
 
 func (c *Pipeline) UpdateVariable(input *UpdateVariableInput) (err error) {
+	return c.UpdateVariableWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) UpdateVariableWithContext(ctx context.Context, input *UpdateVariableInput) (err error) {
 	op := c.NewOperation(base.OpUpdateVariable, input.Name)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -1946,9 +2369,13 @@ func (c *Pipeline) UpdateVariable(input *UpdateVariableInput) (err error) {
 }
 
 func (c *Pipeline) DeleteVariable(input *DeleteVariableInput) (err error) {
+	return c.DeleteVariableWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) DeleteVariableWithContext(ctx context.Context, input *DeleteVariableInput) (err error) {
 	op := c.NewOperation(base.OpDeleteVariable, input.Name)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -1956,13 +2383,17 @@ func (c *Pipeline) DeleteVariable(input *DeleteVariableInput) (err error) {
 }
 
 func (c *Pipeline) GetVariable(input *GetVariableInput) (output *GetVariableOutput, err error) {
+	return c.GetVariableWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) GetVariableWithContext(ctx context.Context, input *GetVariableInput) (output *GetVariableOutput, err error) {
 	if err = input.Validate(); err != nil {
 		return
 	}
 	op := c.NewOperation(base.OpGetVariable, input.Name)
 
 	output = &GetVariableOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
@@ -1970,10 +2401,14 @@ func (c *Pipeline) GetVariable(input *GetVariableInput) (output *GetVariableOutp
 }
 
 func (c *Pipeline) ListUserVariables(input *ListVariablesInput) (output *ListVariablesOutput, err error) {
+	return c.ListUserVariablesWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) ListUserVariablesWithContext(ctx context.Context, input *ListVariablesInput) (output *ListVariablesOutput, err error) {
 	op := c.NewOperation(base.OpListUserVariables, userVariableType)
 
 	output = &ListVariablesOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
@@ -1981,9 +2416,13 @@ func (c *Pipeline) ListUserVariables(input *ListVariablesInput) (output *ListVar
 }
 
 func (c *Pipeline) ListSystemVariables(input *ListVariablesInput) (output *ListVariablesOutput, err error) {
+	return c.ListSystemVariablesWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) ListSystemVariablesWithContext(ctx context.Context, input *ListVariablesInput) (output *ListVariablesOutput, err error) {
 	op := c.NewOperation(base.OpListSystemVariables, systemVariableType)
 	output = &ListVariablesOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	if input.ResourceOwner != "" {
 		req.SetHeader(base.HTTPHeaderResourceOwner, input.ResourceOwner)
 	}
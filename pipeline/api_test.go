@@ -2,9 +2,14 @@ package pipeline
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/qiniu/pandora-go-sdk/base"
 	"github.com/qiniu/pandora-go-sdk/base/config"
 	"github.com/stretchr/testify/assert"
 )
@@ -35,7 +40,7 @@ func TestUnpack(t *testing.T) {
 	for i := 0; i < 3; i++ {
 		datas = append(datas, d)
 	}
-	contexts, err := client.unpack(&SchemaFreeInput{
+	contexts, _, err := client.unpack(context.Background(), &SchemaFreeInput{
 		RepoName: repoName,
 		Datas:    Datas(datas),
 		NoUpdate: true,
@@ -52,7 +57,7 @@ func TestUnpack(t *testing.T) {
 	for i := 0; i < 2*1024*102; i++ {
 		datas = append(datas, d)
 	}
-	contexts, err = client.unpack(&SchemaFreeInput{
+	contexts, _, err = client.unpack(context.Background(), &SchemaFreeInput{
 		RepoName: repoName,
 		Datas:    Datas(datas),
 		NoUpdate: true,
@@ -68,7 +73,7 @@ func TestUnpack(t *testing.T) {
 	for i := 0; i < 2*1024*103; i++ {
 		datas = append(datas, d)
 	}
-	contexts, err = client.unpack(&SchemaFreeInput{
+	contexts, _, err = client.unpack(context.Background(), &SchemaFreeInput{
 		RepoName: repoName,
 		Datas:    Datas(datas),
 		NoUpdate: true,
@@ -99,3 +104,76 @@ func TestBuff(t *testing.T) {
 	xxxxx678 12345678
 	*/
 }
+
+func TestMakeSchemaFreeToken(t *testing.T) {
+	client, err := NewDefaultClient(NewConfig().WithAccessKeySecretKey("ak", "sk"))
+	assert.NoError(t, err)
+	m := base.NewTokenManager(client.MakeToken)
+	tokens, err := client.MakeSchemaFreeToken(m, "repo", "workflow")
+	assert.NoError(t, err)
+	again, err := client.MakeSchemaFreeToken(m, "repo", "workflow")
+	assert.NoError(t, err)
+	assert.Equal(t, tokens, again)
+
+	seen := map[string]bool{}
+	for _, tk := range []string{
+		tokens.PipelineCreateRepoToken.Token, tokens.PipelinePostDataToken.Token, tokens.PipelineGetRepoToken.Token,
+		tokens.PipelineUpdateRepoToken.Token, tokens.PipelineGetWorkflowToken.Token, tokens.PipelineCreateWorkflowToken.Token,
+		tokens.PipelineStartWorkflowToken.Token, tokens.PipelineStopWorkflowToken.Token, tokens.PipelineGetWorkflowStatusToken.Token,
+	} {
+		assert.True(t, strings.HasPrefix(tk, "Pandora ak:"))
+		seen[tk] = true
+	}
+	assert.Len(t, seen, 9)
+}
+
+func TestRepoRegionRouting(t *testing.T) {
+	var got []string
+	newServer := func(region string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = append(got, region+" "+r.Method+" "+r.URL.Path)
+			if r.Method == base.MethodGet {
+				w.Header().Set(base.HTTPHeaderContentType, base.ContentTypeJson)
+				w.Write([]byte(`{"region":"hd"}`))
+			}
+		}))
+	}
+	nb, hd := newServer("nb"), newServer("hd")
+	defer nb.Close()
+	defer hd.Close()
+
+	endpoints := config.NewEndpoints().WithRegion("nb", nb.URL).WithRegion("hd", hd.URL)
+	cfg := NewConfig().
+		WithPipelineEndpoint(nb.URL).
+		WithAccessKeySecretKey("ak", "sk").
+		WithServiceEndpoints(config.TypePipeline, endpoints)
+	cfg.DefaultRegion = "nb"
+	client, err := NewDefaultClient(cfg)
+	assert.NoError(t, err)
+
+	post := func(repo string) {
+		assert.NoError(t, client.PostData(&PostDataInput{RepoName: repo, Points: Points{{Fields: []PointField{{Key: "a", Value: 1}}}}}))
+	}
+	// repo 的 region 未知时使用 DefaultRegion
+	post("repo1")
+	_, err = client.GetRepo(&GetRepoInput{RepoName: "repo1"})
+	assert.NoError(t, err)
+	post("repo1")
+	assert.NoError(t, client.CreateRepo(&CreateRepoInput{
+		RepoName: "repo2",
+		Region:   "hd",
+		Schema:   []RepoSchemaEntry{{Key: "a", ValueType: PandoraTypeLong}},
+	}))
+	post("repo2")
+	client.SetRepoRegion("repo3", "nb")
+	post("repo3")
+
+	assert.Equal(t, []string{
+		"nb POST /v2/repos/repo1/data",
+		"nb GET /v2/repos/repo1",
+		"hd POST /v2/repos/repo1/data",
+		"hd POST /v2/repos/repo2",
+		"hd POST /v2/repos/repo2/data",
+		"nb POST /v2/repos/repo3/data",
+	}, got)
+}
@@ -0,0 +1,250 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/qiniu/pandora-go-sdk/base/reqerr"
+	"github.com/qiniu/x/log"
+)
+
+// TypeConflictStrategy 是 schema free 写入时字段类型与 repo schema 不一致的处理策略
+type TypeConflictStrategy string
+
+const (
+	// TypeConflictFail 不处理类型冲突，与之前的行为相同：数据交给服务端校验，合并 schema 时冲突则整批数据失败
+	TypeConflictFail TypeConflictStrategy = ""
+	// TypeConflictCoerce 把值转换为 schema 中的类型，无法转换时丢弃该字段
+	TypeConflictCoerce TypeConflictStrategy = "coerce"
+	// TypeConflictRename 把字段改名为 <字段名>_<数据类型>，如 field_string，NoUpdate 时无法增加字段，丢弃该字段
+	TypeConflictRename TypeConflictStrategy = "rename"
+	// TypeConflictDeadLetter 把整条数据写入 SchemaFreeOption.DeadLetterRepo，必须设置 DeadLetterRepo
+	TypeConflictDeadLetter TypeConflictStrategy = "dead_letter"
+	// TypeConflictDrop 丢弃该字段
+	TypeConflictDrop TypeConflictStrategy = "drop"
+)
+
+// 写入死信 repo 的数据的字段
+const (
+	DeadLetterSourceRepo  = "source_repo"
+	DeadLetterConflictKey = "conflict_key"
+	DeadLetterRawData     = "raw_data"
+)
+
+// TypeConflictStats 记录每种策略处理类型冲突的次数
+type TypeConflictStats struct {
+	Coerced      int64
+	Renamed      int64
+	DeadLettered int64
+	Dropped      int64
+}
+
+func (o *SchemaFreeOption) typeConflictStrategy() TypeConflictStrategy {
+	if o == nil {
+		return TypeConflictFail
+	}
+	return o.TypeConflict
+}
+
+// TypeConflictStats 返回 repo 的类型冲突计数
+func (c *Pipeline) TypeConflictStats(repoName string) TypeConflictStats {
+	s := c.typeConflictStats(repoName)
+	return TypeConflictStats{
+		Coerced:      atomic.LoadInt64(&s.Coerced),
+		Renamed:      atomic.LoadInt64(&s.Renamed),
+		DeadLettered: atomic.LoadInt64(&s.DeadLettered),
+		Dropped:      atomic.LoadInt64(&s.Dropped),
+	}
+}
+
+// validateTypeConflict 检查类型冲突策略需要的配置
+func (o *SchemaFreeOption) validateTypeConflict() error {
+	if o.typeConflictStrategy() == TypeConflictDeadLetter && o.DeadLetterRepo == "" {
+		return reqerr.NewInvalidArgs("DeadLetterRepo", "dead letter repo is required when TypeConflict is dead_letter").WithComponent("pipleline")
+	}
+	return nil
+}
+
+func (c *Pipeline) typeConflictStats(repoName string) *TypeConflictStats {
+	c.conflictStatsMux.Lock()
+	defer c.conflictStatsMux.Unlock()
+	if c.conflictStats == nil {
+		c.conflictStats = make(map[string]*TypeConflictStats)
+	}
+	s, ok := c.conflictStats[repoName]
+	if !ok {
+		s = &TypeConflictStats{}
+		c.conflictStats[repoName] = s
+	}
+	return s
+}
+
+// resolveTypeConflict 按照策略处理字段 name 的类型冲突。keep 为 true 时以 newValue 写入原字段；
+// 否则字段已经从 data 中移除或改名；deadLetter 为 true 表示整条数据需要写入死信 repo
+func (c *Pipeline) resolveTypeConflict(input *SchemaFreeInput, data Data, schemas map[string]RepoSchemaEntry,
+	name string, value interface{}, schema RepoSchemaEntry) (newValue interface{}, keep, deadLetter bool) {
+	stats := c.typeConflictStats(input.RepoName)
+	inferer := input.Option.schemaInferer()
+	switch input.Option.typeConflictStrategy() {
+	case TypeConflictCoerce:
+		if nv, err := dataConvert(value, schema); err == nil && !inferer.isTypeConflict(nv, schema) {
+			atomic.AddInt64(&stats.Coerced, 1)
+			return nv, true, false
+		}
+	case TypeConflictRename:
+		key := name + "_" + dataType(value, input.Option)
+		if _, exist := data[key]; exist {
+			break
+		}
+		sc, ok := schemas[key]
+		if (ok && !inferer.isTypeConflict(value, sc)) || (!ok && !input.NoUpdate) {
+			delete(data, name)
+			data[key] = value
+			atomic.AddInt64(&stats.Renamed, 1)
+			return nil, false, false
+		}
+	case TypeConflictDeadLetter:
+		atomic.AddInt64(&stats.DeadLettered, 1)
+		return nil, false, true
+	}
+	delete(data, name)
+	atomic.AddInt64(&stats.Dropped, 1)
+	log.Debugf("repo %s drop key %s value %v as type conflict with schema %v", input.RepoName, name, value, schema)
+	return nil, false, false
+}
+
+type deadLetter struct {
+	key  string
+	data Data
+}
+
+// postDeadLetters 以 DeadLetterToken 把存在类型冲突的数据写入 DeadLetterRepo，原始数据以 JSON 格式保存在 raw_data 字段中
+func (c *Pipeline) postDeadLetters(ctx context.Context, input *SchemaFreeInput, deadLetters []deadLetter) error {
+	datas := make(Datas, 0, len(deadLetters))
+	for _, d := range deadLetters {
+		raw, err := json.Marshal(d.data)
+		if err != nil {
+			return err
+		}
+		datas = append(datas, Data{
+			DeadLetterSourceRepo:  input.RepoName,
+			DeadLetterConflictKey: d.key,
+			DeadLetterRawData:     string(raw),
+		})
+	}
+	_, err := c.PostDataSchemaFreeWithContext(ctx, &SchemaFreeInput{
+		RepoName:        input.Option.DeadLetterRepo,
+		Datas:           datas,
+		SchemaFreeToken: input.Option.DeadLetterToken,
+		WorkflowName:    input.WorkflowName,
+		Region:          input.Region,
+	})
+	return err
+}
+
+// dataType 返回 schema free 推断出的 value 的类型
+func dataType(value interface{}, option *SchemaFreeOption) string {
+	vt := option.schemaInferer().Infer(Data{"v": value})
+	option.useFloat(vt)
+	if sc, ok := vt["v"]; ok {
+		return sc.ValueType
+	}
+	return PandoraTypeString
+}
+
+// isTypeConflict 判断 value 是否无法作为 schema 类型写入，string、jsonstring 类型的字段可以写入任意值，map 类型的字段会检查其中已有的字段，
+// date 类型的字段可以写入满足 DateLayouts 中格式的字符串，写入前由 normalizeDates 转换为 RFC3339 格式
+func (i *SchemaInferer) isTypeConflict(value interface{}, schema RepoSchemaEntry) bool {
+	if value == nil {
+		return false
+	}
+	switch schema.ValueType {
+	case PandoraTypeArray:
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return true
+		}
+		for j := 0; j < rv.Len(); j++ {
+			if i.isTypeConflict(rv.Index(j).Interface(), RepoSchemaEntry{ValueType: schema.ElemType}) {
+				return true
+			}
+		}
+		return false
+	case PandoraTypeMap:
+		var m map[string]interface{}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			m = v
+		case Data:
+			m = v
+		default:
+			return true
+		}
+		for _, sc := range schema.Schema {
+			if i.isTypeConflict(m[sc.Key], sc) {
+				return true
+			}
+		}
+		return false
+	case PandoraTypeLong:
+		switch v := value.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return false
+		case float32:
+			return float64(v) != float64(int64(v))
+		case float64:
+			return v != float64(int64(v))
+		case json.Number:
+			_, err := v.Int64()
+			return err != nil
+		case string:
+			_, err := strconv.ParseInt(v, 10, 64)
+			return err != nil
+		}
+		return true
+	case PandoraTypeFloat:
+		switch v := value.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+			return false
+		case string:
+			_, err := strconv.ParseFloat(v, 64)
+			return err != nil
+		}
+		return true
+	case PandoraTypeBool:
+		switch v := value.(type) {
+		case bool:
+			return false
+		case string:
+			_, err := strconv.ParseBool(v)
+			return err != nil
+		}
+		return true
+	case PandoraTypeDate:
+		switch v := value.(type) {
+		case time.Time, *time.Time:
+			return false
+		case string:
+			if _, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return false
+			}
+			_, ok := i.parseDate(v)
+			return !ok
+		}
+		return true
+	case PandoraTypeIP:
+		switch v := value.(type) {
+		case net.IP:
+			return false
+		case string:
+			return net.ParseIP(v) == nil
+		}
+		return true
+	}
+	return false
+}
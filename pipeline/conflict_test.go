@@ -0,0 +1,196 @@
+package pipeline
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/qiniu/pandora-go-sdk/base/reqerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func pointValues(p Point) map[string]interface{} {
+	ret := make(map[string]interface{})
+	for _, f := range p.Fields {
+		ret[f.Key] = f.Value
+	}
+	return ret
+}
+
+func TestTypeConflictStrategies(t *testing.T) {
+	client, err := NewDefaultClient(NewConfig())
+	assert.NoError(t, err)
+	client.repoSchemas["repo"] = RepoSchema{
+		"n":  {Key: "n", ValueType: PandoraTypeLong},
+		"ok": {Key: "ok", ValueType: PandoraTypeBool},
+		"m": {Key: "m", ValueType: PandoraTypeMap, Schema: []RepoSchemaEntry{
+			{Key: "ip", ValueType: PandoraTypeIP},
+		}},
+	}
+	input := &SchemaFreeInput{RepoName: "repo", Option: &SchemaFreeOption{}}
+
+	// 默认不处理冲突
+	point, _, deadLetterKey, err := client.generatePoint(context.Background(), Data{"n": "abc"}, input)
+	assert.NoError(t, err)
+	assert.Empty(t, deadLetterKey)
+	assert.Equal(t, map[string]interface{}{"n": "abc"}, pointValues(point))
+
+	input.Option.TypeConflict = TypeConflictCoerce
+	point, _, _, err = client.generatePoint(context.Background(), Data{"n": "12.0", "ok": "yes"}, input)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"n": int64(12)}, pointValues(point))
+
+	input.Option.TypeConflict = TypeConflictRename
+	point, _, _, err = client.generatePoint(context.Background(), Data{"n": "abc", "m": map[string]interface{}{"ip": 1.5}}, input)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"n_string": "abc", "m_jsonstring": map[string]interface{}{"ip": 1.5}}, pointValues(point))
+	assert.Equal(t, PandoraTypeString, client.repoSchemas["repo"]["n_string"].ValueType)
+
+	// 不能更新 schema 时改名后的字段已经存在则写入，否则丢弃
+	input.NoUpdate = true
+	point, _, _, err = client.generatePoint(context.Background(), Data{"n": "def", "ok": 1}, input)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"n_string": "def"}, pointValues(point))
+	input.NoUpdate = false
+
+	input.Option.TypeConflict = TypeConflictDrop
+	point, _, _, err = client.generatePoint(context.Background(), Data{"n": 1.5, "ok": true}, input)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"ok": true}, pointValues(point))
+
+	input.Option.TypeConflict = TypeConflictDeadLetter
+	input.Option.DeadLetterRepo = "dead"
+	point, _, deadLetterKey, err = client.generatePoint(context.Background(), Data{"n": "abc", "ok": true}, input)
+	assert.NoError(t, err)
+	assert.Equal(t, "n", deadLetterKey)
+	assert.Empty(t, point.Fields)
+
+	assert.Equal(t, TypeConflictStats{Coerced: 1, Renamed: 3, DeadLettered: 1, Dropped: 3}, client.TypeConflictStats("repo"))
+	assert.Equal(t, TypeConflictStats{}, client.TypeConflictStats("other"))
+}
+
+func TestIsTypeConflict(t *testing.T) {
+	assert.False(t, defaultInferer.isTypeConflict(int64(1), RepoSchemaEntry{ValueType: PandoraTypeLong}))
+	assert.False(t, defaultInferer.isTypeConflict(2.0, RepoSchemaEntry{ValueType: PandoraTypeLong}))
+	assert.True(t, defaultInferer.isTypeConflict(2.5, RepoSchemaEntry{ValueType: PandoraTypeLong}))
+	assert.False(t, defaultInferer.isTypeConflict("2.5", RepoSchemaEntry{ValueType: PandoraTypeFloat}))
+	assert.False(t, defaultInferer.isTypeConflict(map[string]interface{}{}, RepoSchemaEntry{ValueType: PandoraTypeString}))
+	assert.False(t, defaultInferer.isTypeConflict("2018-01-02T03:04:05Z", RepoSchemaEntry{ValueType: PandoraTypeDate}))
+	assert.True(t, defaultInferer.isTypeConflict("yesterday", RepoSchemaEntry{ValueType: PandoraTypeDate}))
+	assert.False(t, defaultInferer.isTypeConflict("::1", RepoSchemaEntry{ValueType: PandoraTypeIP}))
+	assert.True(t, defaultInferer.isTypeConflict("host", RepoSchemaEntry{ValueType: PandoraTypeIP}))
+	assert.False(t, defaultInferer.isTypeConflict([]interface{}{1, 2}, RepoSchemaEntry{ValueType: PandoraTypeArray, ElemType: PandoraTypeLong}))
+	assert.True(t, defaultInferer.isTypeConflict([]interface{}{1, "a"}, RepoSchemaEntry{ValueType: PandoraTypeArray, ElemType: PandoraTypeLong}))
+	assert.True(t, defaultInferer.isTypeConflict("a", RepoSchemaEntry{ValueType: PandoraTypeMap}))
+
+	inferer := NewSchemaInferer().WithDateLayouts("2006-01-02 15:04:05")
+	assert.False(t, inferer.isTypeConflict("2018-01-02 03:04:05", RepoSchemaEntry{ValueType: PandoraTypeDate}))
+	assert.False(t, inferer.isTypeConflict("2018-01-02T03:04:05Z", RepoSchemaEntry{ValueType: PandoraTypeDate}))
+	assert.True(t, defaultInferer.isTypeConflict("2018-01-02 03:04:05", RepoSchemaEntry{ValueType: PandoraTypeDate}))
+}
+
+func TestTypeConflictDateLayouts(t *testing.T) {
+	client, err := NewDefaultClient(NewConfig())
+	assert.NoError(t, err)
+	client.repoSchemas["repo"] = RepoSchema{
+		"n":      {Key: "n", ValueType: PandoraTypeLong},
+		"n_date": {Key: "n_date", ValueType: PandoraTypeDate},
+		"ts":     {Key: "ts", ValueType: PandoraTypeDate},
+		"m": {Key: "m", ValueType: PandoraTypeMap, Schema: []RepoSchemaEntry{
+			{Key: "ts", ValueType: PandoraTypeDate},
+		}},
+	}
+	input := &SchemaFreeInput{RepoName: "repo", Option: &SchemaFreeOption{
+		TypeConflict: TypeConflictCoerce,
+		Inferer:      NewSchemaInferer().WithDateLayouts("2006-01-02 15:04:05"),
+	}}
+
+	// 满足 DateLayouts 的时间不是类型冲突，转换为 RFC3339 格式后写入
+	point, _, _, err := client.generatePoint(context.Background(), Data{
+		"n":  "12.0",
+		"ts": "2018-01-02 03:04:05",
+		"m":  map[string]interface{}{"ts": "2018-01-02 03:04:06"},
+	}, input)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"n":  int64(12),
+		"ts": "2018-01-02T03:04:05Z",
+		"m":  map[string]interface{}{"ts": "2018-01-02T03:04:06Z"},
+	}, pointValues(point))
+
+	// 改名后的字段同样转换时间格式
+	input.Option.TypeConflict = TypeConflictRename
+	point, _, _, err = client.generatePoint(context.Background(), Data{"n": "2018-01-02 03:04:05"}, input)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"n_date": "2018-01-02T03:04:05Z"}, pointValues(point))
+	assert.Equal(t, TypeConflictStats{Coerced: 1, Renamed: 1}, client.TypeConflictStats("repo"))
+}
+
+func TestTypeConflictDeadLetterRepo(t *testing.T) {
+	var mu sync.Mutex
+	bodies := map[string][]string{}
+	auths := map[string]string{}
+	failRepo := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		bodies[r.URL.Path] = append(bodies[r.URL.Path], string(body))
+		auths[r.URL.Path] = r.Header.Get("Authorization")
+		if failRepo && r.URL.Path == "/v2/repos/repo/data" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"bad request"}`))
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewDefaultClient(NewConfig().WithPipelineEndpoint(srv.URL).WithAccessKeySecretKey("ak", "sk"))
+	assert.NoError(t, err)
+	client.repoSchemas["repo"] = RepoSchema{"n": {Key: "n", ValueType: PandoraTypeLong}}
+	client.repoSchemas["dead"] = RepoSchema{
+		DeadLetterSourceRepo:  {Key: DeadLetterSourceRepo, ValueType: PandoraTypeString},
+		DeadLetterConflictKey: {Key: DeadLetterConflictKey, ValueType: PandoraTypeString},
+		DeadLetterRawData:     {Key: DeadLetterRawData, ValueType: PandoraTypeString},
+	}
+	input := &SchemaFreeInput{
+		RepoName: "repo",
+		Datas:    Datas{{"n": 1}, {"n": "abc"}},
+		Option:   &SchemaFreeOption{TypeConflict: TypeConflictDeadLetter},
+	}
+	input.PipelinePostDataToken.Token = "repo token"
+
+	// 未设置 DeadLetterRepo 时直接返回错误，不写入任何数据
+	_, err = client.PostDataSchemaFree(input)
+	reqErr, ok := err.(*reqerr.RequestError)
+	assert.True(t, ok, "%v", err)
+	assert.Equal(t, reqerr.InvalidArgs, reqErr.ErrorType)
+	assert.Empty(t, bodies)
+
+	input.Option.DeadLetterRepo = "dead"
+	input.Option.DeadLetterToken.PipelinePostDataToken.Token = "dead token"
+	_, err = client.PostDataSchemaFree(input)
+	assert.NoError(t, err)
+	assert.Equal(t, "repo token", auths["/v2/repos/repo/data"])
+	assert.Equal(t, "dead token", auths["/v2/repos/dead/data"])
+	assert.Equal(t, []string{"n=1\n"}, bodies["/v2/repos/repo/data"])
+	assert.Len(t, bodies["/v2/repos/dead/data"], 1)
+	dead := bodies["/v2/repos/dead/data"][0]
+	assert.True(t, strings.Contains(dead, "source_repo=repo"), dead)
+	assert.True(t, strings.Contains(dead, "conflict_key=n"), dead)
+	assert.True(t, strings.Contains(dead, `raw_data={"n":"abc"}`), dead)
+	assert.Equal(t, int64(1), client.TypeConflictStats("repo").DeadLettered)
+
+	// 原 repo 写入失败时，已经写入死信 repo 的数据不在失败的数据中，避免重发时重复写入死信 repo
+	failRepo = true
+	input.Datas = Datas{{"n": "abc"}, {"n": 1}}
+	_, err = client.PostDataSchemaFree(input)
+	sendErr, ok := err.(*reqerr.SendError)
+	assert.True(t, ok, "%v", err)
+	assert.Equal(t, []map[string]interface{}{{"n": 1}}, sendErr.GetFailDatas())
+	assert.Len(t, bodies["/v2/repos/dead/data"], 2)
+	assert.Equal(t, int64(2), client.TypeConflictStats("repo").DeadLettered)
+}
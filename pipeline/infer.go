@@ -0,0 +1,323 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/qiniu/pandora-go-sdk/base"
+	"github.com/qiniu/x/log"
+)
+
+// SchemaInferer 推断 schema free 数据中字段的类型
+type SchemaInferer struct {
+	// DateLayouts 是推断为 PandoraTypeDate 的字符串格式，按顺序尝试，默认只有 time.RFC3339
+	DateLayouts []string
+	// DetectIP 为 true 时满足 IPv4、IPv6 格式的字符串推断为 PandoraTypeIP
+	DetectIP bool
+	// InferMap 为 true 时嵌套的对象推断为 PandoraTypeMap，否则推断为 PandoraTypeJsonString
+	InferMap bool
+	// MaxDepth 是推断为 PandoraTypeMap 的最大嵌套层数，超过的部分推断为 PandoraTypeJsonString，默认为 base.NestLimit
+	MaxDepth int
+	// SampleSize 大于 0 时，schema free 写入前先根据前 SampleSize 条数据推断新字段的类型，
+	// 避免同一字段在不同数据中类型不同(如 long 与 float)时以第一条数据的类型为准
+	SampleSize int
+}
+
+// defaultInferer 与之前 GetTrimedDataSchema 的行为相同
+var defaultInferer = NewSchemaInferer()
+
+func NewSchemaInferer() *SchemaInferer {
+	return &SchemaInferer{
+		DateLayouts: []string{time.RFC3339},
+		MaxDepth:    base.NestLimit,
+	}
+}
+
+func (i *SchemaInferer) WithDateLayouts(layouts ...string) *SchemaInferer {
+	i.DateLayouts = layouts
+	return i
+}
+
+func (i *SchemaInferer) WithDetectIP(detect bool) *SchemaInferer {
+	i.DetectIP = detect
+	return i
+}
+
+func (i *SchemaInferer) WithInferMap(infer bool, maxDepth int) *SchemaInferer {
+	i.InferMap = infer
+	i.MaxDepth = maxDepth
+	return i
+}
+
+func (i *SchemaInferer) WithSampleSize(n int) *SchemaInferer {
+	i.SampleSize = n
+	return i
+}
+
+func (o *SchemaFreeOption) schemaInferer() *SchemaInferer {
+	if o == nil || o.Inferer == nil {
+		return defaultInferer
+	}
+	return o.Inferer
+}
+
+// useFloat 在 NumberUseFloat 或 IsMetric 时将推断出的 long 都改成 float
+func (o *SchemaFreeOption) useFloat(valueType map[string]RepoSchemaEntry) {
+	if o == nil || !(o.NumberUseFloat || o.IsMetric) {
+		return
+	}
+	for key, val := range valueType {
+		valueType[key] = changeElemType(val, PandoraTypeLong, PandoraTypeFloat)
+	}
+}
+
+func (i *SchemaInferer) maxDepth() int {
+	if i.MaxDepth <= 0 || i.MaxDepth > base.NestLimit {
+		return base.NestLimit
+	}
+	return i.MaxDepth
+}
+
+// Infer 获取 data 中所有字段的 schema，同时将 data 中值为 nil、无法判断类型的键值对删掉
+func (i *SchemaInferer) Infer(data Data) map[string]RepoSchemaEntry {
+	return i.infer(data, 0)
+}
+
+// InferDatas 根据 datas 中前 SampleSize 条数据(SampleSize 不大于 0 时为全部数据)推断 schema，
+// 同一字段在不同数据中的类型按照 unifyType 的规则合并，不会修改 datas
+func (i *SchemaInferer) InferDatas(datas Datas) map[string]RepoSchemaEntry {
+	if i.SampleSize > 0 && len(datas) > i.SampleSize {
+		datas = datas[:i.SampleSize]
+	}
+	var ret map[string]RepoSchemaEntry
+	for _, d := range datas {
+		ret = unifySchemas(ret, i.infer(copyAndConvertData(d, 1), 0))
+	}
+	return ret
+}
+
+func (i *SchemaInferer) infer(data Data, depth int) (valueType map[string]RepoSchemaEntry) {
+	valueType = make(map[string]RepoSchemaEntry)
+	for k, v := range data {
+		switch nv := v.(type) {
+		case map[string]interface{}:
+			follows := i.infer(Data(nv), depth+1)
+			if len(follows) == 0 {
+				// 由于内层数据为空，所以从数据中将该条键值对删掉
+				delete(data, k)
+				continue
+			}
+			if i.InferMap && depth+1 <= i.maxDepth() {
+				sc := formValueType(k, PandoraTypeMap)
+				for _, f := range follows {
+					sc.Schema = append(sc.Schema, f)
+				}
+				sortSchemas(sc.Schema)
+				valueType[k] = sc
+				continue
+			}
+			valueType[k] = formValueType(k, PandoraTypeJsonString)
+		case map[string]string:
+			isEmpty := true
+			for _, nvVal := range nv {
+				if nvVal != "" {
+					isEmpty = false
+					break
+				}
+			}
+			if isEmpty {
+				continue
+			}
+			valueType[k] = formValueType(k, PandoraTypeJsonString)
+		case []interface{}:
+			elemType, ok := arrayElemType(nv)
+			if !ok {
+				// 由于数据为空，且无法判断类型, 所以从数据中将该条键值对删掉
+				delete(data, k)
+				continue
+			}
+			sc := formValueType(k, PandoraTypeArray)
+			sc.ElemType = elemType
+			valueType[k] = sc
+		case nil:
+			// 由于数据为空，且无法判断类型, 所以从数据中将该条键值对删掉
+			delete(data, k)
+		case string:
+			valueType[k] = formValueType(k, i.stringType(nv))
+		default:
+			vt := scalarType(v)
+			if vt == "" {
+				vt = PandoraTypeString
+				log.Warnf("find undetected key(%v)-type(%v), read it as string", k, reflect.TypeOf(v))
+			}
+			sc := formValueType(k, vt)
+			if vt == PandoraTypeArray {
+				sc.ElemType = typedSliceElemType(v)
+			}
+			valueType[k] = sc
+		}
+	}
+	return
+}
+
+// stringType 推断字符串的类型：满足 DateLayouts 中格式的为 PandoraTypeDate，DetectIP 时满足 IP 格式的为 PandoraTypeIP
+func (i *SchemaInferer) stringType(s string) string {
+	if _, ok := i.parseDate(s); ok {
+		return PandoraTypeDate
+	}
+	if i.DetectIP && net.ParseIP(s) != nil {
+		return PandoraTypeIP
+	}
+	return PandoraTypeString
+}
+
+func (i *SchemaInferer) parseDate(s string) (time.Time, bool) {
+	for _, layout := range i.DateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// normalizeDates 把 data 中 date 类型、非 RFC3339 格式的字符串转换为 RFC3339 格式，pandora 只接受 RFC3339 格式的时间
+func (i *SchemaInferer) normalizeDates(data map[string]interface{}, schemas map[string]RepoSchemaEntry) {
+	for k, sc := range schemas {
+		switch v := data[k].(type) {
+		case string:
+			if sc.ValueType != PandoraTypeDate {
+				continue
+			}
+			if _, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				continue
+			}
+			if t, ok := i.parseDate(v); ok {
+				data[k] = t.Format(time.RFC3339Nano)
+			}
+		case map[string]interface{}:
+			if sc.ValueType != PandoraTypeMap {
+				continue
+			}
+			nested := make(map[string]RepoSchemaEntry, len(sc.Schema))
+			for _, s := range sc.Schema {
+				nested[s.Key] = s
+			}
+			i.normalizeDates(v, nested)
+		}
+	}
+}
+
+// scalarType 返回数字、bool、时间以及基本类型 slice 的类型，其他类型返回空字符串
+func scalarType(v interface{}) string {
+	switch nv := v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return PandoraTypeLong
+	case float32, float64:
+		return PandoraTypeFloat
+	case bool:
+		return PandoraTypeBool
+	case json.Number:
+		if _, err := nv.Int64(); err == nil {
+			return PandoraTypeLong
+		}
+		return PandoraTypeFloat
+	case time.Time, *time.Time:
+		return PandoraTypeDate
+	case []int, []int8, []int16, []int32, []int64, []uint, []uint8, []uint16, []uint32, []uint64,
+		[]float32, []float64, []bool, []string, []json.Number:
+		return PandoraTypeArray
+	}
+	return ""
+}
+
+func typedSliceElemType(v interface{}) string {
+	switch v.(type) {
+	case []int, []int8, []int16, []int32, []int64, []uint, []uint8, []uint16, []uint32, []uint64:
+		return PandoraTypeLong
+	case []float32, []float64, []json.Number:
+		return PandoraTypeFloat
+	case []bool:
+		return PandoraTypeBool
+	}
+	return PandoraTypeString
+}
+
+// arrayElemType 根据数组中所有元素推断元素类型，long 与 float 合并为 float，其他不同的类型合并为 string，
+// nil 无法写入数字与 bool 类型的数组，按 string 处理。数组为空或只有一个 nil 元素时无法判断类型，ok 为 false
+func arrayElemType(arr []interface{}) (elemType string, ok bool) {
+	if len(arr) == 0 || (len(arr) == 1 && arr[0] == nil) {
+		return "", false
+	}
+	for _, e := range arr {
+		t := scalarType(e)
+		if t == "" || t == PandoraTypeDate || t == PandoraTypeArray {
+			t = PandoraTypeString
+		}
+		elemType = unifyType(elemType, t)
+	}
+	return elemType, true
+}
+
+// unifyType 合并同一个值的两种类型：long 与 float 合并为 float，其他不同的类型合并为 string
+func unifyType(a, b string) string {
+	switch {
+	case a == "" || a == b:
+		return b
+	case b == "":
+		return a
+	case (a == PandoraTypeLong && b == PandoraTypeFloat) || (a == PandoraTypeFloat && b == PandoraTypeLong):
+		return PandoraTypeFloat
+	}
+	return PandoraTypeString
+}
+
+// unifySchemas 合并两条数据推断出的 schema，map 类型的字段递归合并，map 与其他类型合并为 jsonstring
+func unifySchemas(a, b map[string]RepoSchemaEntry) map[string]RepoSchemaEntry {
+	if a == nil {
+		return b
+	}
+	for k, sb := range b {
+		sa, ok := a[k]
+		if !ok {
+			a[k] = sb
+			continue
+		}
+		a[k] = unifyEntry(sa, sb)
+	}
+	return a
+}
+
+func unifyEntry(a, b RepoSchemaEntry) RepoSchemaEntry {
+	switch {
+	case a.ValueType == PandoraTypeMap && b.ValueType == PandoraTypeMap:
+		as := make(map[string]RepoSchemaEntry, len(a.Schema))
+		for _, s := range a.Schema {
+			as[s.Key] = s
+		}
+		bs := make(map[string]RepoSchemaEntry, len(b.Schema))
+		for _, s := range b.Schema {
+			bs[s.Key] = s
+		}
+		a.Schema = a.Schema[:0:0]
+		for _, s := range unifySchemas(as, bs) {
+			a.Schema = append(a.Schema, s)
+		}
+		sortSchemas(a.Schema)
+		return a
+	case a.ValueType == PandoraTypeMap || b.ValueType == PandoraTypeMap:
+		return formValueType(a.Key, PandoraTypeJsonString)
+	case a.ValueType == PandoraTypeArray && b.ValueType == PandoraTypeArray:
+		a.ElemType = unifyType(a.ElemType, b.ElemType)
+		return a
+	case a.ValueType == PandoraTypeArray || b.ValueType == PandoraTypeArray:
+		return formValueType(a.Key, PandoraTypeString)
+	}
+	return formValueType(a.Key, unifyType(a.ValueType, b.ValueType))
+}
+
+func sortSchemas(schemas []RepoSchemaEntry) {
+	sort.Sort(Schemas(schemas))
+}
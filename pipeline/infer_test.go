@@ -0,0 +1,98 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaInferer(t *testing.T) {
+	inferer := NewSchemaInferer().
+		WithDateLayouts(time.RFC3339, "2006-01-02 15:04:05").
+		WithDetectIP(true).
+		WithInferMap(true, 2)
+	data := Data{
+		"t1":    "2018-01-02T03:04:05Z",
+		"t2":    "2018-01-02 03:04:05",
+		"ip4":   "10.0.0.1",
+		"ip6":   "fe80::1",
+		"s":     "10.0.0",
+		"nums":  []interface{}{1, json.Number("2.5"), 3},
+		"mixed": []interface{}{1, "a"},
+		"m": map[string]interface{}{
+			"a": 1,
+			"n": map[string]interface{}{
+				"b": true,
+				"deep": map[string]interface{}{
+					"c": "x",
+				},
+			},
+			"empty": nil,
+		},
+	}
+	vt := inferer.Infer(data)
+	assert.Equal(t, PandoraTypeDate, vt["t1"].ValueType)
+	assert.Equal(t, PandoraTypeDate, vt["t2"].ValueType)
+	assert.Equal(t, PandoraTypeIP, vt["ip4"].ValueType)
+	assert.Equal(t, PandoraTypeIP, vt["ip6"].ValueType)
+	assert.Equal(t, PandoraTypeString, vt["s"].ValueType)
+	assert.Equal(t, PandoraTypeFloat, vt["nums"].ElemType)
+	assert.Equal(t, PandoraTypeString, vt["mixed"].ElemType)
+	assert.Equal(t, RepoSchemaEntry{Key: "m", ValueType: PandoraTypeMap, Schema: []RepoSchemaEntry{
+		{Key: "a", ValueType: PandoraTypeLong},
+		{Key: "n", ValueType: PandoraTypeMap, Schema: []RepoSchemaEntry{
+			{Key: "b", ValueType: PandoraTypeBool},
+			{Key: "deep", ValueType: PandoraTypeJsonString},
+		}},
+	}}, vt["m"])
+	_, ok := data["m"].(map[string]interface{})["empty"]
+	assert.False(t, ok)
+
+	inferer.normalizeDates(data, vt)
+	assert.Equal(t, "2018-01-02T03:04:05Z", data["t1"])
+	assert.Equal(t, "2018-01-02T03:04:05Z", data["t2"])
+
+	// 默认与 GetTrimedDataSchema 相同
+	vt = GetTrimedDataSchema(Data{"ip": "10.0.0.1", "m": map[string]interface{}{"a": 1}})
+	assert.Equal(t, PandoraTypeString, vt["ip"].ValueType)
+	assert.Equal(t, PandoraTypeJsonString, vt["m"].ValueType)
+}
+
+func TestSchemaInfererSample(t *testing.T) {
+	inferer := NewSchemaInferer().WithInferMap(true, 0).WithSampleSize(3)
+	datas := Datas{
+		{"a": 1, "b": "x", "m": map[string]interface{}{"x": 1}},
+		{"a": 1.5, "b": 2, "m": map[string]interface{}{"y": "s"}, "c": []interface{}{1}},
+		{"a": 2, "m": "str", "c": []interface{}{2.5}},
+		{"a": "beyond sample", "d": true},
+	}
+	vt := inferer.InferDatas(datas)
+	assert.Equal(t, map[string]RepoSchemaEntry{
+		"a": {Key: "a", ValueType: PandoraTypeFloat},
+		"b": {Key: "b", ValueType: PandoraTypeString},
+		"m": {Key: "m", ValueType: PandoraTypeJsonString},
+		"c": {Key: "c", ValueType: PandoraTypeArray, ElemType: PandoraTypeFloat},
+	}, vt)
+	// 不修改原始数据
+	assert.Len(t, datas[0], 3)
+
+	client, err := NewDefaultClient(NewConfig())
+	assert.NoError(t, err)
+	client.repoSchemas["repo"] = RepoSchema{"b": {Key: "b", ValueType: PandoraTypeLong}}
+	input := &SchemaFreeInput{RepoName: "repo", Datas: datas, Option: &SchemaFreeOption{Inferer: inferer}}
+	update, err := client.sampleSchemas(context.Background(), input)
+	assert.NoError(t, err)
+	assert.True(t, update)
+	assert.Equal(t, PandoraTypeLong, client.repoSchemas["repo"]["b"].ValueType)
+	assert.Equal(t, PandoraTypeFloat, client.repoSchemas["repo"]["a"].ValueType)
+
+	// 第一条数据中的 long 按照采样得到的 float 写入，不会导致之后的数据类型冲突
+	point, update, _, err := client.generatePoint(context.Background(), datas[0], input)
+	assert.NoError(t, err)
+	assert.False(t, update)
+	assert.Equal(t, 1, pointValues(point)["a"])
+	assert.Equal(t, PandoraTypeFloat, client.repoSchemas["repo"]["a"].ValueType)
+}
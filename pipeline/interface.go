@@ -1,6 +1,7 @@
 package pipeline
 
 import (
+	"context"
 	"time"
 
 	"github.com/qiniu/pandora-go-sdk/base"
@@ -9,193 +10,421 @@ import (
 type PipelineAPI interface {
 	InitOrUpdateWorkflow(input *InitOrUpdateWorkflowInput) error
 
+	InitOrUpdateWorkflowWithContext(ctx context.Context, input *InitOrUpdateWorkflowInput) error
+
 	AutoExportToLogDB(*AutoExportToLogDBInput) error
 
+	AutoExportToLogDBWithContext(context.Context, *AutoExportToLogDBInput) error
+
 	AutoExportToKODO(*AutoExportToKODOInput) error
 
+	AutoExportToKODOWithContext(context.Context, *AutoExportToKODOInput) error
+
 	AutoExportToTSDB(*AutoExportToTSDBInput) error
 
+	AutoExportToTSDBWithContext(context.Context, *AutoExportToTSDBInput) error
+
 	CreateGroup(*CreateGroupInput) error
 
+	CreateGroupWithContext(context.Context, *CreateGroupInput) error
+
 	UpdateGroup(*UpdateGroupInput) error
 
+	UpdateGroupWithContext(context.Context, *UpdateGroupInput) error
+
 	StartGroupTask(*StartGroupTaskInput) error
 
+	StartGroupTaskWithContext(context.Context, *StartGroupTaskInput) error
+
 	StopGroupTask(*StopGroupTaskInput) error
 
+	StopGroupTaskWithContext(context.Context, *StopGroupTaskInput) error
+
 	ListGroups(*ListGroupsInput) (*ListGroupsOutput, error)
 
+	ListGroupsWithContext(context.Context, *ListGroupsInput) (*ListGroupsOutput, error)
+
 	GetGroup(*GetGroupInput) (*GetGroupOutput, error)
 
+	GetGroupWithContext(context.Context, *GetGroupInput) (*GetGroupOutput, error)
+
 	DeleteGroup(*DeleteGroupInput) error
 
+	DeleteGroupWithContext(context.Context, *DeleteGroupInput) error
+
 	CreateRepo(*CreateRepoInput) error
 
+	CreateRepoWithContext(context.Context, *CreateRepoInput) error
+
 	CreateRepoFromDSL(*CreateRepoDSLInput) error
 
+	CreateRepoFromDSLWithContext(context.Context, *CreateRepoDSLInput) error
+
 	UpdateRepo(*UpdateRepoInput) error
 
+	UpdateRepoWithContext(context.Context, *UpdateRepoInput) error
+
 	GetRepo(*GetRepoInput) (*GetRepoOutput, error)
 
+	GetRepoWithContext(context.Context, *GetRepoInput) (*GetRepoOutput, error)
+
 	GetSampleData(*GetSampleDataInput) (*SampleDataOutput, error)
 
+	GetSampleDataWithContext(context.Context, *GetSampleDataInput) (*SampleDataOutput, error)
+
 	ListRepos(*ListReposInput) (*ListReposOutput, error)
 
+	ListReposWithContext(context.Context, *ListReposInput) (*ListReposOutput, error)
+
 	DeleteRepo(*DeleteRepoInput) error
 
+	DeleteRepoWithContext(context.Context, *DeleteRepoInput) error
+
 	PostData(*PostDataInput) error
 
+	PostDataWithContext(context.Context, *PostDataInput) error
+
 	PostTextData(input *PostTextDataInput) error
 
+	PostTextDataWithContext(ctx context.Context, input *PostTextDataInput) error
+
 	PostRawtextData(input *PostRawtextDataInput) error
 
+	PostRawtextDataWithContext(ctx context.Context, input *PostRawtextDataInput) error
+
 	PostLargeData(*PostDataInput, time.Duration) (Points, error)
 
+	PostLargeDataWithContext(context.Context, *PostDataInput, time.Duration) (Points, error)
+
 	PostDataSchemaFree(input *SchemaFreeInput) (map[string]RepoSchemaEntry, error)
 
+	PostDataSchemaFreeWithContext(ctx context.Context, input *SchemaFreeInput) (map[string]RepoSchemaEntry, error)
+
+	PostDataResilient(*PostDataInput) ([]RejectedPoint, Points, error)
+
+	PostDataResilientWithContext(context.Context, *PostDataInput) ([]RejectedPoint, Points, error)
+
+	PostDataSchemaFreeResilient(*SchemaFreeInput) ([]RejectedData, map[string]RepoSchemaEntry, error)
+
+	PostDataSchemaFreeResilientWithContext(context.Context, *SchemaFreeInput) ([]RejectedData, map[string]RepoSchemaEntry, error)
+
 	PostDataFromFile(*PostDataFromFileInput) error
 
+	PostDataFromFileWithContext(context.Context, *PostDataFromFileInput) error
+
 	PostDataFromReader(*PostDataFromReaderInput) error
 
+	PostDataFromReaderWithContext(context.Context, *PostDataFromReaderInput) error
+
 	PostDataFromBytes(*PostDataFromBytesInput) error
 
+	PostDataFromBytesWithContext(context.Context, *PostDataFromBytesInput) error
+
+	PostDataFromStream(*PostDataFromStreamInput) (*PostDataFromStreamOutput, error)
+
+	PostDataFromStreamWithContext(context.Context, *PostDataFromStreamInput) (*PostDataFromStreamOutput, error)
+
 	UploadPlugin(*UploadPluginInput) error
 
+	UploadPluginWithContext(context.Context, *UploadPluginInput) error
+
 	UploadPluginFromFile(*UploadPluginFromFileInput) error
 
+	UploadPluginFromFileWithContext(context.Context, *UploadPluginFromFileInput) error
+
 	VerifyPlugin(*VerifyPluginInput) (*VerifyPluginOutput, error)
 
+	VerifyPluginWithContext(context.Context, *VerifyPluginInput) (*VerifyPluginOutput, error)
+
 	ListPlugins(*ListPluginsInput) (*ListPluginsOutput, error)
 
+	ListPluginsWithContext(context.Context, *ListPluginsInput) (*ListPluginsOutput, error)
+
 	GetPlugin(*GetPluginInput) (*GetPluginOutput, error)
 
+	GetPluginWithContext(context.Context, *GetPluginInput) (*GetPluginOutput, error)
+
 	DeletePlugin(*DeletePluginInput) error
 
+	DeletePluginWithContext(context.Context, *DeletePluginInput) error
+
 	CreateTransform(*CreateTransformInput) error
 
+	CreateTransformWithContext(context.Context, *CreateTransformInput) error
+
 	UpdateTransform(*UpdateTransformInput) error
 
+	UpdateTransformWithContext(context.Context, *UpdateTransformInput) error
+
 	GetTransform(*GetTransformInput) (*GetTransformOutput, error)
 
+	GetTransformWithContext(context.Context, *GetTransformInput) (*GetTransformOutput, error)
+
 	ListTransforms(*ListTransformsInput) (*ListTransformsOutput, error)
 
+	ListTransformsWithContext(context.Context, *ListTransformsInput) (*ListTransformsOutput, error)
+
 	DeleteTransform(*DeleteTransformInput) error
 
+	DeleteTransformWithContext(context.Context, *DeleteTransformInput) error
+
 	CreateExport(*CreateExportInput) error
 
+	CreateExportWithContext(context.Context, *CreateExportInput) error
+
 	UpdateExport(*UpdateExportInput) error
 
+	UpdateExportWithContext(context.Context, *UpdateExportInput) error
+
 	GetExport(*GetExportInput) (*GetExportOutput, error)
 
+	GetExportWithContext(context.Context, *GetExportInput) (*GetExportOutput, error)
+
 	ListExports(*ListExportsInput) (*ListExportsOutput, error)
 
+	ListExportsWithContext(context.Context, *ListExportsInput) (*ListExportsOutput, error)
+
 	DeleteExport(*DeleteExportInput) error
 
+	DeleteExportWithContext(context.Context, *DeleteExportInput) error
+
 	CreateDatasource(*CreateDatasourceInput) error
 
+	CreateDatasourceWithContext(context.Context, *CreateDatasourceInput) error
+
 	GetDatasource(*GetDatasourceInput) (*GetDatasourceOutput, error)
 
+	GetDatasourceWithContext(context.Context, *GetDatasourceInput) (*GetDatasourceOutput, error)
+
 	ListDatasources() (*ListDatasourcesOutput, error)
 
+	ListDatasourcesWithContext(ctx context.Context) (*ListDatasourcesOutput, error)
+
 	DeleteDatasource(*DeleteDatasourceInput) error
 
+	DeleteDatasourceWithContext(context.Context, *DeleteDatasourceInput) error
+
 	CreateJob(*CreateJobInput) error
 
+	CreateJobWithContext(context.Context, *CreateJobInput) error
+
 	GetJob(*GetJobInput) (*GetJobOutput, error)
 
+	GetJobWithContext(context.Context, *GetJobInput) (*GetJobOutput, error)
+
 	ListJobs(*ListJobsInput) (*ListJobsOutput, error)
 
+	ListJobsWithContext(context.Context, *ListJobsInput) (*ListJobsOutput, error)
+
 	DeleteJob(*DeleteJobInput) error
 
+	DeleteJobWithContext(context.Context, *DeleteJobInput) error
+
 	// 启动接口不适用于新版本workflow(2017/12/14起), 请使用StopWorkflow来停止当前workflow的jobs
 	StartJob(*StartJobInput) error
 
+	StartJobWithContext(context.Context, *StartJobInput) error
+
 	// 停止接口不适用于新版本workflow(2017/12/14起)，请使用StartWorkflow来启动当前workflow的jobs
 	StopJob(*StopJobInput) error
 
+	StopJobWithContext(context.Context, *StopJobInput) error
+
 	GetJobHistory(*GetJobHistoryInput) (*GetJobHistoryOutput, error)
 
+	GetJobHistoryWithContext(context.Context, *GetJobHistoryInput) (*GetJobHistoryOutput, error)
+
 	StopJobBatch(*StopJobBatchInput) (*StopJobBatchOutput, error)
 
+	StopJobBatchWithContext(context.Context, *StopJobBatchInput) (*StopJobBatchOutput, error)
+
 	RerunJobBatch(*RerunJobBatchInput) (*RerunJobBatchOutput, error)
 
+	RerunJobBatchWithContext(context.Context, *RerunJobBatchInput) (*RerunJobBatchOutput, error)
+
 	CreateJobExport(*CreateJobExportInput) error
 
+	CreateJobExportWithContext(context.Context, *CreateJobExportInput) error
+
 	GetJobExport(*GetJobExportInput) (*GetJobExportOutput, error)
 
+	GetJobExportWithContext(context.Context, *GetJobExportInput) (*GetJobExportOutput, error)
+
 	ListJobExports(*ListJobExportsInput) (*ListJobExportsOutput, error)
 
+	ListJobExportsWithContext(context.Context, *ListJobExportsInput) (*ListJobExportsOutput, error)
+
 	DeleteJobExport(*DeleteJobExportInput) error
 
+	DeleteJobExportWithContext(context.Context, *DeleteJobExportInput) error
+
 	RetrieveSchema(*RetrieveSchemaInput) (*RetrieveSchemaOutput, error)
 
+	RetrieveSchemaWithContext(context.Context, *RetrieveSchemaInput) (*RetrieveSchemaOutput, error)
+
 	MakeToken(*base.TokenDesc) (string, error)
 
 	GetDefault(RepoSchemaEntry) interface{}
 
 	GetUpdateSchemas(string) (map[string]RepoSchemaEntry, error)
 
+	GetUpdateSchemasWithContext(context.Context, string) (map[string]RepoSchemaEntry, error)
+
 	GetUpdateSchemasWithInput(input *GetRepoInput) (map[string]RepoSchemaEntry, error)
 
+	GetUpdateSchemasWithInputWithContext(ctx context.Context, input *GetRepoInput) (map[string]RepoSchemaEntry, error)
+
 	UploadUdf(input *UploadUdfInput) (err error)
 
+	UploadUdfWithContext(ctx context.Context, input *UploadUdfInput) (err error)
+
 	UploadUdfFromFile(input *UploadUdfFromFileInput) (err error)
 
+	UploadUdfFromFileWithContext(ctx context.Context, input *UploadUdfFromFileInput) (err error)
+
 	PutUdfMeta(input *PutUdfMetaInput) (err error)
 
+	PutUdfMetaWithContext(ctx context.Context, input *PutUdfMetaInput) (err error)
+
 	DeleteUdf(input *DeleteUdfInfoInput) (err error)
 
+	DeleteUdfWithContext(ctx context.Context, input *DeleteUdfInfoInput) (err error)
+
 	ListUdfs(input *ListUdfsInput) (output *ListUdfsOutput, err error)
 
+	ListUdfsWithContext(ctx context.Context, input *ListUdfsInput) (output *ListUdfsOutput, err error)
+
 	RegisterUdfFunction(input *RegisterUdfFunctionInput) (err error)
 
+	RegisterUdfFunctionWithContext(ctx context.Context, input *RegisterUdfFunctionInput) (err error)
+
 	DeRegisterUdfFunction(input *DeregisterUdfFunctionInput) (err error)
 
+	DeRegisterUdfFunctionWithContext(ctx context.Context, input *DeregisterUdfFunctionInput) (err error)
+
 	ListUdfFunctions(input *ListUdfFunctionsInput) (output *ListUdfFunctionsOutput, err error)
 
+	ListUdfFunctionsWithContext(ctx context.Context, input *ListUdfFunctionsInput) (output *ListUdfFunctionsOutput, err error)
+
 	ListBuiltinUdfFunctions(input *ListBuiltinUdfFunctionsInput) (output *ListUdfBuiltinFunctionsOutput, err error)
 
+	ListBuiltinUdfFunctionsWithContext(ctx context.Context, input *ListBuiltinUdfFunctionsInput) (output *ListUdfBuiltinFunctionsOutput, err error)
+
 	CreateWorkflow(input *CreateWorkflowInput) (err error)
 
+	CreateWorkflowWithContext(ctx context.Context, input *CreateWorkflowInput) (err error)
+
 	UpdateWorkflow(input *UpdateWorkflowInput) (err error)
 
+	UpdateWorkflowWithContext(ctx context.Context, input *UpdateWorkflowInput) (err error)
+
 	GetWorkflow(input *GetWorkflowInput) (output *GetWorkflowOutput, err error)
 
+	GetWorkflowWithContext(ctx context.Context, input *GetWorkflowInput) (output *GetWorkflowOutput, err error)
+
 	GetWorkflowStatus(input *GetWorkflowStatusInput) (output *GetWorkflowStatusOutput, err error)
 
+	GetWorkflowStatusWithContext(ctx context.Context, input *GetWorkflowStatusInput) (output *GetWorkflowStatusOutput, err error)
+
 	DeleteWorkflow(input *DeleteWorkflowInput) (err error)
 
+	DeleteWorkflowWithContext(ctx context.Context, input *DeleteWorkflowInput) (err error)
+
 	StartWorkflow(input *StartWorkflowInput) error
 
+	StartWorkflowWithContext(ctx context.Context, input *StartWorkflowInput) error
+
 	StopWorkflow(input *StopWorkflowInput) error
 
+	StopWorkflowWithContext(ctx context.Context, input *StopWorkflowInput) error
+
 	ListWorkflows(input *ListWorkflowInput) (output *ListWorkflowOutput, err error)
 
+	ListWorkflowsWithContext(ctx context.Context, input *ListWorkflowInput) (output *ListWorkflowOutput, err error)
+
 	SearchWorkflow(input *DagLogSearchInput) (ret *WorkflowSearchRet, err error)
 
+	SearchWorkflowWithContext(ctx context.Context, input *DagLogSearchInput) (ret *WorkflowSearchRet, err error)
+
 	RepoExist(input *RepoExistInput) (output *RepoExistOutput, err error)
 
+	RepoExistWithContext(ctx context.Context, input *RepoExistInput) (output *RepoExistOutput, err error)
+
 	TransformExist(input *TransformExistInput) (output *TransformExistOutput, err error)
 
+	TransformExistWithContext(ctx context.Context, input *TransformExistInput) (output *TransformExistOutput, err error)
+
 	ExportExist(input *ExportExistInput) (output *ExportExistOutput, err error)
 
+	ExportExistWithContext(ctx context.Context, input *ExportExistInput) (output *ExportExistOutput, err error)
+
 	DatasourceExist(input *DatasourceExistInput) (output *DatasourceExistOutput, err error)
 
+	DatasourceExistWithContext(ctx context.Context, input *DatasourceExistInput) (output *DatasourceExistOutput, err error)
+
 	JobExist(input *JobExistInput) (output *JobExistOutput, err error)
 
+	JobExistWithContext(ctx context.Context, input *JobExistInput) (output *JobExistOutput, err error)
+
 	JobExportExist(input *JobExportExistInput) (output *JobExportExistOutput, err error)
 
+	JobExportExistWithContext(ctx context.Context, input *JobExportExistInput) (output *JobExportExistOutput, err error)
+
 	CreateVariable(input *CreateVariableInput) (err error)
 
+	CreateVariableWithContext(ctx context.Context, input *CreateVariableInput) (err error)
+
 	UpdateVariable(input *UpdateVariableInput) (err error)
 
+	UpdateVariableWithContext(ctx context.Context, input *UpdateVariableInput) (err error)
+
 	DeleteVariable(input *DeleteVariableInput) (err error)
 
+	DeleteVariableWithContext(ctx context.Context, input *DeleteVariableInput) (err error)
+
 	GetVariable(input *GetVariableInput) (output *GetVariableOutput, err error)
 
+	GetVariableWithContext(ctx context.Context, input *GetVariableInput) (output *GetVariableOutput, err error)
+
 	ListUserVariables(input *ListVariablesInput) (output *ListVariablesOutput, err error)
 
+	ListUserVariablesWithContext(ctx context.Context, input *ListVariablesInput) (output *ListVariablesOutput, err error)
+
 	ListSystemVariables(input *ListVariablesInput) (output *ListVariablesOutput, err error)
 
+	ListSystemVariablesWithContext(ctx context.Context, input *ListVariablesInput) (output *ListVariablesOutput, err error)
+
+	SetRepoRegion(repoName, region string)
+
 	Close() error
 }
+
+// 以下接口是 PipelineAPI 的可选扩展，*Pipeline 实现了这些接口。新增的方法不加入 PipelineAPI，
+// 避免破坏外部对 PipelineAPI 的实现与 mock，使用时通过类型断言获取，如 client.(pipeline.SchemaFreeTokenMaker)
+
+var _ SchemaFreeTokenMaker = (*Pipeline)(nil)
+
+// SchemaFreeTokenMaker 通过 TokenManager 生成 PostDataSchemaFree 需要的全部 token
+type SchemaFreeTokenMaker interface {
+	MakeSchemaFreeToken(m *base.TokenManager, repoName, workflowName string) (SchemaFreeToken, error)
+}
+
+var _ RepoMigrator = (*Pipeline)(nil)
+
+// RepoMigrator 计算并执行 repo schema 的迁移，见 RepoMigrationInput
+type RepoMigrator interface {
+	PlanRepoMigration(*RepoMigrationInput) (*RepoMigrationPlan, error)
+
+	PlanRepoMigrationWithContext(context.Context, *RepoMigrationInput) (*RepoMigrationPlan, error)
+
+	MigrateRepo(*RepoMigrationInput) (*RepoMigrationPlan, error)
+
+	MigrateRepoWithContext(context.Context, *RepoMigrationInput) (*RepoMigrationPlan, error)
+}
+
+var _ TypeConflictReporter = (*Pipeline)(nil)
+
+// TypeConflictReporter 返回 schema free 写入时每个 repo 的类型冲突计数，见 SchemaFreeOption.TypeConflict
+type TypeConflictReporter interface {
+	TypeConflictStats(repoName string) TypeConflictStats
+}
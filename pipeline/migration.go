@@ -0,0 +1,307 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/qiniu/pandora-go-sdk/base"
+	. "github.com/qiniu/pandora-go-sdk/base/models"
+	"github.com/qiniu/pandora-go-sdk/base/reqerr"
+)
+
+type SchemaChangeType string
+
+const (
+	SchemaFieldAdded           SchemaChangeType = "added"
+	SchemaFieldRemoved         SchemaChangeType = "removed"
+	SchemaFieldRetyped         SchemaChangeType = "retyped"
+	SchemaFieldRequiredChanged SchemaChangeType = "required_changed"
+)
+
+// SchemaChange 描述一个字段的变化，Key 为字段路径，map 中的字段以 . 连接，如 meta.host
+type SchemaChange struct {
+	Type SchemaChangeType
+	Key  string
+	Old  *RepoSchemaEntry // 新增字段为 nil
+	New  *RepoSchemaEntry // 删除字段为 nil
+}
+
+func (c SchemaChange) String() string {
+	switch c.Type {
+	case SchemaFieldAdded:
+		return fmt.Sprintf("+ %s %s", c.Key, schemaTypeString(c.New))
+	case SchemaFieldRemoved:
+		return fmt.Sprintf("- %s %s", c.Key, schemaTypeString(c.Old))
+	case SchemaFieldRetyped:
+		return fmt.Sprintf("~ %s %s -> %s", c.Key, schemaTypeString(c.Old), schemaTypeString(c.New))
+	default:
+		return fmt.Sprintf("~ %s required %v -> %v", c.Key, c.Old.Required, c.New.Required)
+	}
+}
+
+func schemaTypeString(e *RepoSchemaEntry) string {
+	if e.ValueType == PandoraTypeArray {
+		return e.ValueType + "(" + e.ElemType + ")"
+	}
+	return e.ValueType
+}
+
+// Breaking 返回变化是否可能导致已有数据或下游导出不可用：删除字段、修改类型以及把字段改为必填
+func (c SchemaChange) Breaking() bool {
+	switch c.Type {
+	case SchemaFieldRemoved, SchemaFieldRetyped:
+		return true
+	case SchemaFieldRequiredChanged:
+		return c.New.Required
+	}
+	return false
+}
+
+// SchemaDiff 是按字段路径排序的 schema 变化
+type SchemaDiff []SchemaChange
+
+// Filter 返回指定类型的变化
+func (d SchemaDiff) Filter(typ SchemaChangeType) SchemaDiff {
+	var ret SchemaDiff
+	for _, c := range d {
+		if c.Type == typ {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// Breaking 返回其中会破坏兼容性的变化
+func (d SchemaDiff) Breaking() SchemaDiff {
+	var ret SchemaDiff
+	for _, c := range d {
+		if c.Breaking() {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+func (d SchemaDiff) String() string {
+	lines := make([]string, len(d))
+	for i, c := range d {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DiffSchemas 比较两个 schema，报告新增、删除、修改类型与修改 required 的字段，map 类型的字段会递归比较
+func DiffSchemas(old, new []RepoSchemaEntry) SchemaDiff {
+	diff := diffSchemas("", old, new, nil)
+	sort.SliceStable(diff, func(i, j int) bool {
+		return diff[i].Key < diff[j].Key
+	})
+	return diff
+}
+
+func diffSchemas(prefix string, old, new []RepoSchemaEntry, diff SchemaDiff) SchemaDiff {
+	olds := make(map[string]int, len(old))
+	for i, e := range old {
+		olds[e.Key] = i
+	}
+	news := make(map[string]struct{}, len(new))
+	for i := range new {
+		n := &new[i]
+		news[n.Key] = struct{}{}
+		key := prefix + n.Key
+		oi, ok := olds[n.Key]
+		if !ok {
+			diff = append(diff, SchemaChange{Type: SchemaFieldAdded, Key: key, New: n})
+			continue
+		}
+		o := &old[oi]
+		if o.ValueType != n.ValueType || (o.ValueType == PandoraTypeArray && o.ElemType != n.ElemType) {
+			diff = append(diff, SchemaChange{Type: SchemaFieldRetyped, Key: key, Old: o, New: n})
+		} else if o.ValueType == PandoraTypeMap {
+			diff = diffSchemas(key+".", o.Schema, n.Schema, diff)
+		}
+		if o.Required != n.Required {
+			diff = append(diff, SchemaChange{Type: SchemaFieldRequiredChanged, Key: key, Old: o, New: n})
+		}
+	}
+	for i := range old {
+		if _, ok := news[old[i].Key]; !ok {
+			diff = append(diff, SchemaChange{Type: SchemaFieldRemoved, Key: prefix + old[i].Key, Old: &old[i]})
+		}
+	}
+	return diff
+}
+
+// ExportImpact 描述迁移对一个下游导出的影响
+type ExportImpact struct {
+	Name string
+	Type string
+	// Create 表示导出还不存在，UpdateRepo 会根据 SchemaFreeOption 创建
+	Create bool
+	// AddFields 是 UpdateRepo 会加入导出(以及 logdb repo) 的新字段
+	AddFields []string
+	// Changes 是导出中已经引用、但被删除或修改了类型的字段，UpdateRepo 不会处理，需要手动修改导出
+	Changes SchemaDiff
+}
+
+// RepoMigrationPlan 描述 UpdateRepo 会对 repo 及其下游导出做出的修改
+type RepoMigrationPlan struct {
+	RepoName string
+	Diff     SchemaDiff
+	// Schema 是 UpdateRepo 之后 repo 的 schema，UpdateRepo 不会删除 repo 中已有的字段
+	Schema  []RepoSchemaEntry
+	Exports []ExportImpact
+	// Applied 表示迁移已经执行
+	Applied bool
+}
+
+// Breaking 返回迁移中会破坏兼容性的变化
+func (p *RepoMigrationPlan) Breaking() SchemaDiff {
+	return p.Diff.Breaking()
+}
+
+type RepoMigrationInput struct {
+	UpdateRepoInput
+	// ListExportToken 用于列举 repo 的导出
+	ListExportToken PandoraToken
+	// DryRun 为 true 时 MigrateRepo 只返回迁移计划，不做任何修改
+	DryRun bool
+	// AllowBreaking 为 false 时，迁移中存在 Breaking 的变化则 MigrateRepo 返回错误，不做任何修改
+	AllowBreaking bool
+}
+
+func (c *Pipeline) PlanRepoMigration(input *RepoMigrationInput) (*RepoMigrationPlan, error) {
+	return c.PlanRepoMigrationWithContext(context.Background(), input)
+}
+
+// PlanRepoMigrationWithContext 计算以 input 调用 UpdateRepo 时 repo schema 的变化以及受影响的下游导出，不做任何修改
+func (c *Pipeline) PlanRepoMigrationWithContext(ctx context.Context, input *RepoMigrationInput) (plan *RepoMigrationPlan, err error) {
+	repo, err := c.GetRepoWithContext(ctx, &GetRepoInput{
+		RepoName:     input.RepoName,
+		PandoraToken: input.PipelineGetRepoToken,
+	})
+	if err != nil {
+		return
+	}
+	plan = &RepoMigrationPlan{
+		RepoName: input.RepoName,
+		Schema:   updatedSchema(repo.Schema, input.Schema),
+	}
+	plan.Diff = DiffSchemas(repo.Schema, plan.Schema)
+
+	exports, err := c.ListExportsWithContext(ctx, &ListExportsInput{
+		RepoName:     input.RepoName,
+		PandoraToken: input.ListExportToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+	plan.Exports = planExports(&input.UpdateRepoInput, plan.Schema, exports.Exports, plan.Diff)
+	return
+}
+
+func (c *Pipeline) MigrateRepo(input *RepoMigrationInput) (*RepoMigrationPlan, error) {
+	return c.MigrateRepoWithContext(context.Background(), input)
+}
+
+// MigrateRepoWithContext 计算迁移计划，DryRun 为 false 时再以 input.UpdateRepoInput 调用 UpdateRepo 执行迁移
+func (c *Pipeline) MigrateRepoWithContext(ctx context.Context, input *RepoMigrationInput) (plan *RepoMigrationPlan, err error) {
+	if plan, err = c.PlanRepoMigrationWithContext(ctx, input); err != nil {
+		return
+	}
+	if input.DryRun {
+		return
+	}
+	if breaking := plan.Breaking(); len(breaking) > 0 && !input.AllowBreaking {
+		err = reqerr.NewInvalidArgs("Schema", fmt.Sprintf("migration of repo %s has breaking changes:\n%v", input.RepoName, breaking)).WithComponent("pipleline")
+		return
+	}
+	if len(plan.Diff) == 0 && len(plan.Exports) == 0 {
+		return
+	}
+	if err = c.UpdateRepoWithContext(ctx, &input.UpdateRepoInput); err != nil {
+		return
+	}
+	plan.Applied = true
+	return
+}
+
+// planExports 按照 UpdateRepo 的逻辑计算每个导出受到的影响，schema 是 UpdateRepo 之后 repo 的完整 schema，
+// UpdateRepo 以完整的 schema 更新导出，因此 repo 中已有、但导出没有引用的字段也会加入导出
+func planExports(input *UpdateRepoInput, schema []RepoSchemaEntry, exports []ExportDesc, diff SchemaDiff) (impacts []ExportImpact) {
+	option := input.Option
+	if option == nil {
+		option = &SchemaFreeOption{}
+	}
+	// UpdateRepo 会更新的导出
+	managed := map[string]string{}
+	if option.ToLogDB {
+		managed[base.FormExportName(input.RepoName, ExportTypeLogDB)] = ExportTypeLogDB
+	}
+	if option.ToKODO {
+		managed[base.FormExportName(input.RepoName, ExportTypeKODO)] = ExportTypeKODO
+	}
+	if option.ToTSDB {
+		managed[base.FormExportTSDBName(input.RepoName, option.SeriesName, ExportTypeTSDB)] = ExportTypeTSDB
+	}
+
+	for _, ex := range exports {
+		impact := ExportImpact{Name: ex.Name, Type: ex.Type}
+		refs := exportFieldRefs(ex.Spec)
+		if typ, ok := managed[ex.Name]; ok && typ == ex.Type {
+			delete(managed, ex.Name)
+			for _, sc := range schema {
+				if !refs[sc.Key] {
+					impact.AddFields = append(impact.AddFields, sc.Key)
+				}
+			}
+		}
+		for _, c := range diff {
+			if c.Type == SchemaFieldAdded || c.Type == SchemaFieldRequiredChanged {
+				continue
+			}
+			if refs[strings.SplitN(c.Key, ".", 2)[0]] {
+				impact.Changes = append(impact.Changes, c)
+			}
+		}
+		if len(impact.AddFields) > 0 || len(impact.Changes) > 0 {
+			impacts = append(impacts, impact)
+		}
+	}
+
+	names := make([]string, 0, len(managed))
+	for name := range managed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		impacts = append(impacts, ExportImpact{Name: name, Type: managed[name], Create: true})
+	}
+	return
+}
+
+// exportFieldRefs 返回导出 spec 中以 #<字段名> 形式引用的 repo 字段
+func exportFieldRefs(spec map[string]interface{}) map[string]bool {
+	refs := make(map[string]bool)
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch vv := v.(type) {
+		case string:
+			if strings.HasPrefix(vv, "#") {
+				refs[strings.SplitN(vv[1:], ".", 2)[0]] = true
+			}
+		case map[string]interface{}:
+			for _, e := range vv {
+				walk(e)
+			}
+		case []interface{}:
+			for _, e := range vv {
+				walk(e)
+			}
+		}
+	}
+	walk(spec)
+	return refs
+}
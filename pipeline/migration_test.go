@@ -0,0 +1,109 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qiniu/pandora-go-sdk/base"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	old := []RepoSchemaEntry{
+		{Key: "a", ValueType: PandoraTypeLong},
+		{Key: "b", ValueType: PandoraTypeString},
+		{Key: "c", ValueType: PandoraTypeArray, ElemType: PandoraTypeLong},
+		{Key: "m", ValueType: PandoraTypeMap, Schema: []RepoSchemaEntry{
+			{Key: "x", ValueType: PandoraTypeString},
+			{Key: "y", ValueType: PandoraTypeLong},
+		}},
+	}
+	new := []RepoSchemaEntry{
+		{Key: "a", ValueType: PandoraTypeLong, Required: true},
+		{Key: "c", ValueType: PandoraTypeArray, ElemType: PandoraTypeString},
+		{Key: "d", ValueType: PandoraTypeDate},
+		{Key: "m", ValueType: PandoraTypeMap, Schema: []RepoSchemaEntry{
+			{Key: "x", ValueType: PandoraTypeFloat},
+			{Key: "z", ValueType: PandoraTypeBool},
+		}},
+	}
+	diff := DiffSchemas(old, new)
+	assert.Equal(t, "~ a required false -> true\n"+
+		"- b string\n"+
+		"~ c array(long) -> array(string)\n"+
+		"+ d date\n"+
+		"~ m.x string -> float\n"+
+		"- m.y long\n"+
+		"+ m.z boolean", diff.String())
+	assert.Len(t, diff.Breaking(), 5)
+	assert.Len(t, diff.Filter(SchemaFieldAdded), 2)
+	assert.Empty(t, DiffSchemas(old, old))
+}
+
+func TestMigrateRepo(t *testing.T) {
+	var updated *UpdateRepoInput
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(base.HTTPHeaderContentType, base.ContentTypeJson)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v2/repos/repo":
+			w.Write([]byte(`{"schema":[{"key":"a","valtype":"long"},{"key":"b","valtype":"string"}]}`))
+		case "GET /v2/repos/repo/exports":
+			w.Write([]byte(`{"exports":[
+				{"name":"repo_export2_logdb","type":"logdb","spec":{"doc":{"b":"#b"}}},
+				{"name":"to_http","type":"http","spec":{"fields":"#b"}},
+				{"name":"other","type":"http","spec":{"fields":"#a"}}
+			]}`))
+		case "PUT /v2/repos/repo":
+			updated = &UpdateRepoInput{}
+			json.NewDecoder(r.Body).Decode(updated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	client, err := NewDefaultClient(NewConfig().WithPipelineEndpoint(srv.URL).WithAccessKeySecretKey("ak", "sk"))
+	assert.NoError(t, err)
+
+	input := &RepoMigrationInput{
+		UpdateRepoInput: UpdateRepoInput{
+			RepoName: "repo",
+			Schema: []RepoSchemaEntry{
+				{Key: "b", ValueType: PandoraTypeLong},
+				{Key: "c", ValueType: PandoraTypeFloat},
+			},
+			Option: &SchemaFreeOption{ToLogDB: true, ToKODO: true},
+		},
+		DryRun: true,
+	}
+	plan, err := client.MigrateRepo(input)
+	assert.NoError(t, err)
+	assert.False(t, plan.Applied)
+	assert.Nil(t, updated)
+	assert.Equal(t, "~ b string -> long\n+ c float", plan.Diff.String())
+	assert.Equal(t, []RepoSchemaEntry{
+		{Key: "a", ValueType: PandoraTypeLong},
+		{Key: "b", ValueType: PandoraTypeLong},
+		{Key: "c", ValueType: PandoraTypeFloat},
+	}, plan.Schema)
+	// repo 中已有、但 logdb 导出没有引用的字段 a 也会被 UpdateRepo 加入导出
+	assert.Equal(t, []ExportImpact{
+		{Name: "repo_export2_logdb", Type: ExportTypeLogDB, AddFields: []string{"a", "c"}, Changes: plan.Diff[:1]},
+		{Name: "to_http", Type: ExportTypeHTTP, Changes: plan.Diff[:1]},
+		{Name: "repo_export2_kodo", Type: ExportTypeKODO, Create: true},
+	}, plan.Exports)
+
+	// 存在 breaking 的变化时默认不执行
+	input.DryRun = false
+	_, err = client.MigrateRepo(input)
+	assert.Error(t, err)
+	assert.Nil(t, updated)
+
+	input.AllowBreaking = true
+	input.Option = nil
+	plan, err = client.MigrateRepo(input)
+	assert.NoError(t, err)
+	assert.True(t, plan.Applied)
+	assert.Equal(t, plan.Schema, updated.Schema)
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/qiniu/pandora-go-sdk/base"
 	. "github.com/qiniu/pandora-go-sdk/base/models"
+	"github.com/qiniu/pandora-go-sdk/base/record"
 	"github.com/qiniu/pandora-go-sdk/base/reqerr"
 )
 
@@ -984,6 +985,35 @@ type GetRepoOutput struct {
 
 type SampleDataOutput struct {
 	Values []map[string]interface{} `json:"records"`
+
+	raw json.RawMessage
+}
+
+type sampleDataOutput SampleDataOutput
+
+// UnmarshalJSON 按默认规则解析 Values，同时保留其原始数据，Decode 时 long 类型的数字不会经过 float64 转换而损失精度
+func (o *SampleDataOutput) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		*sampleDataOutput
+		Values json.RawMessage `json:"records"`
+	}{sampleDataOutput: (*sampleDataOutput)(o)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	o.raw = aux.Values
+	o.Values = nil
+	if len(aux.Values) == 0 {
+		return nil
+	}
+	return json.Unmarshal(aux.Values, &o.Values)
+}
+
+// Decode 把采样数据解码到 v 指向的结构体 slice，结构体字段使用 pandora 标签指定名称，见 record.Decode
+func (o *SampleDataOutput) Decode(v interface{}) error {
+	if len(o.raw) > 0 {
+		return record.Unmarshal(o.raw, v)
+	}
+	return record.DecodeSlice(o.Values, v)
 }
 
 type RepoDesc struct {
@@ -1228,6 +1258,14 @@ type SchemaFreeOption struct {
 	ToKODO           bool
 	ForceDataConvert bool
 	NumberUseFloat   bool
+	// TypeConflict 是字段类型与 repo schema 冲突时的处理策略，默认不处理
+	TypeConflict TypeConflictStrategy
+	// DeadLetterRepo 是 TypeConflict 为 TypeConflictDeadLetter 时写入冲突数据的 repo
+	DeadLetterRepo string
+	// DeadLetterToken 是写入 DeadLetterRepo 使用的 token，使用 token 鉴权时必须设置，SchemaFreeInput 中的 token 只能用于原 repo
+	DeadLetterToken SchemaFreeToken
+	// Inferer 推断新字段的类型，默认与 GetTrimedDataSchema 相同
+	Inferer *SchemaInferer
 	AutoExportToLogDBInput
 	AutoExportToKODOInput
 	AutoExportToTSDBInput
@@ -1255,6 +1293,23 @@ type PostDataFromBytesInput struct {
 	Tags     map[string]interface{}
 }
 
+// PostDataFromStreamInput 中 Reader 的每一行是一条 pandora 打点协议格式的数据，
+// SchemaFree 不为 nil 时每一行是一个 JSON 对象，通过 PostDataSchemaFree 发送，其中的 Datas 与 RepoName 字段会被忽略
+type PostDataFromStreamInput struct {
+	PandoraToken
+	RepoName    string
+	Reader      io.Reader
+	Tags        map[string]interface{}
+	SchemaFree  *SchemaFreeInput
+	BatchSize   int // 每个请求的最大字节数，默认且最大为 PandoraMaxBatchSize
+	Concurrency int // 并发发送的请求数，默认为1，即按顺序发送
+}
+
+type PostDataFromStreamOutput struct {
+	Offset int64 // 已确认发送成功的最后一行之后的字节偏移，重新发送时从 Reader 的这个位置继续即可
+	Lines  int64 // 已确认发送成功的行数，不包含空行
+}
+
 type UploadPluginInput struct {
 	PandoraToken
 	ResourceOwner string
@@ -0,0 +1,338 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/qiniu/pandora-go-sdk/base"
+	. "github.com/qiniu/pandora-go-sdk/base/models"
+	"github.com/qiniu/pandora-go-sdk/base/reqerr"
+)
+
+const (
+	defaultProducerLinger    = time.Second
+	defaultProducerWorkers   = 1
+	defaultProducerQueueSize = 16
+	defaultProducerBatchData = 1000
+)
+
+var ErrProducerClosed = errors.New("pipeline producer is closed")
+
+// ProducerConfig 描述 Producer 如何攒批与发送
+type ProducerConfig struct {
+	RepoName     string
+	PandoraToken PandoraToken
+	Tags         map[string]interface{}
+
+	// SchemaFree 不为 nil 时，通过 Producer.SendData 写入的数据使用 PostDataSchemaFree 发送，
+	// 其中的 Datas 与 RepoName 字段会被忽略
+	SchemaFree *SchemaFreeInput
+
+	BatchSize      int           // 一批 Point 序列化后的最大字节数，默认且最大为 PandoraMaxBatchSize
+	BatchDataCount int           // 一批 Data 的最大条数，schemafree 会再按 PandoraMaxBatchSize 拆分请求
+	Linger         time.Duration // 数据在缓冲区中停留的最长时间，到期后即使未满也会发送
+	Workers        int           // 并发发送的 worker 数
+	QueueSize      int           // 等待 worker 发送的批次数，队列满时写入会阻塞
+
+	// OnError 在一批数据发送失败时调用，可能被多个 worker 并发调用；为 nil 时只打印日志
+	OnError func(*ProducerError)
+	// Spool 不为 nil 时，发送失败的数据会先写入本地 spool，待服务恢复后重放
+	Spool *Spool
+}
+
+// ProducerError 记录一批发送失败的数据，Points 与 Datas 只有一个不为空
+type ProducerError struct {
+	Err    *reqerr.SendError
+	Points Points
+	Datas  Datas
+}
+
+func (e *ProducerError) Error() string {
+	return e.Err.Error()
+}
+
+type producerBatch struct {
+	points Points
+	datas  Datas
+	buffer []byte
+}
+
+// Producer 接收多个 goroutine 写入的单条 Point 或 Data，按大小与时间攒批后由多个 worker 异步发送
+type Producer struct {
+	client PipelineAPI
+	cfg    ProducerConfig
+	logger base.StructuredLogger
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	closed   bool
+	inflight int
+
+	points    Points
+	pointsBuf bytes.Buffer
+	datas     Datas
+	lingerGen int
+	linger    *time.Timer
+
+	batches chan *producerBatch
+	workers sync.WaitGroup
+}
+
+func NewProducer(client PipelineAPI, cfg ProducerConfig) (*Producer, error) {
+	if cfg.RepoName == "" {
+		return nil, reqerr.NewInvalidArgs("RepoName", "repo name should not be empty")
+	}
+	if cfg.BatchSize <= 0 || cfg.BatchSize > PandoraMaxBatchSize {
+		cfg.BatchSize = PandoraMaxBatchSize
+	}
+	if cfg.BatchDataCount <= 0 {
+		cfg.BatchDataCount = defaultProducerBatchData
+	}
+	if cfg.Linger <= 0 {
+		cfg.Linger = defaultProducerLinger
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultProducerWorkers
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultProducerQueueSize
+	}
+	logger := base.NewFieldLogger(base.NewDefaultLogger())
+	if p, ok := client.(*Pipeline); ok {
+		logger = p.Config.GetStructuredLogger()
+	}
+	p := &Producer{
+		client:  client,
+		cfg:     cfg,
+		logger:  logger,
+		batches: make(chan *producerBatch, cfg.QueueSize),
+	}
+	p.cond = sync.NewCond(&p.mu)
+	for i := 0; i < cfg.Workers; i++ {
+		p.workers.Add(1)
+		go p.work()
+	}
+	return p, nil
+}
+
+// SendPoint 写入一条 Point，缓冲区满时会把当前批次交给 worker，队列满时阻塞
+func (p *Producer) SendPoint(point Point) error {
+	bs := point.ToBytes()
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrProducerClosed
+	}
+	var full *producerBatch
+	if len(p.points) > 0 && p.pointsBuf.Len()+len(bs) > p.cfg.BatchSize {
+		full = p.takePointsLocked()
+	}
+	p.points = append(p.points, point)
+	p.pointsBuf.Write(bs)
+	p.startLingerLocked()
+	p.mu.Unlock()
+
+	p.dispatch(full)
+	return nil
+}
+
+// SendData 写入一条 schemafree 数据，需要 ProducerConfig.SchemaFree 不为 nil
+func (p *Producer) SendData(data Data) error {
+	if p.cfg.SchemaFree == nil {
+		return reqerr.NewInvalidArgs("SchemaFree", "producer is not configured for schema free data")
+	}
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrProducerClosed
+	}
+	p.datas = append(p.datas, data)
+	var full *producerBatch
+	if len(p.datas) >= p.cfg.BatchDataCount {
+		full = p.takeDatasLocked()
+	}
+	p.startLingerLocked()
+	p.mu.Unlock()
+
+	p.dispatch(full)
+	return nil
+}
+
+// Flush 立即发送缓冲区中的数据，并等待所有已提交的批次发送完成
+func (p *Producer) Flush() {
+	p.mu.Lock()
+	points, datas := p.takePointsLocked(), p.takeDatasLocked()
+	p.mu.Unlock()
+
+	p.dispatch(points)
+	p.dispatch(datas)
+
+	p.mu.Lock()
+	for p.inflight > 0 {
+		p.cond.Wait()
+	}
+	p.mu.Unlock()
+}
+
+// Close 发送完所有缓冲的数据后停止 worker，之后的写入返回 ErrProducerClosed
+func (p *Producer) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	p.Flush()
+	close(p.batches)
+	p.workers.Wait()
+	return nil
+}
+
+func (p *Producer) takePointsLocked() *producerBatch {
+	if len(p.points) == 0 {
+		return nil
+	}
+	buf := make([]byte, p.pointsBuf.Len())
+	copy(buf, p.pointsBuf.Bytes())
+	// 与 Points.Buffer 保持一致，去掉最后的换行符
+	b := &producerBatch{points: p.points, buffer: buf[:len(buf)-1]}
+	p.points = nil
+	p.pointsBuf.Reset()
+	p.inflight++
+	return b
+}
+
+func (p *Producer) takeDatasLocked() *producerBatch {
+	if len(p.datas) == 0 {
+		return nil
+	}
+	b := &producerBatch{datas: p.datas}
+	p.datas = nil
+	p.inflight++
+	return b
+}
+
+func (p *Producer) startLingerLocked() {
+	if p.linger != nil {
+		return
+	}
+	gen := p.lingerGen
+	p.linger = time.AfterFunc(p.cfg.Linger, func() {
+		p.mu.Lock()
+		if p.lingerGen != gen {
+			p.mu.Unlock()
+			return
+		}
+		p.lingerGen++
+		p.linger = nil
+		points, datas := p.takePointsLocked(), p.takeDatasLocked()
+		p.mu.Unlock()
+		p.dispatch(points)
+		p.dispatch(datas)
+	})
+}
+
+func (p *Producer) dispatch(b *producerBatch) {
+	if b == nil {
+		return
+	}
+	p.mu.Lock()
+	if len(p.points) == 0 && len(p.datas) == 0 && p.linger != nil {
+		p.linger.Stop()
+		p.linger = nil
+		p.lingerGen++
+	}
+	p.mu.Unlock()
+	p.batches <- b
+}
+
+func (p *Producer) work() {
+	defer p.workers.Done()
+	for b := range p.batches {
+		p.send(b)
+		p.mu.Lock()
+		p.inflight--
+		if p.inflight == 0 {
+			p.cond.Broadcast()
+		}
+		p.mu.Unlock()
+	}
+}
+
+func (p *Producer) send(b *producerBatch) {
+	ctx := context.Background()
+	if len(b.points) > 0 {
+		err := p.client.PostDataFromBytesWithContext(ctx, &PostDataFromBytesInput{
+			RepoName:     p.cfg.RepoName,
+			Buffer:       b.buffer,
+			PandoraToken: p.cfg.PandoraToken,
+			Tags:         p.cfg.Tags,
+		})
+		if err != nil {
+			p.fail(&ProducerError{
+				Err:    reqerr.NewSendError("Cannot send points to pandora, "+err.Error(), nil, sendErrorType(err)).WithCause(err),
+				Points: b.points,
+			})
+		}
+		return
+	}
+
+	input := *p.cfg.SchemaFree
+	input.RepoName = p.cfg.RepoName
+	input.Datas = b.datas
+	if input.Tags == nil {
+		input.Tags = p.cfg.Tags
+	}
+	_, err := p.client.PostDataSchemaFreeWithContext(ctx, &input)
+	if err == nil {
+		return
+	}
+	se, ok := err.(*reqerr.SendError)
+	if !ok {
+		se = reqerr.NewSendError("Cannot send data to pandora, "+err.Error(), convertDatas(b.datas), reqerr.TypeDefault).WithCause(err)
+	}
+	failed := make(Datas, 0, len(se.GetFailDatas()))
+	for _, d := range se.GetFailDatas() {
+		failed = append(failed, Data(d))
+	}
+	p.fail(&ProducerError{Err: se, Datas: failed})
+}
+
+func (p *Producer) fail(e *ProducerError) {
+	if p.cfg.Spool != nil {
+		var err error
+		if len(e.Points) > 0 {
+			err = p.cfg.Spool.AppendPoints(p.cfg.RepoName, p.cfg.Tags, e.Points)
+		} else {
+			err = p.cfg.Spool.AppendDatas(p.cfg.RepoName, p.cfg.Tags, e.Datas)
+		}
+		if err != nil {
+			p.logger.Log(base.LogError, "producer append to spool failed",
+				base.F(base.FieldRepo, p.cfg.RepoName), base.F(base.FieldError, err))
+		}
+	}
+	if p.cfg.OnError != nil {
+		p.cfg.OnError(e)
+		return
+	}
+	p.logger.Log(base.LogError, "producer send failed, data dropped", base.F(base.FieldRepo, p.cfg.RepoName),
+		base.F("points", len(e.Points)), base.F("datas", len(e.Datas)), base.F(base.FieldError, e.Err))
+}
+
+// sendErrorType 与 PostDataSchemaFree 中的判断保持一致，告诉调用方是否需要二分重发
+func sendErrorType(err error) reqerr.SendErrorType {
+	reqErr, ok := err.(*reqerr.RequestError)
+	if !ok {
+		return reqerr.TypeDefault
+	}
+	switch reqErr.ErrorType {
+	case reqerr.InvalidDataSchemaError, reqerr.EntityTooLargeError:
+		return reqerr.TypeBinaryUnpack
+	}
+	return reqerr.TypeDefault
+}
@@ -0,0 +1,104 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/qiniu/pandora-go-sdk/base/reqerr"
+)
+
+type fakeProducerClient struct {
+	PipelineAPI
+	mu      sync.Mutex
+	bodies  []string
+	datas   []Datas
+	failing bool
+}
+
+func (f *fakeProducerClient) PostDataFromBytesWithContext(ctx context.Context, input *PostDataFromBytesInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failing {
+		return reqerr.New("E18005", "", "", 413)
+	}
+	f.bodies = append(f.bodies, string(input.Buffer))
+	return nil
+}
+
+func (f *fakeProducerClient) PostDataSchemaFreeWithContext(ctx context.Context, input *SchemaFreeInput) (map[string]RepoSchemaEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.datas = append(f.datas, input.Datas)
+	return nil, nil
+}
+
+func TestProducerBatchBySize(t *testing.T) {
+	client := &fakeProducerClient{}
+	p, err := NewProducer(client, ProducerConfig{RepoName: "repo", BatchSize: 20, Linger: time.Hour, Workers: 2})
+	assert.NoError(t, err)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, p.SendPoint(Point{Fields: []PointField{{Key: "a", Value: "123456"}}}))
+		}()
+	}
+	wg.Wait()
+	assert.NoError(t, p.Close())
+	assert.Equal(t, ErrProducerClosed, p.SendPoint(Point{}))
+
+	lines := 0
+	for _, body := range client.bodies {
+		assert.True(t, len(body) <= 20, body)
+		for _, line := range strings.Split(body, "\n") {
+			assert.Equal(t, "a=123456", line)
+			lines++
+		}
+	}
+	assert.Equal(t, 10, lines)
+}
+
+func TestProducerLingerAndFlush(t *testing.T) {
+	client := &fakeProducerClient{}
+	p, err := NewProducer(client, ProducerConfig{
+		RepoName:   "repo",
+		Linger:     20 * time.Millisecond,
+		SchemaFree: &SchemaFreeInput{},
+	})
+	assert.NoError(t, err)
+	defer p.Close()
+
+	assert.NoError(t, p.SendData(Data{"a": 1}))
+	time.Sleep(100 * time.Millisecond)
+	client.mu.Lock()
+	assert.Equal(t, []Datas{{{"a": 1}}}, client.datas)
+	client.mu.Unlock()
+
+	assert.NoError(t, p.SendData(Data{"b": 2}))
+	assert.NoError(t, p.SendData(Data{"c": 3}))
+	p.Flush()
+	client.mu.Lock()
+	assert.Equal(t, []Datas{{{"a": 1}}, {{"b": 2}, {"c": 3}}}, client.datas)
+	client.mu.Unlock()
+}
+
+func TestProducerOnError(t *testing.T) {
+	client := &fakeProducerClient{failing: true}
+	var failed []*ProducerError
+	p, err := NewProducer(client, ProducerConfig{
+		RepoName: "repo",
+		OnError:  func(e *ProducerError) { failed = append(failed, e) },
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, p.SendPoint(Point{Fields: []PointField{{Key: "a", Value: 1}}}))
+	assert.NoError(t, p.Close())
+	assert.Len(t, failed, 1)
+	assert.Len(t, failed[0].Points, 1)
+	assert.Equal(t, reqerr.TypeDefault, failed[0].Err.ErrorType)
+}
@@ -0,0 +1,140 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/qiniu/pandora-go-sdk/base/reqerr"
+)
+
+// maxSchemaFreeRetry 是 PostDataSchemaFreeResilient 遇到 TypeSchemaFreeRetry 类型错误时的最大重试次数
+const maxSchemaFreeRetry = 3
+
+// RejectedPoint 是二分重发后仍被服务端单独拒绝的 Point 及其错误
+type RejectedPoint struct {
+	Point Point
+	Err   error
+}
+
+// RejectedData 是二分重发后仍被服务端单独拒绝的 Data 及其错误
+type RejectedData struct {
+	Data Data
+	Err  error
+}
+
+func needBinaryUnpack(errType reqerr.SendErrorType) bool {
+	return errType == reqerr.TypeBinaryUnpack || errType == reqerr.TypeContainInvalidPoint
+}
+
+// PostDataResilient 与 PostData 相同，但在服务端返回 TypeBinaryUnpack 或 TypeContainInvalidPoint 类型的错误时，
+// 把失败的数据不断二分后重发，直到找出被单独拒绝的 Point。
+// 被拒绝的 Point 通过 rejected 返回，不影响其余数据的发送；遇到其他错误时停止，
+// datafailed 为所有尚未发送成功的 Point，可以直接用于 PostData 重发，err 为 SendError，其中以 map 形式包含同样的数据
+func (c *Pipeline) PostDataResilient(input *PostDataInput) (rejected []RejectedPoint, datafailed Points, err error) {
+	return c.PostDataResilientWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) PostDataResilientWithContext(ctx context.Context, input *PostDataInput) (rejected []RejectedPoint, datafailed Points, err error) {
+	pending := []Points{input.Points}
+	for len(pending) > 0 {
+		points := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if len(points) == 0 {
+			continue
+		}
+		sub := *input
+		sub.Points = points
+		sendErr := c.PostDataWithContext(ctx, &sub)
+		if sendErr == nil {
+			continue
+		}
+		if needBinaryUnpack(sendErrorType(sendErr)) {
+			if len(points) == 1 {
+				rejected = append(rejected, RejectedPoint{Point: points[0], Err: sendErr})
+				continue
+			}
+			mid := len(points) / 2
+			pending = append(pending, points[mid:], points[:mid])
+			continue
+		}
+
+		datafailed = append(datafailed, points...)
+		for i := len(pending) - 1; i >= 0; i-- {
+			datafailed = append(datafailed, pending[i]...)
+		}
+		failed := make([]map[string]interface{}, 0, len(datafailed))
+		for _, p := range datafailed {
+			failed = append(failed, pointToMap(p))
+		}
+		err = reqerr.NewSendError("Cannot send data to pandora, "+sendErr.Error(), failed, reqerr.TypeDefault).WithCause(sendErr)
+		return
+	}
+	return
+}
+
+func pointToMap(p Point) map[string]interface{} {
+	m := make(map[string]interface{}, len(p.Fields))
+	for _, f := range p.Fields {
+		m[f.Key] = f.Value
+	}
+	return m
+}
+
+// PostDataSchemaFreeResilient 与 PostDataSchemaFree 相同，但在返回 TypeBinaryUnpack 或 TypeContainInvalidPoint 类型的
+// SendError 时，把失败的数据不断二分后重发，直到找出被单独拒绝的 Data，TypeSchemaFreeRetry 类型的错误会直接重试。
+// 被拒绝的 Data 通过 rejected 返回；遇到其他错误时停止，err 为 SendError，其中包含所有尚未发送成功的数据
+func (c *Pipeline) PostDataSchemaFreeResilient(input *SchemaFreeInput) (rejected []RejectedData, newSchemas map[string]RepoSchemaEntry, err error) {
+	return c.PostDataSchemaFreeResilientWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) PostDataSchemaFreeResilientWithContext(ctx context.Context, input *SchemaFreeInput) (rejected []RejectedData, newSchemas map[string]RepoSchemaEntry, err error) {
+	pending := []Datas{input.Datas}
+	retries := 0
+	for len(pending) > 0 {
+		datas := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if len(datas) == 0 {
+			continue
+		}
+		sub := *input
+		sub.Datas = datas
+		schemas, sendErr := c.PostDataSchemaFreeWithContext(ctx, &sub)
+		if schemas != nil {
+			newSchemas = schemas
+		}
+		if sendErr == nil {
+			continue
+		}
+		se, ok := sendErr.(*reqerr.SendError)
+		if !ok {
+			se = reqerr.NewSendError("Cannot send data to pandora, "+sendErr.Error(), convertDatas(datas), reqerr.TypeDefault).WithCause(sendErr)
+		}
+		failed := make(Datas, 0, len(se.GetFailDatas()))
+		for _, d := range se.GetFailDatas() {
+			failed = append(failed, Data(d))
+		}
+		switch {
+		case len(failed) == 0:
+			continue
+		case se.ErrorType == reqerr.TypeSchemaFreeRetry && retries < maxSchemaFreeRetry:
+			retries++
+			pending = append(pending, failed)
+			continue
+		case needBinaryUnpack(se.ErrorType):
+			if len(failed) == 1 {
+				rejected = append(rejected, RejectedData{Data: failed[0], Err: se})
+				continue
+			}
+			mid := len(failed) / 2
+			pending = append(pending, failed[mid:], failed[:mid])
+			continue
+		}
+
+		var remain []map[string]interface{}
+		for i := len(pending) - 1; i >= 0; i-- {
+			remain = append(remain, convertDatas(pending[i])...)
+		}
+		err = reqerr.NewSendError("Cannot send data to pandora, "+se.Error(), append(convertDatas(failed), remain...), se.ErrorType).WithCause(se)
+		return
+	}
+	return
+}
@@ -0,0 +1,107 @@
+package pipeline
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/qiniu/pandora-go-sdk/base/reqerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostDataResilient(t *testing.T) {
+	var mu sync.Mutex
+	var accepted []string
+	down := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("service unavailable"))
+			return
+		}
+		if strings.Contains(string(body), "bad") {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("E18125: invalid data"))
+			return
+		}
+		accepted = append(accepted, strings.Split(string(body), "\n")...)
+	}))
+	defer srv.Close()
+
+	client, err := NewDefaultClient(NewConfig().WithPipelineEndpoint(srv.URL).WithAccessKeySecretKey("ak", "sk"))
+	assert.NoError(t, err)
+
+	values := []string{"a", "bad1", "b", "c", "d", "bad2", "e"}
+	input := &PostDataInput{RepoName: "repo"}
+	for _, v := range values {
+		input.Points = append(input.Points, Point{Fields: []PointField{{Key: "f", Value: v}}})
+	}
+	rejected, datafailed, err := client.PostDataResilient(input)
+	assert.NoError(t, err)
+	assert.Empty(t, datafailed)
+	assert.Len(t, rejected, 2)
+	assert.Equal(t, "bad1", rejected[0].Point.Fields[0].Value)
+	assert.Equal(t, "bad2", rejected[1].Point.Fields[0].Value)
+	assert.Equal(t, reqerr.InvalidDataSchemaError, rejected[0].Err.(*reqerr.RequestError).ErrorType)
+	assert.Equal(t, []string{"f=a", "f=b", "f=c", "f=d", "f=e"}, accepted)
+
+	down = true
+	rejected, datafailed, err = client.PostDataResilient(input)
+	assert.Empty(t, rejected)
+	se, ok := err.(*reqerr.SendError)
+	assert.True(t, ok)
+	assert.Len(t, se.GetFailDatas(), len(values))
+	assert.Equal(t, input.Points, datafailed)
+
+	// 未发送成功的 Point 可以直接重发
+	down = false
+	accepted = nil
+	assert.NoError(t, client.PostData(&PostDataInput{RepoName: "repo", Points: datafailed[:1]}))
+	rejected, datafailed, err = client.PostDataResilient(&PostDataInput{RepoName: "repo", Points: datafailed[2:]})
+	assert.NoError(t, err)
+	assert.Len(t, rejected, 1)
+	assert.Empty(t, datafailed)
+	assert.Equal(t, []string{"f=a", "f=b", "f=c", "f=d", "f=e"}, accepted)
+}
+
+func TestPostDataSchemaFreeResilientRetry(t *testing.T) {
+	var mu sync.Mutex
+	posts, failures := 0, 2
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		posts++
+		if posts <= failures {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("E18111: field key does not exist in repo schema"))
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewDefaultClient(NewConfig().WithPipelineEndpoint(srv.URL).WithAccessKeySecretKey("ak", "sk"))
+	assert.NoError(t, err)
+	client.repoSchemas["repo"] = RepoSchema{"a": {Key: "a", ValueType: PandoraTypeLong}}
+	input := &SchemaFreeInput{RepoName: "repo", Datas: Datas{{"a": 1}, {"a": 2}}}
+
+	// TypeSchemaFreeRetry 类型的错误直接重试，之后发送成功
+	rejected, _, err := client.PostDataSchemaFreeResilient(input)
+	assert.NoError(t, err)
+	assert.Empty(t, rejected)
+	assert.Equal(t, failures+1, posts)
+
+	// 超过 maxSchemaFreeRetry 次后返回包含所有数据的 SendError
+	posts, failures = 0, maxSchemaFreeRetry+1
+	rejected, _, err = client.PostDataSchemaFreeResilient(input)
+	assert.Empty(t, rejected)
+	assert.Equal(t, maxSchemaFreeRetry+1, posts)
+	se, ok := err.(*reqerr.SendError)
+	assert.True(t, ok)
+	assert.Equal(t, reqerr.TypeSchemaFreeRetry, se.ErrorType)
+	assert.Len(t, se.GetFailDatas(), 2)
+}
@@ -1,6 +1,7 @@
 package pipeline
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -20,8 +21,8 @@ const (
 	maxMapLevel = 5
 )
 
-func (c *Pipeline) getSchemas(repoName string, token PandoraToken) (schemas map[string]RepoSchemaEntry, err error) {
-	repo, err := c.GetRepo(&GetRepoInput{
+func (c *Pipeline) getSchemas(ctx context.Context, repoName string, token PandoraToken) (schemas map[string]RepoSchemaEntry, err error) {
+	repo, err := c.GetRepoWithContext(ctx, &GetRepoInput{
 		RepoName:     repoName,
 		PandoraToken: token,
 	})
@@ -454,16 +455,13 @@ func changeElemType(entry RepoSchemaEntry, srcType, dstType string) RepoSchemaEn
 	return entry
 }
 
-func (c *Pipeline) generatePoint(oldData Data, input *SchemaFreeInput) (point Point, repoUpdate bool, err error) {
-	// copyAndConvertData 函数会将包含'-'的 key 用 '_' 来代替
-	// 同时该函数会去除数据中无法判断类型的部分
-	data := copyAndConvertData(oldData, 1)
-	point = Point{}
+// cachedSchemas 返回缓存的 repo schema，没有缓存时从服务端获取，repo 不存在时为空
+func (c *Pipeline) cachedSchemas(ctx context.Context, input *SchemaFreeInput) (schemas RepoSchema, err error) {
 	c.repoSchemaMux.Lock()
-	schemas := c.repoSchemas[input.RepoName]
+	schemas = c.repoSchemas[input.RepoName]
 	c.repoSchemaMux.Unlock()
 	if schemas == nil {
-		if schemas, err = c.getSchemas(input.RepoName, input.PipelineGetRepoToken); err != nil {
+		if schemas, err = c.getSchemas(ctx, input.RepoName, input.PipelineGetRepoToken); err != nil {
 			reqe, ok := err.(*reqerr.RequestError)
 			if ok && reqe.ErrorType != reqerr.NoSuchRepoError {
 				return
@@ -476,6 +474,64 @@ func (c *Pipeline) generatePoint(oldData Data, input *SchemaFreeInput) (point Po
 		c.repoSchemas[input.RepoName] = schemas
 		c.repoSchemaMux.Unlock()
 	}
+	return
+}
+
+// sampleSchemas 根据前 SampleSize 条数据推断 repo 中还不存在的字段的类型并加入缓存的 schema，
+// 之后逐条处理数据时这些字段的类型已经确定，不会因为第一条数据的类型导致后面的数据类型冲突
+func (c *Pipeline) sampleSchemas(ctx context.Context, input *SchemaFreeInput) (repoUpdate bool, err error) {
+	inferer := input.Option.schemaInferer()
+	if input.NoUpdate || inferer.SampleSize <= 0 {
+		return
+	}
+	schemas, err := c.cachedSchemas(ctx, input)
+	if err != nil {
+		return
+	}
+	sampled := inferer.InferDatas(input.Datas)
+	for key := range sampled {
+		if _, ok := schemas[key]; ok {
+			delete(sampled, key)
+		}
+	}
+	input.Option.useFloat(sampled)
+	return c.addRepoSchemas(sampled, input.RepoName)
+}
+
+// generatePoint 生成 data 对应的 point，deadLetterKey 不为空时表示 data 中该字段存在类型冲突，data 需要写入死信 repo
+func (c *Pipeline) generatePoint(ctx context.Context, oldData Data, input *SchemaFreeInput) (point Point, repoUpdate bool, deadLetterKey string, err error) {
+	// copyAndConvertData 函数会将包含'-'的 key 用 '_' 来代替
+	// 同时该函数会去除数据中无法判断类型的部分
+	data := copyAndConvertData(oldData, 1)
+	point = Point{}
+	schemas, err := c.cachedSchemas(ctx, input)
+	if err != nil {
+		return
+	}
+	inferer := input.Option.schemaInferer()
+	if input.Option.typeConflictStrategy() != TypeConflictFail {
+		// 先处理类型冲突，改名后的字段再按照 schema 或 schemaFree 处理
+		keys := make([]string, 0, len(data))
+		for name := range data {
+			keys = append(keys, name)
+		}
+		for _, name := range keys {
+			v, ok := schemas[name]
+			if !ok || !inferer.isTypeConflict(data[name], v) {
+				continue
+			}
+			value, keep, deadLetter := c.resolveTypeConflict(input, data, schemas, name, data[name], v)
+			if deadLetter {
+				deadLetterKey = name
+				return
+			}
+			if keep {
+				data[name] = value
+			}
+		}
+	}
+	// 处理类型冲突之后再转换时间格式，改名后的字段也需要转换
+	inferer.normalizeDates(data, schemas)
 	for name, v := range schemas {
 		value, ok := data[name]
 		if !ok {
@@ -524,13 +580,9 @@ func (c *Pipeline) generatePoint(oldData Data, input *SchemaFreeInput) (point Po
 	if !input.NoUpdate && haveNewData(data) {
 		//defaultAll 为false时，过滤一批不要的
 		// 该函数有两个作用，1. 获取 data 中所有字段的 schema; 2. 将 data 中值为 nil, 无法判断类型的键值对，从 data 中删掉
-		valueType := GetTrimedDataSchema(data)
-		// 将 metric 中的 long 都改成 float
-		if input.Option != nil && (input.Option.NumberUseFloat || input.Option.IsMetric) {
-			for key, val := range valueType {
-				valueType[key] = changeElemType(val, PandoraTypeLong, PandoraTypeFloat)
-			}
-		}
+		valueType := inferer.Infer(data)
+		inferer.normalizeDates(data, valueType)
+		input.Option.useFloat(valueType)
 		if repoUpdate, err = c.addRepoSchemas(valueType, input.RepoName); err != nil {
 			err = fmt.Errorf("schemafree add Repo schema error %v", err)
 			return
@@ -631,12 +683,12 @@ type WorkflowTokens struct {
 	GetWorkflowStatusToken PandoraToken
 }
 
-func (c *Pipeline) changeWorkflowToStopped(workflow *GetWorkflowOutput, waitStopped bool, tokens WorkflowTokens, isNeedStart *bool) error {
+func (c *Pipeline) changeWorkflowToStopped(ctx context.Context, workflow *GetWorkflowOutput, waitStopped bool, tokens WorkflowTokens, isNeedStart *bool) error {
 	logger := base.NewDefaultLogger()
 	switch workflow.Status {
 	case base.WorkflowStarted:
 		*isNeedStart = true
-		if err := c.StopWorkflow(&StopWorkflowInput{WorkflowName: workflow.Name, PandoraToken: tokens.StopWorkflowToken}); err != nil {
+		if err := c.StopWorkflowWithContext(ctx, &StopWorkflowInput{WorkflowName: workflow.Name, PandoraToken: tokens.StopWorkflowToken}); err != nil {
 			return err
 		}
 	case base.WorkflowStarting:
@@ -644,7 +696,7 @@ func (c *Pipeline) changeWorkflowToStopped(workflow *GetWorkflowOutput, waitStop
 			return err
 		}
 		*isNeedStart = true
-		if err := c.StopWorkflow(&StopWorkflowInput{WorkflowName: workflow.Name, PandoraToken: tokens.StopWorkflowToken}); err != nil {
+		if err := c.StopWorkflowWithContext(ctx, &StopWorkflowInput{WorkflowName: workflow.Name, PandoraToken: tokens.StopWorkflowToken}); err != nil {
 			return err
 		}
 	case base.WorkflowStopping:
@@ -663,18 +715,18 @@ func (c *Pipeline) changeWorkflowToStopped(workflow *GetWorkflowOutput, waitStop
 	return nil
 }
 
-func (c *Pipeline) changeWorkflowToStarted(workflow *GetWorkflowOutput, waitStarted bool, tokens WorkflowTokens) error {
+func (c *Pipeline) changeWorkflowToStarted(ctx context.Context, workflow *GetWorkflowOutput, waitStarted bool, tokens WorkflowTokens) error {
 	logger := base.NewDefaultLogger()
 	switch workflow.Status {
 	case base.WorkflowReady, base.WorkflowStopped:
-		if err := c.StartWorkflow(&StartWorkflowInput{WorkflowName: workflow.Name, PandoraToken: tokens.StartWorkflowToken}); err != nil {
+		if err := c.StartWorkflowWithContext(ctx, &StartWorkflowInput{WorkflowName: workflow.Name, PandoraToken: tokens.StartWorkflowToken}); err != nil {
 			return err
 		}
 	case base.WorkflowStopping:
 		if err := WaitWorkflowStopped(workflow.Name, c, logger, tokens.GetWorkflowStatusToken); err != nil {
 			return err
 		}
-		if err := c.StartWorkflow(&StartWorkflowInput{WorkflowName: workflow.Name, PandoraToken: tokens.StartWorkflowToken}); err != nil {
+		if err := c.StartWorkflowWithContext(ctx, &StartWorkflowInput{WorkflowName: workflow.Name, PandoraToken: tokens.StartWorkflowToken}); err != nil {
 			return err
 		}
 	case base.WorkflowStarting:
@@ -700,8 +752,8 @@ func (c *Pipeline) changeWorkflowToStarted(workflow *GetWorkflowOutput, waitStar
 //	2) 如果创建 workflow 有错误，并且错误不是 workflow 已经存在, 直接返回错误
 //	3) 如果创建 workflow 没有错误，即创建成功，此时记录需要自动启动新建的 workflow, 并填充 workflow 的名称和当前的状态
 //2. 获取 workflow 有错误，并且不是 workflow 不存在的错误, 直接返回错误
-func (c *Pipeline) getOrCreateWorkflow(input *InitOrUpdateWorkflowInput, ns *bool) (workflow *GetWorkflowOutput, err error) {
-	workflow, err = c.GetWorkflow(&GetWorkflowInput{
+func (c *Pipeline) getOrCreateWorkflow(ctx context.Context, input *InitOrUpdateWorkflowInput, ns *bool) (workflow *GetWorkflowOutput, err error) {
+	workflow, err = c.GetWorkflowWithContext(ctx, &GetWorkflowInput{
 		WorkflowName: input.WorkflowName,
 		PandoraToken: input.PipelineGetWorkflowToken,
 	})
@@ -714,8 +766,8 @@ func (c *Pipeline) getOrCreateWorkflow(input *InitOrUpdateWorkflowInput, ns *boo
 		if input.Description != nil {
 			createWorkflowInput.Comment = *input.Description
 		}
-		if err = c.CreateWorkflow(createWorkflowInput); err != nil && reqerr.IsExistError(err) {
-			workflow, err = c.GetWorkflow(&GetWorkflowInput{
+		if err = c.CreateWorkflowWithContext(ctx, createWorkflowInput); err != nil && reqerr.IsExistError(err) {
+			workflow, err = c.GetWorkflowWithContext(ctx, &GetWorkflowInput{
 				WorkflowName: input.WorkflowName,
 				PandoraToken: input.PipelineGetWorkflowToken,
 			})
@@ -738,8 +790,8 @@ func (c *Pipeline) getOrCreateWorkflow(input *InitOrUpdateWorkflowInput, ns *boo
 //1. 如果有错误, 且错误为 workflow 状态不允许创建: 停止 workflow 停止 workflow 时出现错误直接返回
 //	停止 workflow 后, 重新 create repo, 并在接下来的逻辑中处理可能出现的错误
 //2. 第一次 create 或者重试 create 如果有错误, 且错误为 repo 已经存在: 获取 repo, merge schema, 更新 repo
-func (c *Pipeline) createOrUpdateRepo(input *InitOrUpdateWorkflowInput, workflow *GetWorkflowOutput, ns *bool) (err error) {
-	err = c.CreateRepo(&CreateRepoInput{
+func (c *Pipeline) createOrUpdateRepo(ctx context.Context, input *InitOrUpdateWorkflowInput, workflow *GetWorkflowOutput, ns *bool) (err error) {
+	err = c.CreateRepoWithContext(ctx, &CreateRepoInput{
 		RepoName:     input.RepoName,
 		Region:       input.Region,
 		Schema:       input.Schema,
@@ -750,7 +802,7 @@ func (c *Pipeline) createOrUpdateRepo(input *InitOrUpdateWorkflowInput, workflow
 	})
 	if err != nil && reqerr.IsWorkflowStatError(err) {
 		// 如果当前 workflow 的状态不允许更新，则先等待停止 workflow 再更新
-		if subErr := c.changeWorkflowToStopped(workflow, true, WorkflowTokens{
+		if subErr := c.changeWorkflowToStopped(ctx, workflow, true, WorkflowTokens{
 			StartWorkflowToken:     input.PipelineStartWorkflowToken,
 			StopWorkflowToken:      input.PipelineStopWorkflowToken,
 			GetWorkflowStatusToken: input.PipelineGetWorkflowStatusToken,
@@ -758,7 +810,7 @@ func (c *Pipeline) createOrUpdateRepo(input *InitOrUpdateWorkflowInput, workflow
 			log.Errorf("changeWorkflowToStopped from pipeline err %v", subErr)
 			return subErr
 		}
-		err = c.CreateRepo(&CreateRepoInput{
+		err = c.CreateRepoWithContext(ctx, &CreateRepoInput{
 			RepoName:     input.RepoName,
 			Region:       input.Region,
 			Schema:       input.Schema,
@@ -769,7 +821,7 @@ func (c *Pipeline) createOrUpdateRepo(input *InitOrUpdateWorkflowInput, workflow
 		})
 	}
 	if err != nil && reqerr.IsExistError(err) {
-		repo, subErr := c.GetRepo(&GetRepoInput{
+		repo, subErr := c.GetRepoWithContext(ctx, &GetRepoInput{
 			RepoName:     input.RepoName,
 			PandoraToken: input.PipelineGetRepoToken,
 		})
@@ -782,7 +834,7 @@ func (c *Pipeline) createOrUpdateRepo(input *InitOrUpdateWorkflowInput, workflow
 			return subErr
 		}
 		if needUpdate {
-			if err = c.updateRepo(&UpdateRepoInput{
+			if err = c.updateRepo(ctx, &UpdateRepoInput{
 				RepoName:             input.RepoName,
 				Schema:               schemas,
 				Option:               input.Option,
@@ -809,6 +861,10 @@ func (c *Pipeline) createOrUpdateRepo(input *InitOrUpdateWorkflowInput, workflow
 // 4. 确保 workflow 处于启动状态，每次发送数据前不再确认 workflow 是否是启动状态
 // 注意: 处于兼容性考虑，未删除 AutoExportTo* 这些函数的 GetRepo 请求，即每个函数额外请求一次
 func (c *Pipeline) InitOrUpdateWorkflow(input *InitOrUpdateWorkflowInput) error {
+	return c.InitOrUpdateWorkflowWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) InitOrUpdateWorkflowWithContext(ctx context.Context, input *InitOrUpdateWorkflowInput) error {
 	if input.RepoName == "" {
 		return fmt.Errorf("repo name can not be empty")
 	}
@@ -816,7 +872,7 @@ func (c *Pipeline) InitOrUpdateWorkflow(input *InitOrUpdateWorkflowInput) error
 		input.Option.AutoExportToLogDBInput.Region = input.Region
 	}
 	// 获取 repo
-	repo, err := c.GetRepo(&GetRepoInput{
+	repo, err := c.GetRepoWithContext(ctx, &GetRepoInput{
 		RepoName:     input.RepoName,
 		PandoraToken: input.PipelineGetRepoToken,
 	})
@@ -831,12 +887,12 @@ func (c *Pipeline) InitOrUpdateWorkflow(input *InitOrUpdateWorkflowInput) error
 		var workflow *GetWorkflowOutput
 		if input.WorkflowName != "" {
 			// 如果导出到 dag, 确保 workflow 存在, 不存在时创建
-			if workflow, err = c.getOrCreateWorkflow(input, needStartWorkflow); err != nil {
+			if workflow, err = c.getOrCreateWorkflow(ctx, input, needStartWorkflow); err != nil {
 				return err
 			}
 			defer func() {
 				if *needStartWorkflow {
-					if err := c.changeWorkflowToStarted(workflow, false, WorkflowTokens{
+					if err := c.changeWorkflowToStarted(ctx, workflow, false, WorkflowTokens{
 						StartWorkflowToken:     input.PipelineStartWorkflowToken,
 						StopWorkflowToken:      input.PipelineStopWorkflowToken,
 						GetWorkflowStatusToken: input.PipelineGetWorkflowStatusToken,
@@ -851,24 +907,24 @@ func (c *Pipeline) InitOrUpdateWorkflow(input *InitOrUpdateWorkflowInput) error
 			}()
 		}
 		// repo 不存在且传入了非空的 schema, 此时要新建 repo
-		if err = c.createOrUpdateRepo(input, workflow, needStartWorkflow); err != nil {
+		if err = c.createOrUpdateRepo(ctx, input, workflow, needStartWorkflow); err != nil {
 			return err
 		}
 		// 创建、更新各种导出
 		if input.Option != nil && input.Option.ToLogDB {
-			if err := c.AutoExportToLogDB(&input.Option.AutoExportToLogDBInput); err != nil {
+			if err := c.AutoExportToLogDBWithContext(ctx, &input.Option.AutoExportToLogDBInput); err != nil {
 				log.Error("create repo and AutoExportToLogDB err: ", err)
 				return err
 			}
 		}
 		if input.Option != nil && input.Option.ToKODO {
-			if err := c.AutoExportToKODO(&input.Option.AutoExportToKODOInput); err != nil {
+			if err := c.AutoExportToKODOWithContext(ctx, &input.Option.AutoExportToKODOInput); err != nil {
 				log.Error("create repo and AutoExportToKODO err: ", err)
 				return err
 			}
 		}
 		if input.Option != nil && input.Option.ToTSDB {
-			if err := c.AutoExportToTSDB(&input.Option.AutoExportToTSDBInput); err != nil {
+			if err := c.AutoExportToTSDBWithContext(ctx, &input.Option.AutoExportToTSDBInput); err != nil {
 				log.Error("create repo and AutoExportToTSDB err: ", err)
 				return err
 			}
@@ -882,7 +938,7 @@ func (c *Pipeline) InitOrUpdateWorkflow(input *InitOrUpdateWorkflowInput) error
 		defer func() {
 			// 如果 repo 已经存在, repo 本身的 fromDag 字段就表明了是否来自workflow
 			if repo.FromDag && *needStartWorkflow {
-				workflow, err := c.GetWorkflow(&GetWorkflowInput{
+				workflow, err := c.GetWorkflowWithContext(ctx, &GetWorkflowInput{
 					WorkflowName: repo.Workflow,
 					PandoraToken: input.PipelineGetWorkflowToken,
 				})
@@ -890,7 +946,7 @@ func (c *Pipeline) InitOrUpdateWorkflow(input *InitOrUpdateWorkflowInput) error
 					log.Errorf("InitOrUpdateWorkflow get workflow from pipeline err: %v", err)
 					return
 				}
-				if err := c.changeWorkflowToStarted(workflow, false, WorkflowTokens{
+				if err := c.changeWorkflowToStarted(ctx, workflow, false, WorkflowTokens{
 					StartWorkflowToken:     input.PipelineStartWorkflowToken,
 					StopWorkflowToken:      input.PipelineStopWorkflowToken,
 					GetWorkflowStatusToken: input.PipelineGetWorkflowStatusToken,
@@ -920,10 +976,10 @@ func (c *Pipeline) InitOrUpdateWorkflow(input *InitOrUpdateWorkflowInput) error
 				PandoraToken:         input.PipelineUpdateRepoToken,
 				PipelineGetRepoToken: input.PipelineGetRepoToken,
 			}
-			if err := c.UpdateRepo(updateRepoInput); err != nil {
+			if err := c.UpdateRepoWithContext(ctx, updateRepoInput); err != nil {
 				if reqerr.IsWorkflowStatError(err) {
 					// 如果当前 workflow 的状态不允许更新，则先等待停止 workflow 再更新
-					workflow, subErr := c.GetWorkflow(&GetWorkflowInput{
+					workflow, subErr := c.GetWorkflowWithContext(ctx, &GetWorkflowInput{
 						WorkflowName: updateRepoInput.workflow,
 						PandoraToken: input.PipelineGetWorkflowToken,
 					})
@@ -931,7 +987,7 @@ func (c *Pipeline) InitOrUpdateWorkflow(input *InitOrUpdateWorkflowInput) error
 						log.Errorf("InitOrUpdateWorkflow get workflow from pipeline err: %v", subErr)
 						return subErr
 					}
-					if subErr := c.changeWorkflowToStopped(workflow, true, WorkflowTokens{
+					if subErr := c.changeWorkflowToStopped(ctx, workflow, true, WorkflowTokens{
 						StartWorkflowToken:     input.PipelineStartWorkflowToken,
 						StopWorkflowToken:      input.PipelineStopWorkflowToken,
 						GetWorkflowStatusToken: input.PipelineGetWorkflowStatusToken,
@@ -939,7 +995,7 @@ func (c *Pipeline) InitOrUpdateWorkflow(input *InitOrUpdateWorkflowInput) error
 						log.Errorf("InitOrUpdateWorkflow change workflow to stopped err: %v", err)
 						return subErr
 					}
-					if subErr = c.UpdateRepo(updateRepoInput); subErr != nil {
+					if subErr = c.UpdateRepoWithContext(ctx, updateRepoInput); subErr != nil {
 						return subErr
 					}
 				} else if err != nil {
@@ -989,116 +1045,9 @@ PandoraTypeArray  ：全部支持
 PandoraTypeMap    ：全部支持
 */
 // 该函数有两个作用，1. 获取 data 中所有字段的 schema; 2. 将 data 中值为 nil, 无法判断类型的键值对，从 data 中删掉
-// 当值为string的时候，如果数据满足date格式，则推断为PandoraTypeDate，如果数据满足IP格式，则推断为PandoraTypeIP
+// 当值为string的时候，如果数据满足rfc3339格式，则推断为PandoraTypeDate；需要推断IP、map或其他时间格式时使用 SchemaInferer
 func GetTrimedDataSchema(data Data) (valueType map[string]RepoSchemaEntry) {
-	valueType = make(map[string]RepoSchemaEntry)
-	for k, v := range data {
-		switch nv := v.(type) {
-		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-			valueType[k] = formValueType(k, PandoraTypeLong)
-		case float32, float64:
-			valueType[k] = formValueType(k, PandoraTypeFloat)
-		case bool:
-			valueType[k] = formValueType(k, PandoraTypeBool)
-		case json.Number:
-			_, err := nv.Int64()
-			if err == nil {
-				valueType[k] = formValueType(k, PandoraTypeLong)
-			} else {
-				valueType[k] = formValueType(k, PandoraTypeFloat)
-			}
-		case map[string]interface{}:
-			follows := GetTrimedDataSchema(Data(nv))
-			if len(follows) == 0 {
-				// 由于内层数据为空，所以从数据中将该条键值对删掉
-				delete(data, k)
-				continue
-			}
-			valueType[k] = formValueType(k, PandoraTypeJsonString)
-		case map[string]string:
-			isEmpty := true
-			for _, nvVal := range nv {
-				if nvVal != "" {
-					isEmpty = false
-					break
-				}
-			}
-			if isEmpty {
-				continue
-			}
-			valueType[k] = formValueType(k, PandoraTypeJsonString)
-		case []interface{}:
-			sc := formValueType(k, PandoraTypeArray)
-			if len(nv) > 0 {
-				switch nnv := nv[0].(type) {
-				case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-					sc.ElemType = PandoraTypeLong
-				case float32, float64:
-					sc.ElemType = PandoraTypeFloat
-				case bool:
-					sc.ElemType = PandoraTypeBool
-				case json.Number:
-					_, err := nnv.Int64()
-					if err == nil {
-						sc.ElemType = PandoraTypeLong
-					} else {
-						sc.ElemType = PandoraTypeFloat
-					}
-				case nil:
-					// 由于数据为空，且无法判断类型, 所以从数据中将该条键值对删掉
-					if len(nv) == 1 {
-						delete(data, k)
-					} else {
-						sc.ElemType = PandoraTypeString
-					}
-				case string:
-					sc.ElemType = PandoraTypeString
-				default:
-					sc.ElemType = PandoraTypeString
-				}
-				valueType[k] = sc
-			} else {
-				// 由于数据为空，且无法判断类型, 所以从数据中将该条键值对删掉
-				delete(data, k)
-			}
-		case []int, []int8, []int16, []int32, []int64, []uint, []uint8, []uint16, []uint32, []uint64:
-			sc := formValueType(k, PandoraTypeArray)
-			sc.ElemType = PandoraTypeLong
-			valueType[k] = sc
-		case []float32, []float64:
-			sc := formValueType(k, PandoraTypeArray)
-			sc.ElemType = PandoraTypeFloat
-			valueType[k] = sc
-		case []bool:
-			sc := formValueType(k, PandoraTypeArray)
-			sc.ElemType = PandoraTypeBool
-			valueType[k] = sc
-		case []string:
-			sc := formValueType(k, PandoraTypeArray)
-			sc.ElemType = PandoraTypeString
-			valueType[k] = sc
-		case []json.Number:
-			sc := formValueType(k, PandoraTypeArray)
-			sc.ElemType = PandoraTypeFloat
-			valueType[k] = sc
-		case nil:
-			// 由于数据为空，且无法判断类型, 所以从数据中将该条键值对删掉
-			delete(data, k)
-		case string:
-			// 如果数据满足date格式，则推断为PandoraTypeDate
-			if _, err := time.Parse(time.RFC3339, nv); err == nil {
-				valueType[k] = formValueType(k, PandoraTypeDate)
-			} else {
-				valueType[k] = formValueType(k, PandoraTypeString)
-			}
-		case time.Time, *time.Time:
-			valueType[k] = formValueType(k, PandoraTypeDate)
-		default:
-			valueType[k] = formValueType(k, PandoraTypeString)
-			log.Warnf("find undetected key(%v)-type(%v), read it as string", k, reflect.TypeOf(v))
-		}
-	}
-	return
+	return defaultInferer.Infer(data)
 }
 
 func formValueType(key, vtype string) RepoSchemaEntry {
@@ -1139,20 +1088,26 @@ func getDefault(t RepoSchemaEntry) (result interface{}) {
 	return
 }
 
-func (c *Pipeline) getSchemaSorted(input *UpdateRepoInput) (err error) {
-	repo, err := c.GetRepo(&GetRepoInput{
+func (c *Pipeline) getSchemaSorted(ctx context.Context, input *UpdateRepoInput) (err error) {
+	repo, err := c.GetRepoWithContext(ctx, &GetRepoInput{
 		RepoName:     input.RepoName,
 		PandoraToken: input.PipelineGetRepoToken,
 	})
 	if err != nil {
 		return
 	}
+	input.Schema = updatedSchema(repo.Schema, input.Schema)
+	return
+}
+
+// updatedSchema 返回 UpdateRepo 之后 repo 的 schema：update 中的字段替换 old 中的同名字段，新字段追加在后面，old 中的其他字段保留
+func updatedSchema(old, update []RepoSchemaEntry) []RepoSchemaEntry {
 	mschemas := make(map[string]RepoSchemaEntry)
-	for _, sc := range input.Schema {
+	for _, sc := range update {
 		mschemas[sc.Key] = sc
 	}
 	var schemas []RepoSchemaEntry
-	for _, old := range repo.Schema {
+	for _, old := range old {
 		new, ok := mschemas[old.Key]
 		if ok {
 			schemas = append(schemas, new)
@@ -1161,9 +1116,11 @@ func (c *Pipeline) getSchemaSorted(input *UpdateRepoInput) (err error) {
 			schemas = append(schemas, old)
 		}
 	}
-	for _, v := range mschemas {
-		schemas = append(schemas, v)
+	for _, v := range update {
+		if _, ok := mschemas[v.Key]; ok {
+			schemas = append(schemas, v)
+			delete(mschemas, v.Key)
+		}
 	}
-	input.Schema = schemas
-	return
+	return schemas
 }
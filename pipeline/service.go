@@ -1,12 +1,10 @@
 package pipeline
 
 import (
-	"crypto/tls"
+	"context"
 	"fmt"
-	"net"
 	"net/http"
 	"sync"
-	"time"
 
 	"github.com/qiniu/pandora-go-sdk/base"
 	"github.com/qiniu/pandora-go-sdk/base/config"
@@ -19,13 +17,19 @@ import (
 var builder PipelineErrBuilder
 
 type Pipeline struct {
-	Config        *config.Config
-	HTTPClient    *http.Client
-	reqLimit      *ratelimit.Limiter
-	flowLimit     *ratelimit.Limiter
-	repoSchemas   map[string]RepoSchema
-	repoSchemaMux sync.Mutex
-	defaultRegion string
+	Config           *config.Config
+	HTTPClient       *http.Client
+	reqLimit         *ratelimit.Limiter
+	flowLimit        *ratelimit.Limiter
+	reqBuckets       *ratelimit.Group
+	flowBuckets      *ratelimit.Group
+	repoSchemas      map[string]RepoSchema
+	repoSchemaMux    sync.Mutex
+	repoRegions      map[string]string
+	repoRegionMux    sync.Mutex
+	conflictStats    map[string]*TypeConflictStats
+	conflictStatsMux sync.Mutex
+	defaultRegion    string
 
 	//如果不使用schemafree 和 autoexport接口，以下可以不创建
 	LogDB logdb.LogdbAPI
@@ -55,6 +59,10 @@ func (c *Pipeline) Close() (err error) {
 			c.Config.Logger.Errorf("Close flowLimit error %v", err)
 		}
 	}
+	if c.reqBuckets != nil {
+		c.reqBuckets.Close()
+		c.flowBuckets.Close()
+	}
 	return
 }
 
@@ -69,17 +77,6 @@ func NewDefaultClient(c *config.Config) (p *Pipeline, err error) {
 	if err = base.CheckEndPoint(c.PipelineEndpoint); err != nil {
 		return
 	}
-	var t = &http.Transport{
-		Dial: (&net.Dialer{
-			Timeout:   c.DialTimeout,
-			KeepAlive: 30 * time.Second,
-		}).Dial,
-		ResponseHeaderTimeout: c.ResponseTimeout,
-		TLSClientConfig:       &tls.Config{},
-	}
-	if c.AllowInsecureServer {
-		t.TLSClientConfig.InsecureSkipVerify = true
-	}
 
 	region := defaultRegion
 	if c.DefaultRegion != "" {
@@ -87,23 +84,60 @@ func NewDefaultClient(c *config.Config) (p *Pipeline, err error) {
 	}
 	p = &Pipeline{
 		Config:        c,
-		HTTPClient:    &http.Client{Transport: t},
+		HTTPClient:    request.NewHTTPClient(c),
 		repoSchemas:   make(map[string]RepoSchema),
 		repoSchemaMux: sync.Mutex{},
+		repoRegions:   make(map[string]string),
 		defaultRegion: region,
 	}
 
 	if c.RequestRateLimit > 0 {
-		p.reqLimit = ratelimit.NewLimiter(c.RequestRateLimit)
+		p.reqLimit = c.AdaptiveRateLimit.NewLimiter(c.RequestRateLimit)
 	}
 	if c.FlowRateLimit > 0 {
-		p.flowLimit = ratelimit.NewLimiter(1024 * c.FlowRateLimit)
+		p.flowLimit = c.AdaptiveRateLimit.NewLimiter(1024 * c.FlowRateLimit)
+	}
+	if c.RateLimitBuckets != nil {
+		p.reqBuckets, p.flowBuckets = c.RateLimitBuckets.NewGroups(p.reqLimit, p.flowLimit)
 	}
 	return
 }
 
-func (c *Pipeline) newRequest(op *request.Operation, token string, v interface{}) *request.Request {
-	req := request.New(c.Config, c.HTTPClient, op, token, builder, v)
+// setLimiters 为数据写入请求设置限速器，配置了 RateLimitBuckets 时使用 repo/ResourceOwner 对应的桶
+func (c *Pipeline) setLimiters(req *request.Request, repoName, resourceOwner string) {
+	if c.reqBuckets == nil {
+		req.SetFlowLimiter(c.flowLimit)
+		req.SetReqLimiter(c.reqLimit)
+		return
+	}
+	key := c.Config.RateLimitBuckets.Key(repoName, resourceOwner)
+	req.SetFlowLimiter(c.flowBuckets.Bucket(key))
+	req.SetReqLimiter(c.reqBuckets.Bucket(key))
+}
+
+// SetRepoRegion 记录 repo 所在的 region，配置了 Config.ServiceEndpoints 时该 repo 的数据写入请求发往这个 region 的 endpoint。
+// CreateRepo 和 GetRepo 会自动记录
+func (c *Pipeline) SetRepoRegion(repoName, region string) {
+	if region == "" {
+		return
+	}
+	c.repoRegionMux.Lock()
+	c.repoRegions[repoName] = region
+	c.repoRegionMux.Unlock()
+}
+
+// routeToRepoRegion 把请求发往 repo 所在 region 的 endpoint，repo 的 region 未知时使用 DefaultRegion
+func (c *Pipeline) routeToRepoRegion(req *request.Request, repoName string) {
+	c.repoRegionMux.Lock()
+	region, ok := c.repoRegions[repoName]
+	c.repoRegionMux.Unlock()
+	if ok {
+		req.SetRegion(region)
+	}
+}
+
+func (c *Pipeline) newRequest(ctx context.Context, op *request.Operation, token string, v interface{}) *request.Request {
+	req := request.NewWithContext(ctx, c.Config, c.HTTPClient, op, token, builder, v)
 	req.Data = v
 	return req
 }
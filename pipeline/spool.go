@@ -0,0 +1,496 @@
+package pipeline
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qiniu/pandora-go-sdk/base"
+	"github.com/qiniu/pandora-go-sdk/base/reqerr"
+)
+
+const (
+	spoolSegmentExt        = ".seg"
+	spoolCheckpointFile    = "checkpoint"
+	spoolRecordHeaderLen   = 8
+	defaultSpoolSegment    = 64 * 1024 * 1024
+	defaultSpoolMaxSize    = 1024 * 1024 * 1024
+	defaultSpoolReplayTick = 10 * time.Second
+)
+
+var errSpoolCorrupted = errors.New("spool record corrupted")
+
+// SpoolConfig 描述发送失败数据的本地落盘策略
+type SpoolConfig struct {
+	Dir            string
+	SegmentSize    int64         // 单个 segment 文件的最大字节数，大于 MaxSize 时使用 MaxSize
+	MaxSize        int64         // 所有 segment 的总大小上限，超过时从最旧的 segment 开始淘汰
+	ReplayInterval time.Duration // Start 之后后台重放的间隔
+
+	// SchemaFree 返回重放 Data 时使用的 SchemaFreeInput 模板，为 nil 时只设置 RepoName
+	SchemaFree func(repoName string) *SchemaFreeInput
+	// OnDrop 在数据因自身错误无法重放或因容量被淘汰时调用
+	OnDrop func(repoName string, err error)
+}
+
+type spoolRecord struct {
+	Repo   string                   `json:"repo"`
+	Tags   map[string]interface{}   `json:"tags,omitempty"`
+	Points []byte                   `json:"points,omitempty"`
+	Datas  []map[string]interface{} `json:"datas,omitempty"`
+}
+
+type spoolSegment struct {
+	id   int64
+	size int64
+}
+
+// Spool 是一个基于本地 segment 文件的预写队列，保存发送失败的数据，并在服务恢复后按写入顺序重放，
+// 每条记录带有 crc32 校验，进程重启后会从 checkpoint 继续重放
+type Spool struct {
+	client PipelineAPI
+	cfg    SpoolConfig
+	logger base.Logger
+
+	mu       sync.Mutex
+	segments []spoolSegment // 按 id 升序，最后一个为正在写入的 segment
+	active   *os.File
+	size     int64
+
+	replayMu sync.Mutex
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func OpenSpool(client PipelineAPI, cfg SpoolConfig) (*Spool, error) {
+	if cfg.Dir == "" {
+		return nil, reqerr.NewInvalidArgs("Dir", "spool dir should not be empty")
+	}
+	if cfg.SegmentSize <= 0 {
+		cfg.SegmentSize = defaultSpoolSegment
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = defaultSpoolMaxSize
+	}
+	// 正在写入的 segment 不会被淘汰，segment 不能大于 MaxSize，否则总大小无法限制在 MaxSize 以内
+	if cfg.SegmentSize > cfg.MaxSize {
+		cfg.SegmentSize = cfg.MaxSize
+	}
+	if cfg.ReplayInterval <= 0 {
+		cfg.ReplayInterval = defaultSpoolReplayTick
+	}
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, err
+	}
+	logger := base.Logger(base.NewDefaultLogger())
+	if p, ok := client.(*Pipeline); ok && p.Config.Logger != nil {
+		logger = p.Config.Logger
+	}
+	s := &Spool{client: client, cfg: cfg, logger: logger, done: make(chan struct{})}
+
+	infos, err := ioutil.ReadDir(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		name := info.Name()
+		if !strings.HasSuffix(name, spoolSegmentExt) {
+			continue
+		}
+		id, err := strconv.ParseInt(strings.TrimSuffix(name, spoolSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		s.segments = append(s.segments, spoolSegment{id: id, size: info.Size()})
+		s.size += info.Size()
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i].id < s.segments[j].id })
+	if err = s.rotateLocked(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// AppendPoints 保存一批发送失败的 Point，例如 PostLargeData 返回的 datafailed
+func (s *Spool) AppendPoints(repoName string, tags map[string]interface{}, points Points) error {
+	if len(points) == 0 {
+		return nil
+	}
+	return s.append(&spoolRecord{Repo: repoName, Tags: tags, Points: points.Buffer()})
+}
+
+// AppendDatas 保存一批发送失败的 schemafree 数据
+func (s *Spool) AppendDatas(repoName string, tags map[string]interface{}, datas Datas) error {
+	if len(datas) == 0 {
+		return nil
+	}
+	return s.append(&spoolRecord{Repo: repoName, Tags: tags, Datas: convertDatas(datas)})
+}
+
+// AppendSendError 保存 PostDataSchemaFree 返回的 SendError 中发送失败的数据，err 不是 SendError 时不做处理
+func (s *Spool) AppendSendError(input *SchemaFreeInput, err error) error {
+	se, ok := err.(*reqerr.SendError)
+	if !ok || len(se.GetFailDatas()) == 0 {
+		return nil
+	}
+	return s.append(&spoolRecord{Repo: input.RepoName, Tags: input.Tags, Datas: se.GetFailDatas()})
+}
+
+// Size 返回当前所有 segment 的总字节数
+func (s *Spool) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+func (s *Spool) append(rec *spoolRecord) error {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, spoolRecordHeaderLen+len(payload))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+	copy(buf[spoolRecordHeaderLen:], payload)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active == nil {
+		return fmt.Errorf("spool %s is closed", s.cfg.Dir)
+	}
+	last := &s.segments[len(s.segments)-1]
+	if last.size > 0 && last.size+int64(len(buf)) > s.cfg.SegmentSize {
+		if err = s.rotateLocked(); err != nil {
+			return err
+		}
+		last = &s.segments[len(s.segments)-1]
+	}
+	if _, err = s.active.Write(buf); err != nil {
+		return err
+	}
+	if err = s.active.Sync(); err != nil {
+		return err
+	}
+	last.size += int64(len(buf))
+	s.size += int64(len(buf))
+	s.evictLocked()
+	return nil
+}
+
+// rotateLocked 关闭当前 segment 并新建一个空 segment 用于写入
+func (s *Spool) rotateLocked() error {
+	if s.active != nil {
+		if err := s.active.Close(); err != nil {
+			return err
+		}
+		s.active = nil
+	}
+	var id int64 = 1
+	if n := len(s.segments); n > 0 {
+		id = s.segments[n-1].id + 1
+	}
+	f, err := os.OpenFile(s.segmentPath(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	s.active = f
+	s.segments = append(s.segments, spoolSegment{id: id})
+	return nil
+}
+
+func (s *Spool) evictLocked() {
+	for s.size > s.cfg.MaxSize {
+		// 单条记录大于 MaxSize 时只剩正在写入的 segment，先切换到新的 segment 再淘汰
+		if len(s.segments) == 1 {
+			if err := s.rotateLocked(); err != nil {
+				s.logger.Errorf("spool rotate segment error %v", err)
+				return
+			}
+		}
+		oldest := s.segments[0]
+		if err := os.Remove(s.segmentPath(oldest.id)); err != nil && !os.IsNotExist(err) {
+			s.logger.Errorf("spool evict segment %d error %v", oldest.id, err)
+			return
+		}
+		s.segments = s.segments[1:]
+		s.size -= oldest.size
+		s.logger.Warnf("spool %s exceeds max size %d, segment %d with %d bytes evicted", s.cfg.Dir, s.cfg.MaxSize, oldest.id, oldest.size)
+		if s.cfg.OnDrop != nil {
+			s.cfg.OnDrop("", fmt.Errorf("spool segment %d evicted", oldest.id))
+		}
+	}
+}
+
+func (s *Spool) segmentPath(id int64) string {
+	return filepath.Join(s.cfg.Dir, fmt.Sprintf("%020d%s", id, spoolSegmentExt))
+}
+
+// Replay 按写入顺序重放所有已落盘的数据，遇到网络或服务端错误时停止并返回该错误，已成功的部分不会重复发送
+func (s *Spool) Replay(ctx context.Context) (replayed int, err error) {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	s.mu.Lock()
+	if s.active == nil {
+		s.mu.Unlock()
+		return 0, fmt.Errorf("spool %s is closed", s.cfg.Dir)
+	}
+	// 正在写入的 segment 有数据时先封存，保证重放的都是不再变化的 segment
+	if s.segments[len(s.segments)-1].size > 0 {
+		if err = s.rotateLocked(); err != nil {
+			s.mu.Unlock()
+			return
+		}
+	}
+	sealed := make([]spoolSegment, len(s.segments)-1)
+	copy(sealed, s.segments)
+	s.mu.Unlock()
+
+	cpID, cpOffset := s.readCheckpoint()
+	for _, seg := range sealed {
+		if seg.id < cpID {
+			continue
+		}
+		var offset int64
+		if seg.id == cpID {
+			offset = cpOffset
+		}
+		n, err := s.replaySegment(ctx, seg.id, offset)
+		replayed += n
+		if err != nil {
+			return replayed, err
+		}
+		s.removeSegment(seg.id)
+	}
+	return
+}
+
+func (s *Spool) replaySegment(ctx context.Context, id int64, offset int64) (replayed int, err error) {
+	f, err := os.Open(s.segmentPath(id))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return
+	}
+	remain := info.Size() - offset
+	r := bufio.NewReader(f)
+	for {
+		rec, n, rerr := readSpoolRecord(r, remain)
+		if rerr == io.EOF {
+			return replayed, nil
+		}
+		if rerr != nil {
+			// 进程崩溃可能留下不完整的尾部记录，跳过该 segment 剩余部分
+			s.logger.Errorf("spool segment %d at offset %d: %v, skip the rest", id, offset, rerr)
+			if s.cfg.OnDrop != nil {
+				s.cfg.OnDrop("", rerr)
+			}
+			return replayed, nil
+		}
+		requeued, rerr := s.replayRecord(ctx, rec)
+		if rerr != nil && !requeued {
+			return replayed, rerr
+		}
+		offset += n
+		remain -= n
+		replayed++
+		s.writeCheckpoint(id, offset)
+		if rerr != nil {
+			return replayed, rerr
+		}
+	}
+}
+
+// replayRecord 重放一条记录。schemafree 数据部分发送成功时，只把失败的数据作为新记录重新写入 spool，
+// 此时 requeued 为 true，该记录视为已处理，避免下次重放时重复发送已成功的数据
+func (s *Spool) replayRecord(ctx context.Context, rec *spoolRecord) (requeued bool, err error) {
+	var errType reqerr.SendErrorType
+	if len(rec.Points) > 0 {
+		err = s.client.PostDataFromBytesWithContext(ctx, &PostDataFromBytesInput{
+			RepoName: rec.Repo,
+			Buffer:   rec.Points,
+			Tags:     rec.Tags,
+		})
+		errType = sendErrorType(err)
+	} else {
+		input := &SchemaFreeInput{}
+		if s.cfg.SchemaFree != nil {
+			if tmpl := s.cfg.SchemaFree(rec.Repo); tmpl != nil {
+				*input = *tmpl
+			}
+		}
+		input.RepoName = rec.Repo
+		if input.Tags == nil {
+			input.Tags = rec.Tags
+		}
+		input.Datas = make(Datas, 0, len(rec.Datas))
+		for _, d := range rec.Datas {
+			input.Datas = append(input.Datas, Data(d))
+		}
+		var rejected []RejectedData
+		rejected, _, err = s.client.PostDataSchemaFreeResilientWithContext(ctx, input)
+		for _, r := range rejected {
+			s.logger.Errorf("spool drop data of repo %s, error %v", rec.Repo, r.Err)
+			if s.cfg.OnDrop != nil {
+				s.cfg.OnDrop(rec.Repo, r.Err)
+			}
+		}
+		if se, ok := err.(*reqerr.SendError); ok {
+			errType = se.ErrorType
+			// 只有部分数据失败时把失败的数据重新写入 spool，整条记录都失败时保持原样，下次重放
+			if failed := se.GetFailDatas(); len(failed) > 0 && len(failed)+len(rejected) < len(rec.Datas) {
+				if aerr := s.append(&spoolRecord{Repo: rec.Repo, Tags: rec.Tags, Datas: failed}); aerr != nil {
+					s.logger.Errorf("spool requeue %d failed datas of repo %s error %v", len(failed), rec.Repo, aerr)
+					return false, err
+				}
+				return true, err
+			}
+		}
+	}
+	if err == nil {
+		return false, nil
+	}
+	// 数据本身有问题时重放也不会成功，丢弃该记录，避免阻塞后续数据
+	if errType == reqerr.TypeBinaryUnpack || errType == reqerr.TypeContainInvalidPoint {
+		s.logger.Errorf("spool drop record of repo %s, error %v", rec.Repo, err)
+		if s.cfg.OnDrop != nil {
+			s.cfg.OnDrop(rec.Repo, err)
+		}
+		return false, nil
+	}
+	return false, err
+}
+
+func (s *Spool) removeSegment(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, seg := range s.segments {
+		if seg.id != id {
+			continue
+		}
+		if err := os.Remove(s.segmentPath(id)); err != nil && !os.IsNotExist(err) {
+			s.logger.Errorf("spool remove segment %d error %v", id, err)
+			return
+		}
+		s.segments = append(s.segments[:i], s.segments[i+1:]...)
+		s.size -= seg.size
+		return
+	}
+}
+
+// readSpoolRecord 读取一条记录，remain 是 segment 中剩余的字节数。header 没有校验，
+// 长度超过 remain 的记录视为损坏，避免按照损坏的长度分配内存
+func readSpoolRecord(r *bufio.Reader, remain int64) (rec *spoolRecord, n int64, err error) {
+	header := make([]byte, spoolRecordHeaderLen)
+	if _, err = io.ReadFull(r, header); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = errSpoolCorrupted
+		}
+		return
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	if int64(spoolRecordHeaderLen)+int64(length) > remain {
+		return nil, 0, errSpoolCorrupted
+	}
+	payload := make([]byte, length)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return nil, 0, errSpoolCorrupted
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, 0, errSpoolCorrupted
+	}
+	rec = &spoolRecord{}
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err = dec.Decode(rec); err != nil {
+		return nil, 0, errSpoolCorrupted
+	}
+	return rec, int64(spoolRecordHeaderLen + length), nil
+}
+
+func (s *Spool) readCheckpoint() (id, offset int64) {
+	buf, err := ioutil.ReadFile(filepath.Join(s.cfg.Dir, spoolCheckpointFile))
+	if err != nil {
+		return
+	}
+	fmt.Sscanf(string(buf), "%d %d", &id, &offset)
+	return
+}
+
+func (s *Spool) writeCheckpoint(id, offset int64) {
+	path := filepath.Join(s.cfg.Dir, spoolCheckpointFile)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(fmt.Sprintf("%d %d", id, offset)), 0644); err != nil {
+		s.logger.Errorf("spool write checkpoint error %v", err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		s.logger.Errorf("spool write checkpoint error %v", err)
+	}
+}
+
+// Start 启动后台 goroutine，每隔 ReplayInterval 重放一次
+func (s *Spool) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.cfg.ReplayInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if s.Size() == 0 {
+					continue
+				}
+				if n, err := s.Replay(context.Background()); err != nil {
+					s.logger.Warnf("spool replayed %d records, then stopped by error %v", n, err)
+				}
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// Close 停止后台重放并关闭正在写入的 segment，未重放的数据保留在磁盘上
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	if s.active == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	close(s.done)
+	s.mu.Unlock()
+	s.wg.Wait()
+
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.active.Close()
+	s.active = nil
+	return err
+}
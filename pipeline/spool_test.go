@@ -0,0 +1,191 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/qiniu/pandora-go-sdk/base/reqerr"
+)
+
+type fakeSpoolClient struct {
+	PipelineAPI
+	mu      sync.Mutex
+	down    bool
+	failKey string
+	bodies  []string
+	datas   []Datas
+}
+
+func (f *fakeSpoolClient) PostDataFromBytesWithContext(ctx context.Context, input *PostDataFromBytesInput) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return errors.New("connection refused")
+	}
+	f.bodies = append(f.bodies, input.RepoName+":"+string(input.Buffer))
+	return nil
+}
+
+func (f *fakeSpoolClient) PostDataSchemaFreeResilientWithContext(ctx context.Context, input *SchemaFreeInput) ([]RejectedData, map[string]RepoSchemaEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return nil, nil, errors.New("connection refused")
+	}
+	var accepted, failed Datas
+	for _, d := range input.Datas {
+		if _, ok := d[f.failKey]; ok {
+			failed = append(failed, d)
+		} else {
+			accepted = append(accepted, d)
+		}
+	}
+	if len(accepted) > 0 {
+		f.datas = append(f.datas, accepted)
+	}
+	if len(failed) > 0 {
+		return nil, nil, reqerr.NewSendError("service unavailable", convertDatas(failed), reqerr.TypeDefault)
+	}
+	return nil, nil, nil
+}
+
+func TestSpoolReplayAfterRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	client := &fakeSpoolClient{down: true}
+	s, err := OpenSpool(client, SpoolConfig{Dir: dir, SegmentSize: 64})
+	assert.NoError(t, err)
+	assert.NoError(t, s.AppendPoints("repo", nil, Points{{Fields: []PointField{{Key: "a", Value: 1}}}}))
+	assert.NoError(t, s.AppendDatas("repo", nil, Datas{{"b": int64(1) << 60}}))
+	assert.NoError(t, s.AppendPoints("repo", nil, Points{{Fields: []PointField{{Key: "a", Value: 2}}}}))
+
+	n, err := s.Replay(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 0, n)
+	assert.NoError(t, s.Close())
+
+	// 重启后从磁盘恢复未发送的数据，服务恢复后按写入顺序重放
+	s, err = OpenSpool(client, SpoolConfig{Dir: dir, SegmentSize: 64})
+	assert.NoError(t, err)
+	client.down = false
+	n, err = s.Replay(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []string{"repo:a=1", "repo:a=2"}, client.bodies)
+	assert.Len(t, client.datas, 1)
+	assert.Equal(t, "1152921504606846976", client.datas[0][0]["b"].(interface{ String() string }).String())
+	assert.Equal(t, int64(0), s.Size())
+
+	n, err = s.Replay(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.NoError(t, s.Close())
+}
+
+func TestSpoolEvictAndCorruption(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	client := &fakeSpoolClient{}
+	dropped := 0
+	s, err := OpenSpool(client, SpoolConfig{Dir: dir, SegmentSize: 1, MaxSize: 100, OnDrop: func(string, error) { dropped++ }})
+	assert.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, s.AppendPoints("repo", nil, Points{{Fields: []PointField{{Key: "a", Value: i}}}}))
+	}
+	assert.True(t, s.Size() <= 100)
+	assert.True(t, dropped > 0)
+
+	// 模拟崩溃时写了一半的记录
+	segs, _ := filepath.Glob(filepath.Join(dir, "*"+spoolSegmentExt))
+	f, err := os.OpenFile(segs[len(segs)-1], os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	f.Write([]byte{0, 0, 1})
+	f.Close()
+
+	_, err = s.Replay(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "repo:a=4", client.bodies[len(client.bodies)-1])
+
+	// header 中损坏的长度超过 segment 剩余大小时视为损坏，不按照该长度分配内存
+	dropped = 0
+	assert.NoError(t, s.AppendPoints("repo", nil, Points{{Fields: []PointField{{Key: "a", Value: 5}}}}))
+	segs, _ = filepath.Glob(filepath.Join(dir, "*"+spoolSegmentExt))
+	f, err = os.OpenFile(segs[len(segs)-1], os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	f.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	f.Close()
+	_, err = s.Replay(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "repo:a=4", client.bodies[len(client.bodies)-1])
+	assert.Equal(t, 1, dropped)
+	assert.NoError(t, s.Close())
+}
+
+func TestSpoolSegmentLargerThanMaxSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	client := &fakeSpoolClient{}
+	dropped := 0
+	s, err := OpenSpool(client, SpoolConfig{Dir: dir, SegmentSize: 1 << 20, MaxSize: 200, OnDrop: func(string, error) { dropped++ }})
+	assert.NoError(t, err)
+	defer s.Close()
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, s.AppendPoints("repo", nil, Points{{Fields: []PointField{{Key: "a", Value: i}}}}))
+		assert.True(t, s.Size() <= 200, "size %d", s.Size())
+	}
+	assert.True(t, dropped > 0)
+	_, err = s.Replay(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "repo:a=9", client.bodies[len(client.bodies)-1])
+
+	// 单条记录大于 MaxSize 时也会被淘汰
+	assert.NoError(t, s.AppendDatas("repo", nil, Datas{{"a": strings.Repeat("x", 300)}}))
+	assert.Equal(t, int64(0), s.Size())
+	n, err := s.Replay(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Empty(t, client.datas)
+}
+
+func TestSpoolReplayPartialFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	client := &fakeSpoolClient{failKey: "bad"}
+	s, err := OpenSpool(client, SpoolConfig{Dir: dir})
+	assert.NoError(t, err)
+	defer s.Close()
+	assert.NoError(t, s.AppendDatas("repo", nil, Datas{{"a": 1}, {"bad": 2}, {"a": 3}}))
+
+	// 部分数据发送成功时只把失败的数据重新写入 spool
+	n, err := s.Replay(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 1, n)
+	assert.Len(t, client.datas, 1)
+	assert.Len(t, client.datas[0], 2)
+
+	client.failKey = ""
+	n, err = s.Replay(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Len(t, client.datas, 2)
+	assert.Len(t, client.datas[1], 1)
+	assert.Equal(t, "2", fmt.Sprint(client.datas[1][0]["bad"]))
+	assert.Equal(t, int64(0), s.Size())
+}
@@ -0,0 +1,196 @@
+package pipeline
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/qiniu/pandora-go-sdk/base/reqerr"
+)
+
+type streamBatch struct {
+	seq   int
+	buf   []byte
+	lines int64
+	end   int64
+}
+
+// streamAcker 记录按顺序连续确认的批次，并发发送时只有之前的批次都成功后 Offset 才会前进
+type streamAcker struct {
+	mu     sync.Mutex
+	next   int
+	done   map[int]*streamBatch
+	output PostDataFromStreamOutput
+	err    error
+}
+
+func (a *streamAcker) ack(b *streamBatch) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.done[b.seq] = b
+	for {
+		d, ok := a.done[a.next]
+		if !ok {
+			return
+		}
+		a.output.Offset = d.end
+		a.output.Lines += d.lines
+		delete(a.done, a.next)
+		a.next++
+	}
+}
+
+// fail 记录第一个发送失败的错误，返回是否为第一个
+func (a *streamAcker) fail(err error) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.err != nil {
+		return false
+	}
+	a.err = err
+	return true
+}
+
+// PostDataFromStream 从 Reader 中按行读取数据，按行边界切分成不超过 BatchSize 的批次后发送，不需要预先知道数据的总长度。
+// 行尾的 \r 会被去掉；超过 BatchSize 的行无法发送，读到时停止并返回错误。
+// 发送失败时停止读取并返回错误，output.Offset 为已确认发送成功的最后一行之后的字节偏移，调用方可以据此断点续传
+func (c *Pipeline) PostDataFromStream(input *PostDataFromStreamInput) (output *PostDataFromStreamOutput, err error) {
+	return c.PostDataFromStreamWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) PostDataFromStreamWithContext(ctx context.Context, input *PostDataFromStreamInput) (output *PostDataFromStreamOutput, err error) {
+	if input.Reader == nil {
+		return nil, reqerr.NewInvalidArgs("Reader", "reader should not be nil")
+	}
+	batchSize := input.BatchSize
+	if batchSize <= 0 || batchSize > PandoraMaxBatchSize {
+		batchSize = PandoraMaxBatchSize
+	}
+	workers := input.Concurrency
+	if workers <= 0 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	acker := &streamAcker{done: make(map[int]*streamBatch)}
+	batches := make(chan *streamBatch)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				if sendErr := c.postStreamBatch(ctx, input, b); sendErr != nil {
+					if acker.fail(sendErr) {
+						cancel()
+					}
+					continue
+				}
+				acker.ack(b)
+			}
+		}()
+	}
+
+	var readErr error
+	var buf bytes.Buffer
+	var offset, lines int64
+	seq := 0
+	emit := func() bool {
+		if buf.Len() == 0 {
+			return true
+		}
+		b := &streamBatch{seq: seq, buf: append([]byte(nil), buf.Bytes()...), lines: lines, end: offset}
+		seq++
+		buf.Reset()
+		lines = 0
+		select {
+		case batches <- b:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	// 缓冲区只比 batchSize 多出换行符的长度，超长的行不会整行读入内存
+	r := bufio.NewReaderSize(input.Reader, batchSize+2)
+	for {
+		line, rerr := r.ReadSlice('\n')
+		if rerr != nil && rerr != io.EOF && rerr != bufio.ErrBufferFull {
+			readErr = rerr
+			break
+		}
+		// 兼容 CRLF 换行
+		data := bytes.TrimRight(line, "\r\n")
+		if rerr == bufio.ErrBufferFull || len(data) > batchSize {
+			// 一行无法拆分到多个请求中，先发送之前的数据，Offset 停在该行开头，调用方可以跳过该行后续传
+			readErr = reqerr.NewInvalidArgs("Reader", fmt.Sprintf("line at offset %d is larger than batch size %d", offset, batchSize))
+			emit()
+			break
+		}
+		if len(data) > 0 {
+			if buf.Len() > 0 && buf.Len()+1+len(data) > batchSize && !emit() {
+				break
+			}
+			if buf.Len() > 0 {
+				buf.WriteByte('\n')
+			}
+			buf.Write(data)
+			lines++
+		}
+		offset += int64(len(line))
+		if rerr == io.EOF {
+			emit()
+			break
+		}
+	}
+	close(batches)
+	wg.Wait()
+
+	output = &PostDataFromStreamOutput{}
+	*output = acker.output
+	switch {
+	case acker.err != nil:
+		err = acker.err
+	case readErr != nil:
+		err = readErr
+	case ctx.Err() != nil:
+		err = ctx.Err()
+	}
+	return
+}
+
+func (c *Pipeline) postStreamBatch(ctx context.Context, input *PostDataFromStreamInput, b *streamBatch) error {
+	if input.SchemaFree == nil {
+		return c.PostDataFromBytesWithContext(ctx, &PostDataFromBytesInput{
+			RepoName:     input.RepoName,
+			Buffer:       b.buf,
+			PandoraToken: input.PandoraToken,
+			Tags:         input.Tags,
+		})
+	}
+
+	datas := make(Datas, 0, b.lines)
+	dec := json.NewDecoder(bytes.NewReader(b.buf))
+	dec.UseNumber()
+	for {
+		var d Data
+		if err := dec.Decode(&d); err == io.EOF {
+			break
+		} else if err != nil {
+			return reqerr.NewInvalidArgs("Reader", "invalid json line: "+err.Error())
+		}
+		datas = append(datas, d)
+	}
+	sfInput := *input.SchemaFree
+	sfInput.RepoName = input.RepoName
+	sfInput.Datas = datas
+	if sfInput.Tags == nil {
+		sfInput.Tags = input.Tags
+	}
+	_, err := c.PostDataSchemaFreeWithContext(ctx, &sfInput)
+	return err
+}
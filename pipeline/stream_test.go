@@ -0,0 +1,125 @@
+package pipeline
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/qiniu/pandora-go-sdk/base/reqerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostDataFromStream(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(body), "a=15") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		mu.Lock()
+		received = append(received, strings.Split(string(body), "\n")...)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	client, err := NewDefaultClient(NewConfig().WithPipelineEndpoint(srv.URL).WithAccessKeySecretKey("ak", "sk"))
+	assert.NoError(t, err)
+
+	var text strings.Builder
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(&text, "a=%d\n\n", i)
+	}
+	output, err := client.PostDataFromStream(&PostDataFromStreamInput{
+		RepoName:    "repo",
+		Reader:      strings.NewReader(text.String()),
+		BatchSize:   10,
+		Concurrency: 3,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(text.Len()), output.Offset)
+	assert.Equal(t, int64(10), output.Lines)
+	sort.Strings(received)
+	assert.Equal(t, []string{"a=0", "a=1", "a=2", "a=3", "a=4", "a=5", "a=6", "a=7", "a=8", "a=9"}, received)
+
+	received = nil
+	output, err = client.PostDataFromStream(&PostDataFromStreamInput{
+		RepoName:  "repo",
+		Reader:    strings.NewReader("a=11\na=12\na=13\na=14\na=15\na=16\na=17"),
+		BatchSize: 10,
+	})
+	assert.Error(t, err)
+	assert.Equal(t, int64(len("a=11\na=12\na=13\na=14\n")), output.Offset)
+	assert.Equal(t, int64(4), output.Lines)
+	assert.Equal(t, []string{"a=11", "a=12", "a=13", "a=14"}, received)
+}
+
+func TestPostDataFromStreamLines(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	client, err := NewDefaultClient(NewConfig().WithPipelineEndpoint(srv.URL).WithAccessKeySecretKey("ak", "sk"))
+	assert.NoError(t, err)
+
+	text := "a=1\r\na=2\r\n\r\na=3\r\n"
+	output, err := client.PostDataFromStream(&PostDataFromStreamInput{
+		RepoName: "repo",
+		Reader:   strings.NewReader(text),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(text)), output.Offset)
+	assert.Equal(t, int64(3), output.Lines)
+	assert.Equal(t, []string{"a=1\na=2\na=3"}, bodies)
+
+	// 超过 BatchSize 的行不会被发送，Offset 停在该行开头
+	bodies = nil
+	output, err = client.PostDataFromStream(&PostDataFromStreamInput{
+		RepoName:  "repo",
+		Reader:    strings.NewReader("a=1\na=123456789012\na=2\n"),
+		BatchSize: 10,
+	})
+	assert.Error(t, err)
+	assert.Equal(t, int64(len("a=1\n")), output.Offset)
+	assert.Equal(t, int64(1), output.Lines)
+	assert.Equal(t, []string{"a=1"}, bodies)
+
+	// 没有换行符的无限输入在读满 BatchSize 后立即返回错误，不会整行读入内存
+	bodies = nil
+	r := &endlessReader{}
+	output, err = client.PostDataFromStream(&PostDataFromStreamInput{
+		RepoName:  "repo",
+		Reader:    io.MultiReader(strings.NewReader("a=1\n"), r),
+		BatchSize: 100,
+	})
+	reqErr, ok := err.(*reqerr.RequestError)
+	assert.True(t, ok, "%v", err)
+	assert.Equal(t, reqerr.InvalidArgs, reqErr.ErrorType)
+	assert.Equal(t, int64(len("a=1\n")), output.Offset)
+	assert.True(t, r.n <= 102, "read %d bytes", r.n)
+	assert.Equal(t, []string{"a=1"}, bodies)
+}
+
+// endlessReader 是没有换行符的无限输入
+type endlessReader struct {
+	n int
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'x'
+	}
+	r.n += len(p)
+	return len(p), nil
+}
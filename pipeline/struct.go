@@ -0,0 +1,339 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qiniu/pandora-go-sdk/base"
+	"github.com/qiniu/pandora-go-sdk/base/record"
+	"github.com/qiniu/pandora-go-sdk/base/reqerr"
+)
+
+/*
+结构体字段通过 `pandora:"<字段名称>,<类型>,required,omitempty"` 标签描述对应的 repo 字段，各部分均可省略：
+  - 字段名称缺省时使用结构体字段名，为 `-` 时忽略该字段，未导出的字段也会被忽略
+  - 类型的写法与 DSL 相同，如 `long`、`l`、`array(string)`，缺省时根据 Go 类型推断：
+    整数为 long，浮点数为 float，string 为 string，bool 为 boolean，time.Time 为 date，net.IP 为 ip，
+    结构体为 map，slice 与数组为 array，map 为 jsonstring
+  - required 表示该字段必填，omitempty 表示值为零值时不写入 Point
+  - 匿名嵌入且没有标签的结构体，其字段视为外层结构体的字段
+*/
+const structTagName = record.TagName
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	ipType   = reflect.TypeOf(net.IP{})
+)
+
+type structField struct {
+	index     []int
+	entry     RepoSchemaEntry
+	omitEmpty bool
+	fields    []structField // map 类型字段对应的结构体字段
+}
+
+type structInfo struct {
+	fields []structField
+	err    error
+}
+
+// structInfos 缓存每个结构体类型解析后的字段
+var structInfos sync.Map
+
+// SchemaFromStruct 根据结构体的 pandora 标签生成 repo 的 schema，v 为结构体或结构体指针
+func SchemaFromStruct(v interface{}) (schema []RepoSchemaEntry, err error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		err = reqerr.NewInvalidArgs("Schema", fmt.Sprintf("%v is not a struct", t)).WithComponent("pipleline")
+		return
+	}
+	fields, err := cachedStructFields(t)
+	if err != nil {
+		return
+	}
+	return fieldsSchema(fields), nil
+}
+
+// PointFromStruct 按照 SchemaFromStruct 生成的 schema 把结构体编码为 Point，值为 nil 的指针、slice 与 map 字段不写入
+func PointFromStruct(v interface{}) (point Point, err error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			err = reqerr.NewInvalidArgs("Points", "nil pointer can not be encoded").WithComponent("pipleline")
+			return
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		err = reqerr.NewInvalidArgs("Points", fmt.Sprintf("%v is not a struct", rv.Type())).WithComponent("pipleline")
+		return
+	}
+	fields, err := cachedStructFields(rv.Type())
+	if err != nil {
+		return
+	}
+	values, err := encodeStruct(rv, fields)
+	if err != nil {
+		return
+	}
+	for _, f := range fields {
+		if value, ok := values[f.entry.Key]; ok {
+			point.Fields = append(point.Fields, PointField{Key: f.entry.Key, Value: value})
+		}
+	}
+	return
+}
+
+// PointsFromSlice 把结构体的 slice 或数组编码为 Points，元素可以是结构体或结构体指针
+func PointsFromSlice(v interface{}) (points Points, err error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		err = reqerr.NewInvalidArgs("Points", fmt.Sprintf("%v is not a slice", reflect.TypeOf(v))).WithComponent("pipleline")
+		return
+	}
+	points = make(Points, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		var point Point
+		if point, err = PointFromStruct(rv.Index(i).Interface()); err != nil {
+			return nil, fmt.Errorf("encode element %d: %v", i, err)
+		}
+		points = append(points, point)
+	}
+	return
+}
+
+func cachedStructFields(t reflect.Type) ([]structField, error) {
+	if info, ok := structInfos.Load(t); ok {
+		return info.(*structInfo).fields, info.(*structInfo).err
+	}
+	fields, err := parseStructFields(t, 0)
+	info, _ := structInfos.LoadOrStore(t, &structInfo{fields: fields, err: err})
+	return info.(*structInfo).fields, info.(*structInfo).err
+}
+
+func fieldsSchema(fields []structField) []RepoSchemaEntry {
+	schema := make([]RepoSchemaEntry, 0, len(fields))
+	for _, f := range fields {
+		schema = append(schema, f.entry)
+	}
+	return schema
+}
+
+func parseStructFields(t reflect.Type, depth int) (fields []structField, err error) {
+	if depth > base.NestLimit {
+		err = reqerr.NewInvalidArgs("Schema", fmt.Sprintf("struct %v is nested out of limit %v", t, base.NestLimit)).WithComponent("pipleline")
+		return
+	}
+	keys := make(map[string]struct{})
+	for _, rf := range record.Fields(t) {
+		var f structField
+		if f, err = parseStructField(rf, depth); err != nil {
+			return
+		}
+		if err = addStructField(&fields, keys, t, f); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func addStructField(fields *[]structField, keys map[string]struct{}, t reflect.Type, f structField) error {
+	if _, ok := keys[f.entry.Key]; ok {
+		return reqerr.NewInvalidArgs("Schema", fmt.Sprintf("duplicate key %s in struct %v", f.entry.Key, t)).WithComponent("pipleline")
+	}
+	keys[f.entry.Key] = struct{}{}
+	*fields = append(*fields, f)
+	return nil
+}
+
+func parseStructField(rf record.Field, depth int) (f structField, err error) {
+	ft, typ := rf.Type, ""
+	for ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	f = structField{index: rf.Index, entry: RepoSchemaEntry{Key: rf.Name}}
+	for _, opt := range rf.Options {
+		switch opt = strings.TrimSpace(opt); opt {
+		case "required":
+			f.entry.Required = true
+		case "omitempty":
+			f.omitEmpty = true
+		case "":
+		default:
+			typ = opt
+		}
+	}
+
+	if err = f.inferType(ft, typ); err != nil {
+		err = reqerr.NewInvalidArgs("Schema", fmt.Sprintf("field %s: %v", rf.GoName, err)).WithComponent("pipleline")
+		return
+	}
+	if f.entry.ValueType == PandoraTypeMap {
+		if f.fields, err = parseStructFields(ft, depth+1); err != nil {
+			return
+		}
+		f.entry.Schema = fieldsSchema(f.fields)
+	}
+	err = f.entry.Validate()
+	return
+}
+
+// inferType 确定字段的类型，typ 为标签中指定的类型，为空时根据 Go 类型推断
+func (f *structField) inferType(ft reflect.Type, typ string) (err error) {
+	if typ == "" {
+		f.entry.ValueType, f.entry.ElemType, err = inferStructFieldType(ft)
+		return
+	}
+	var required bool
+	if _, f.entry.ValueType, f.entry.ElemType, required, err = getField(f.entry.Key + " " + typ); err != nil {
+		return
+	}
+	f.entry.Required = f.entry.Required || required
+	switch f.entry.ValueType {
+	case PandoraTypeArray:
+		if f.entry.ElemType == "" && (ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array) {
+			_, f.entry.ElemType, err = inferArrayType(ft.Elem())
+		}
+	case PandoraTypeMap:
+		if ft.Kind() != reflect.Struct || ft == timeType {
+			err = fmt.Errorf("map type requires a struct, got %v", ft)
+		}
+	}
+	return
+}
+
+// inferStructFieldType 根据 Go 类型推断 pandora 类型
+func inferStructFieldType(t reflect.Type) (valueType, elemType string, err error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return PandoraTypeDate, "", nil
+	case ipType:
+		return PandoraTypeIP, "", nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		valueType = PandoraTypeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		valueType = PandoraTypeLong
+	case reflect.Float32, reflect.Float64:
+		valueType = PandoraTypeFloat
+	case reflect.String:
+		valueType = PandoraTypeString
+	case reflect.Struct:
+		valueType = PandoraTypeMap
+	case reflect.Map:
+		valueType = PandoraTypeJsonString
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return PandoraTypeString, "", nil
+		}
+		return inferArrayType(t.Elem())
+	default:
+		err = fmt.Errorf("can not infer pandora type of %v, specify it in the %s tag", t, structTagName)
+	}
+	return
+}
+
+func inferArrayType(elem reflect.Type) (valueType, elemType string, err error) {
+	if elemType, _, err = inferStructFieldType(elem); err != nil {
+		return
+	}
+	if elemType != PandoraTypeLong && elemType != PandoraTypeFloat && elemType != PandoraTypeString {
+		err = fmt.Errorf("array element type %v is not supported, element should be long, float or string", elem)
+		return
+	}
+	return PandoraTypeArray, elemType, nil
+}
+
+// encodeStruct 按字段取值，值为 nil 或 omitempty 的零值的字段不出现在返回的 map 中
+func encodeStruct(rv reflect.Value, fields []structField) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		fv, ok := record.FieldByIndex(rv, f.index, false)
+		for ok && fv.Kind() == reflect.Ptr {
+			if ok = !fv.IsNil(); ok {
+				fv = fv.Elem()
+			}
+		}
+		if ok && (fv.Kind() == reflect.Slice || fv.Kind() == reflect.Map || fv.Kind() == reflect.Interface) {
+			ok = !fv.IsNil()
+		}
+		if !ok {
+			if f.entry.Required {
+				return nil, reqerr.NewInvalidArgs("Points", fmt.Sprintf("required field %s is nil", f.entry.Key)).WithComponent("pipleline")
+			}
+			continue
+		}
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		value, err := encodeValue(f, fv)
+		if err != nil {
+			return nil, err
+		}
+		values[f.entry.Key] = value
+	}
+	return values, nil
+}
+
+func encodeValue(f structField, v reflect.Value) (interface{}, error) {
+	switch f.entry.ValueType {
+	case PandoraTypeMap:
+		if len(f.fields) > 0 && v.Kind() == reflect.Struct {
+			return encodeStruct(v, f.fields)
+		}
+	case PandoraTypeIP:
+		if ip, ok := v.Interface().(net.IP); ok {
+			return ip.String(), nil
+		}
+	case PandoraTypeJsonString:
+		if v.Kind() == reflect.String {
+			return v.String(), nil
+		}
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return nil, reqerr.NewInvalidArgs("Points", fmt.Sprintf("field %s: %v", f.entry.Key, err)).WithComponent("pipleline")
+		}
+		return string(b), nil
+	case PandoraTypeString:
+		if b, ok := v.Interface().([]byte); ok {
+			return string(b), nil
+		}
+	}
+	return v.Interface(), nil
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Struct:
+		if t, ok := v.Interface().(time.Time); ok {
+			return t.IsZero()
+		}
+	}
+	return false
+}
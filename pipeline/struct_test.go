@@ -0,0 +1,144 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type structTestMeta struct {
+	Host  string `pandora:"host,required"`
+	Ports []int  `pandora:"ports"`
+}
+
+type structTestBase struct {
+	Source string `pandora:"source"`
+}
+
+type structTestEvent struct {
+	structTestBase
+	ID        int64             `pandora:"id,long,required"`
+	Score     float64           `pandora:"score"`
+	Tags      []string          `pandora:"tags"`
+	Time      time.Time         `pandora:"ts"`
+	IP        net.IP            `pandora:"ip"`
+	OK        bool              `pandora:"ok,omitempty"`
+	Meta      *structTestMeta   `pandora:"meta"`
+	Extra     map[string]string `pandora:"extra"`
+	Raw       string            `pandora:"raw,jsonstring"`
+	Ignored   string            `pandora:"-"`
+	unexposed string
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	schema, err := SchemaFromStruct(&structTestEvent{})
+	assert.NoError(t, err)
+	assert.Equal(t, []RepoSchemaEntry{
+		{Key: "source", ValueType: PandoraTypeString},
+		{Key: "id", ValueType: PandoraTypeLong, Required: true},
+		{Key: "score", ValueType: PandoraTypeFloat},
+		{Key: "tags", ValueType: PandoraTypeArray, ElemType: PandoraTypeString},
+		{Key: "ts", ValueType: PandoraTypeDate},
+		{Key: "ip", ValueType: PandoraTypeIP},
+		{Key: "ok", ValueType: PandoraTypeBool},
+		{Key: "meta", ValueType: PandoraTypeMap, Schema: []RepoSchemaEntry{
+			{Key: "host", ValueType: PandoraTypeString, Required: true},
+			{Key: "ports", ValueType: PandoraTypeArray, ElemType: PandoraTypeLong},
+		}},
+		{Key: "extra", ValueType: PandoraTypeJsonString},
+		{Key: "raw", ValueType: PandoraTypeJsonString},
+	}, schema)
+
+	_, err = SchemaFromStruct(struct {
+		A interface{}
+	}{})
+	assert.Error(t, err)
+	_, err = SchemaFromStruct(struct {
+		A []bool
+	}{})
+	assert.Error(t, err)
+	_, err = SchemaFromStruct(struct {
+		A int `pandora:"a"`
+		B int `pandora:"a"`
+	}{})
+	assert.Error(t, err)
+	_, err = SchemaFromStruct(1)
+	assert.Error(t, err)
+}
+
+func TestPointsFromSlice(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	events := []*structTestEvent{
+		{
+			structTestBase: structTestBase{Source: "a\tb"},
+			ID:             1,
+			Score:          0.5,
+			Tags:           []string{"x", "y"},
+			Time:           ts,
+			IP:             net.ParseIP("10.0.0.1"),
+			Meta:           &structTestMeta{Host: "h1", Ports: []int{80}},
+			Extra:          map[string]string{"k": "v"},
+			Raw:            `{"a":1}`,
+			Ignored:        "ignored",
+		},
+		{ID: 2, OK: true},
+	}
+	points, err := PointsFromSlice(events)
+	assert.NoError(t, err)
+	assert.Equal(t, "source=a\\tb\tid=1\tscore=0.5\ttags=[\"x\",\"y\"]\tts=2026-01-02T03:04:05Z\tip=10.0.0.1\t"+
+		"meta={\"host\":\"h1\",\"ports\":[80]}\textra={\"k\":\"v\"}\traw={\"a\":1}\n"+
+		"source=\tid=2\tscore=0\tts=0001-01-01T00:00:00Z\tok=true\traw=", string(points.Buffer()))
+
+	_, err = PointFromStruct(struct {
+		ID *int64 `pandora:"id,required"`
+	}{})
+	assert.Error(t, err)
+	_, err = PointsFromSlice(structTestEvent{})
+	assert.Error(t, err)
+}
+
+func TestSampleDataDecode(t *testing.T) {
+	var output SampleDataOutput
+	assert.NoError(t, json.Unmarshal([]byte(`{"records":[{"id":9007199254740993,"ts":"2026-01-02T03:04:05Z","meta":{"host":"h"}}]}`), &output))
+	assert.Len(t, output.Values, 1)
+	// Values 的解析方式不变，数字仍然是 float64
+	assert.Equal(t, float64(9007199254740993), output.Values[0]["id"])
+
+	var events []structTestEvent
+	assert.NoError(t, output.Decode(&events))
+	assert.Equal(t, int64(9007199254740993), events[0].ID)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), events[0].Time)
+	assert.Equal(t, "h", events[0].Meta.Host)
+
+	// 手动构造的 SampleDataOutput 解码 Values
+	output = SampleDataOutput{Values: []map[string]interface{}{{"id": float64(1)}}}
+	assert.NoError(t, output.Decode(&events))
+	assert.Equal(t, int64(1), events[0].ID)
+}
+
+type StructTestLabels struct {
+	Env string `pandora:"env"`
+}
+
+func TestPointFromStructNilEmbedded(t *testing.T) {
+	type event struct {
+		*StructTestLabels
+		Name string `pandora:"name"`
+	}
+	schema, err := SchemaFromStruct(event{})
+	assert.NoError(t, err)
+	assert.Equal(t, []RepoSchemaEntry{{Key: "env", ValueType: PandoraTypeString}, {Key: "name", ValueType: PandoraTypeString}}, schema)
+
+	// 值为 nil 的嵌入结构体指针的字段不写入
+	point, err := PointFromStruct(event{Name: "n"})
+	assert.NoError(t, err)
+	assert.Equal(t, Point{Fields: []PointField{{Key: "name", Value: "n"}}}, point)
+
+	var events []event
+	output := SampleDataOutput{Values: []map[string]interface{}{{"env": "prod", "name": "n"}}}
+	assert.NoError(t, output.Decode(&events))
+	assert.Equal(t, "prod", events[0].Env)
+}
@@ -1,6 +1,7 @@
 package pipeline
 
 import (
+	"context"
 	"strings"
 
 	"github.com/qiniu/x/log"
@@ -210,6 +211,10 @@ func isInExpandAttr(key string, expandAttr []string) bool {
 }
 
 func (c *Pipeline) AutoExportToTSDB(input *AutoExportToTSDBInput) error {
+	return c.AutoExportToTSDBWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) AutoExportToTSDBWithContext(ctx context.Context, input *AutoExportToTSDBInput) error {
 	if input.TSDBRepoName == "" {
 		input.TSDBRepoName = input.RepoName
 	}
@@ -219,7 +224,7 @@ func (c *Pipeline) AutoExportToTSDB(input *AutoExportToTSDBInput) error {
 	if input.Retention == "" {
 		input.Retention = "30d"
 	}
-	repoInfo, err := c.GetRepo(&GetRepoInput{
+	repoInfo, err := c.GetRepoWithContext(ctx, &GetRepoInput{
 		RepoName:     input.RepoName,
 		PandoraToken: input.PipelineGetRepoToken,
 	})
@@ -233,7 +238,7 @@ func (c *Pipeline) AutoExportToTSDB(input *AutoExportToTSDBInput) error {
 	}
 
 	if !input.IsMetric {
-		return c.CreateForTSDB(&CreateRepoForTSDBInput{
+		return c.CreateForTSDBWithContext(ctx, &CreateRepoForTSDBInput{
 			Tags:                 tags,
 			RepoName:             input.RepoName,
 			TSDBRepoName:         input.TSDBRepoName,
@@ -278,7 +283,7 @@ func (c *Pipeline) AutoExportToTSDB(input *AutoExportToTSDBInput) error {
 		seriesMap[k] = val
 	}
 
-	err = c.CreateForMutiExportTSDB(&CreateRepoForMutiExportTSDBInput{
+	err = c.CreateForMutiExportTSDBWithContext(ctx, &CreateRepoForMutiExportTSDBInput{
 		RepoName:             input.RepoName,
 		TSDBRepoName:         input.TSDBRepoName,
 		Region:               repoInfo.Region,
@@ -292,6 +297,10 @@ func (c *Pipeline) AutoExportToTSDB(input *AutoExportToTSDBInput) error {
 }
 
 func (c *Pipeline) AutoExportToLogDB(input *AutoExportToLogDBInput) error {
+	return c.AutoExportToLogDBWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) AutoExportToLogDBWithContext(ctx context.Context, input *AutoExportToLogDBInput) error {
 	if input.LogRepoName == "" {
 		input.LogRepoName = input.RepoName
 	}
@@ -299,7 +308,7 @@ func (c *Pipeline) AutoExportToLogDB(input *AutoExportToLogDBInput) error {
 	if input.Retention == "" {
 		input.Retention = "30d"
 	}
-	repoInfo, err := c.GetRepo(&GetRepoInput{
+	repoInfo, err := c.GetRepoWithContext(ctx, &GetRepoInput{
 		RepoName:     input.RepoName,
 		PandoraToken: input.PipelineGetRepoToken,
 	})
@@ -313,7 +322,7 @@ func (c *Pipeline) AutoExportToLogDB(input *AutoExportToLogDBInput) error {
 		return err
 	}
 	logdbschemas := convertSchema2LogDB(repoInfo.Schema, input.AnalyzerInfo, nil)
-	logdbrepoinfo, err := logdbapi.GetRepo(&logdb.GetRepoInput{
+	logdbrepoinfo, err := logdbapi.GetRepoWithContext(ctx, &logdb.GetRepoInput{
 		RepoName:     input.LogRepoName,
 		PandoraToken: input.GetLogDBRepoToken,
 	})
@@ -329,7 +338,7 @@ func (c *Pipeline) AutoExportToLogDB(input *AutoExportToLogDBInput) error {
 		if input.AnalyzerInfo.FullText {
 			linput.FullText = logdb.NewFullText(logdb.StandardAnalyzer)
 		}
-		err = logdbapi.CreateRepo(linput)
+		err = logdbapi.CreateRepoWithContext(ctx, linput)
 		if err != nil && !reqerr.IsExistError(err) {
 			log.Error("AutoExportToLogDB create logdb repo error", err)
 			return err
@@ -348,7 +357,7 @@ func (c *Pipeline) AutoExportToLogDB(input *AutoExportToLogDBInput) error {
 			}
 		}
 		if needupdate {
-			if err = logdbapi.UpdateRepo(&logdb.UpdateRepoInput{
+			if err = logdbapi.UpdateRepoWithContext(ctx, &logdb.UpdateRepoInput{
 				RepoName:     input.LogRepoName,
 				Retention:    logdbrepoinfo.Retention,
 				Schema:       logdbrepoinfo.Schema,
@@ -360,7 +369,7 @@ func (c *Pipeline) AutoExportToLogDB(input *AutoExportToLogDBInput) error {
 		}
 	}
 
-	_, err = c.GetExport(&GetExportInput{
+	_, err = c.GetExportWithContext(ctx, &GetExportInput{
 		RepoName:     input.RepoName,
 		ExportName:   base.FormExportName(input.RepoName, ExportTypeLogDB),
 		PandoraToken: input.GetExportToken,
@@ -376,7 +385,7 @@ func (c *Pipeline) AutoExportToLogDB(input *AutoExportToLogDBInput) error {
 		})
 		exportInput := c.FormExportInput(input.RepoName, ExportTypeLogDB, logDBSpec)
 		exportInput.PandoraToken = input.CreateExportToken
-		if err = c.CreateExport(exportInput); err != nil && reqerr.IsExistError(err) {
+		if err = c.CreateExportWithContext(ctx, exportInput); err != nil && reqerr.IsExistError(err) {
 			err = nil
 		} else if err != nil {
 			log.Error("AutoExportToLogDB get export error", err)
@@ -390,6 +399,10 @@ func (c *Pipeline) AutoExportToLogDB(input *AutoExportToLogDBInput) error {
 
 //自动导出到KODO需要提前创建bucket，不会自动创建
 func (c *Pipeline) AutoExportToKODO(input *AutoExportToKODOInput) error {
+	return c.AutoExportToKODOWithContext(context.Background(), input)
+}
+
+func (c *Pipeline) AutoExportToKODOWithContext(ctx context.Context, input *AutoExportToKODOInput) error {
 	if input.BucketName == "" {
 		input.BucketName = input.RepoName
 	}
@@ -398,7 +411,7 @@ func (c *Pipeline) AutoExportToKODO(input *AutoExportToKODOInput) error {
 	}
 	input.BucketName = strings.Replace(input.BucketName, "_", "-", -1)
 
-	repoInfo, err := c.GetRepo(&GetRepoInput{
+	repoInfo, err := c.GetRepoWithContext(ctx, &GetRepoInput{
 		RepoName:     input.RepoName,
 		PandoraToken: input.PipelineGetRepoToken,
 	})
@@ -407,15 +420,15 @@ func (c *Pipeline) AutoExportToKODO(input *AutoExportToKODOInput) error {
 		return err
 	}
 
-	_, err = c.GetExport(&GetExportInput{
+	_, err = c.GetExportWithContext(ctx, &GetExportInput{
 		RepoName:     input.RepoName,
 		ExportName:   base.FormExportName(input.RepoName, ExportTypeKODO),
 		PandoraToken: input.GetExportToken,
 	})
 	if reqerr.IsNoSuchResourceError(err) {
 		var ak string
-		if c.Config.Ak != "" {
-			ak = c.Config.Ak
+		if creds, err := c.Config.GetCredentials(); err == nil {
+			ak = creds.Ak
 		}
 		if ak == "" && input.CreateExportToken.Token != "" {
 			tks := strings.Split(strings.TrimSpace(strings.TrimPrefix(input.CreateExportToken.Token, "Pandora")), ":")
@@ -443,7 +456,7 @@ func (c *Pipeline) AutoExportToKODO(input *AutoExportToKODOInput) error {
 		})
 		exportInput := c.FormExportInput(input.RepoName, ExportTypeKODO, kodoSpec)
 		exportInput.PandoraToken = input.CreateExportToken
-		if err = c.CreateExport(exportInput); err != nil && reqerr.IsExistError(err) {
+		if err = c.CreateExportWithContext(ctx, exportInput); err != nil && reqerr.IsExistError(err) {
 			err = nil
 		} else if err != nil {
 			log.Error("AutoExportToKodo create export error", err)
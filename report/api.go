@@ -1,12 +1,20 @@
 package report
 
-import . "github.com/qiniu/pandora-go-sdk/base"
+import (
+	"context"
+
+	. "github.com/qiniu/pandora-go-sdk/base"
+)
 
 func (c *Report) ActivateUser(input *UserActivateInput) (output *UserActivateOutput, err error) {
+	return c.ActivateUserWithContext(context.Background(), input)
+}
+
+func (c *Report) ActivateUserWithContext(ctx context.Context, input *UserActivateInput) (output *UserActivateOutput, err error) {
 	op := c.newOperation(OpActivateUser)
 
 	output = &UserActivateOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -16,9 +24,13 @@ func (c *Report) ActivateUser(input *UserActivateInput) (output *UserActivateOut
 }
 
 func (c *Report) CreateDatabase(input *CreateDatabaseInput) (err error) {
+	return c.CreateDatabaseWithContext(context.Background(), input)
+}
+
+func (c *Report) CreateDatabaseWithContext(ctx context.Context, input *CreateDatabaseInput) (err error) {
 	op := c.newOperation(OpCreateDatabase, input.DatabaseName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -27,24 +39,36 @@ func (c *Report) CreateDatabase(input *CreateDatabaseInput) (err error) {
 }
 
 func (c *Report) ListDatabases(input *ListDatabasesInput) (output *ListDatabasesOutput, err error) {
+	return c.ListDatabasesWithContext(context.Background(), input)
+}
+
+func (c *Report) ListDatabasesWithContext(ctx context.Context, input *ListDatabasesInput) (output *ListDatabasesOutput, err error) {
 	op := c.newOperation(OpListDatabases)
 
 	output = &ListDatabasesOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Report) DeleteDatabase(input *DeleteDatabaseInput) (err error) {
+	return c.DeleteDatabaseWithContext(context.Background(), input)
+}
+
+func (c *Report) DeleteDatabaseWithContext(ctx context.Context, input *DeleteDatabaseInput) (err error) {
 	op := c.newOperation(OpDeleteDatabase, input.DatabaseName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Report) CreateTable(input *CreateTableInput) (err error) {
+	return c.CreateTableWithContext(context.Background(), input)
+}
+
+func (c *Report) CreateTableWithContext(ctx context.Context, input *CreateTableInput) (err error) {
 	op := c.newOperation(OpCreateTable, input.DatabaseName, input.TableName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -53,18 +77,26 @@ func (c *Report) CreateTable(input *CreateTableInput) (err error) {
 }
 
 func (c *Report) ListTables(input *ListTablesInput) (output *ListTablesOutput, err error) {
+	return c.ListTablesWithContext(context.Background(), input)
+}
+
+func (c *Report) ListTablesWithContext(ctx context.Context, input *ListTablesInput) (output *ListTablesOutput, err error) {
 	op := c.newOperation(OpListTables, input.DatabaseName)
 
 	output = &ListTablesOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	return output, req.Send()
 
 }
 
 func (c *Report) UpdateTable(input *UpdateTableInput) (err error) {
+	return c.UpdateTableWithContext(context.Background(), input)
+}
+
+func (c *Report) UpdateTableWithContext(ctx context.Context, input *UpdateTableInput) (err error) {
 	op := c.newOperation(OpUpdateTable, input.DatabaseName, input.TableName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -73,20 +105,32 @@ func (c *Report) UpdateTable(input *UpdateTableInput) (err error) {
 }
 
 func (c *Report) DeleteTable(input *DeleteTableInput) (err error) {
+	return c.DeleteTableWithContext(context.Background(), input)
+}
+
+func (c *Report) DeleteTableWithContext(ctx context.Context, input *DeleteTableInput) (err error) {
 	op := c.newOperation(OpDeleteTable, input.DatabaseName, input.TableName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Report) GetTable(input *GetTableInput) (output *GetTableOutput, err error) {
+	return c.GetTableWithContext(context.Background(), input)
+}
+
+func (c *Report) GetTableWithContext(ctx context.Context, input *GetTableInput) (output *GetTableOutput, err error) {
 	op := c.newOperation(OpGetTable, input.DatabaseName, input.TableName)
 
 	output = &GetTableOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	return output, req.Send()
 }
 
 func (c *Report) MakeToken(desc *TokenDesc) (string, error) {
-	return MakeTokenInternal(c.Config.Ak, c.Config.Sk, desc)
+	creds, err := c.Config.GetCredentials()
+	if err != nil {
+		return "", err
+	}
+	return MakeTokenInternal(creds.Ak, creds.Sk, desc)
 }
@@ -1,27 +1,46 @@
 package report
 
 import (
+	"context"
 	"github.com/qiniu/pandora-go-sdk/base"
 )
 
 type ReportAPI interface {
 	ActivateUser(*UserActivateInput) (*UserActivateOutput, error)
 
+	ActivateUserWithContext(context.Context, *UserActivateInput) (*UserActivateOutput, error)
+
 	CreateDatabase(*CreateDatabaseInput) error
 
+	CreateDatabaseWithContext(context.Context, *CreateDatabaseInput) error
+
 	ListDatabases(*ListDatabasesInput) (*ListDatabasesOutput, error)
 
+	ListDatabasesWithContext(context.Context, *ListDatabasesInput) (*ListDatabasesOutput, error)
+
 	DeleteDatabase(*DeleteDatabaseInput) error
 
+	DeleteDatabaseWithContext(context.Context, *DeleteDatabaseInput) error
+
 	CreateTable(*CreateTableInput) error
 
+	CreateTableWithContext(context.Context, *CreateTableInput) error
+
 	UpdateTable(*UpdateTableInput) error
 
+	UpdateTableWithContext(context.Context, *UpdateTableInput) error
+
 	ListTables(*ListTablesInput) (*ListTablesOutput, error)
 
+	ListTablesWithContext(context.Context, *ListTablesInput) (*ListTablesOutput, error)
+
 	GetTable(*GetTableInput) (*GetTableOutput, error)
 
+	GetTableWithContext(context.Context, *GetTableInput) (*GetTableOutput, error)
+
 	DeleteTable(*DeleteTableInput) error
 
+	DeleteTableWithContext(context.Context, *DeleteTableInput) error
+
 	MakeToken(*base.TokenDesc) (string, error)
 }
@@ -1,11 +1,9 @@
 package report
 
 import (
-	"crypto/tls"
+	"context"
 	"fmt"
-	"net"
 	"net/http"
-	"time"
 
 	"github.com/qiniu/pandora-go-sdk/base"
 	"github.com/qiniu/pandora-go-sdk/base/config"
@@ -39,29 +37,16 @@ func newClient(c *config.Config) (p *Report, err error) {
 		return
 	}
 
-	var t = &http.Transport{
-		Dial: (&net.Dialer{
-			Timeout:   c.DialTimeout,
-			KeepAlive: 30 * time.Second,
-		}).Dial,
-		ResponseHeaderTimeout: c.ResponseTimeout,
-		TLSClientConfig:       &tls.Config{},
-	}
-
-	if c.AllowInsecureServer {
-		t.TLSClientConfig.InsecureSkipVerify = true
-	}
-
 	p = &Report{
 		Config:     c,
-		HTTPClient: &http.Client{Transport: t},
+		HTTPClient: request.NewHTTPClient(c),
 	}
 
 	return
 }
 
-func (c *Report) newRequest(op *request.Operation, token string, v interface{}) *request.Request {
-	req := request.New(c.Config, c.HTTPClient, op, token, builder, v)
+func (c *Report) newRequest(ctx context.Context, op *request.Operation, token string, v interface{}) *request.Request {
+	req := request.NewWithContext(ctx, c.Config, c.HTTPClient, op, token, builder, v)
 	req.Data = v
 	return req
 }
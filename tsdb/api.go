@@ -1,15 +1,20 @@
 package tsdb
 
 import (
+	"context"
 	"os"
 
 	. "github.com/qiniu/pandora-go-sdk/base"
 )
 
 func (c *Tsdb) CreateRepo(input *CreateRepoInput) (err error) {
+	return c.CreateRepoWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) CreateRepoWithContext(ctx context.Context, input *CreateRepoInput) (err error) {
 	op := c.NewOperation(OpCreateRepo, input.RepoName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -18,25 +23,37 @@ func (c *Tsdb) CreateRepo(input *CreateRepoInput) (err error) {
 }
 
 func (c *Tsdb) GetRepo(input *GetRepoInput) (output *GetRepoOutput, err error) {
+	return c.GetRepoWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) GetRepoWithContext(ctx context.Context, input *GetRepoInput) (output *GetRepoOutput, err error) {
 	op := c.NewOperation(OpGetRepo, input.RepoName)
 
 	output = &GetRepoOutput{}
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	return output, req.Send()
 }
 
 func (c *Tsdb) ListRepos(input *ListReposInput) (output *ListReposOutput, err error) {
+	return c.ListReposWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) ListReposWithContext(ctx context.Context, input *ListReposInput) (output *ListReposOutput, err error) {
 	op := c.NewOperation(OpListRepos)
 
 	output = &ListReposOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	return output, req.Send()
 }
 
 func (c *Tsdb) UpdateRepoMetadata(input *UpdateRepoMetadataInput) (err error) {
+	return c.UpdateRepoMetadataWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) UpdateRepoMetadataWithContext(ctx context.Context, input *UpdateRepoMetadataInput) (err error) {
 	op := c.NewOperation(OpUpdateRepoMetadata, input.RepoName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -45,23 +62,35 @@ func (c *Tsdb) UpdateRepoMetadata(input *UpdateRepoMetadataInput) (err error) {
 }
 
 func (c *Tsdb) DeleteRepoMetadata(input *DeleteRepoMetadataInput) (err error) {
+	return c.DeleteRepoMetadataWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) DeleteRepoMetadataWithContext(ctx context.Context, input *DeleteRepoMetadataInput) (err error) {
 	op := c.NewOperation(OpDeleteRepoMetadata, input.RepoName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Tsdb) DeleteRepo(input *DeleteRepoInput) (err error) {
+	return c.DeleteRepoWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) DeleteRepoWithContext(ctx context.Context, input *DeleteRepoInput) (err error) {
 	op := c.NewOperation(OpDeleteRepo, input.RepoName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Tsdb) CreateSeries(input *CreateSeriesInput) (err error) {
+	return c.CreateSeriesWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) CreateSeriesWithContext(ctx context.Context, input *CreateSeriesInput) (err error) {
 	op := c.NewOperation(OpCreateSeries, input.RepoName, input.SeriesName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -70,18 +99,26 @@ func (c *Tsdb) CreateSeries(input *CreateSeriesInput) (err error) {
 }
 
 func (c *Tsdb) ListSeries(input *ListSeriesInput) (output *ListSeriesOutput, err error) {
+	return c.ListSeriesWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) ListSeriesWithContext(ctx context.Context, input *ListSeriesInput) (output *ListSeriesOutput, err error) {
 	op := c.NewOperation(OpListSeries, input.RepoName)
 
 	output = &ListSeriesOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	return output, req.Send()
 
 }
 
 func (c *Tsdb) UpdateSeriesMetadata(input *UpdateSeriesMetadataInput) (err error) {
+	return c.UpdateSeriesMetadataWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) UpdateSeriesMetadataWithContext(ctx context.Context, input *UpdateSeriesMetadataInput) (err error) {
 	op := c.NewOperation(OpUpdateSeriesMetadata, input.RepoName, input.SeriesName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -90,23 +127,35 @@ func (c *Tsdb) UpdateSeriesMetadata(input *UpdateSeriesMetadataInput) (err error
 }
 
 func (c *Tsdb) DeleteSeriesMetadata(input *DeleteSeriesMetadataInput) (err error) {
+	return c.DeleteSeriesMetadataWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) DeleteSeriesMetadataWithContext(ctx context.Context, input *DeleteSeriesMetadataInput) (err error) {
 	op := c.NewOperation(OpDeleteSeriesMetadata, input.RepoName, input.SeriesName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Tsdb) DeleteSeries(input *DeleteSeriesInput) (err error) {
+	return c.DeleteSeriesWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) DeleteSeriesWithContext(ctx context.Context, input *DeleteSeriesInput) (err error) {
 	op := c.NewOperation(OpDeleteSeries, input.RepoName, input.SeriesName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Tsdb) CreateView(input *CreateViewInput) (err error) {
+	return c.CreateViewWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) CreateViewWithContext(ctx context.Context, input *CreateViewInput) (err error) {
 	op := c.NewOperation(OpCreateView, input.RepoName, input.ViewName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -115,43 +164,64 @@ func (c *Tsdb) CreateView(input *CreateViewInput) (err error) {
 }
 
 func (c *Tsdb) ListView(input *ListViewInput) (output *ListViewOutput, err error) {
+	return c.ListViewWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) ListViewWithContext(ctx context.Context, input *ListViewInput) (output *ListViewOutput, err error) {
 	op := c.NewOperation(OpListView, input.RepoName)
 
 	output = &ListViewOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	return output, req.Send()
 }
 
 func (c *Tsdb) GetView(input *GetViewInput) (output *GetViewOutput, err error) {
+	return c.GetViewWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) GetViewWithContext(ctx context.Context, input *GetViewInput) (output *GetViewOutput, err error) {
 	op := c.NewOperation(OpGetView, input.RepoName, input.ViewName)
 
 	output = &GetViewOutput{}
-	req := c.newRequest(op, input.Token, &output)
+	req := c.newRequest(ctx, op, input.Token, &output)
 	return output, req.Send()
 }
 
 func (c *Tsdb) DeleteView(input *DeleteViewInput) (err error) {
+	return c.DeleteViewWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) DeleteViewWithContext(ctx context.Context, input *DeleteViewInput) (err error) {
 	op := c.NewOperation(OpDeleteView, input.RepoName, input.ViewName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	return req.Send()
 }
 
 func (c *Tsdb) PostPoints(input *PostPointsInput) (err error) {
+	return c.PostPointsWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) PostPointsWithContext(ctx context.Context, input *PostPointsInput) (err error) {
 	op := c.NewOperation(OpWritePoints, input.RepoName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	req.SetBufferBody(input.Points.Buffer())
+	req.SetCompressible()
 	req.SetHeader(HTTPHeaderContentType, ContentTypeText)
 	return req.Send()
 }
 
 func (c *Tsdb) QueryPoints(input *QueryInput) (output *QueryOutput, err error) {
+	return c.QueryPointsWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) QueryPointsWithContext(ctx context.Context, input *QueryInput) (output *QueryOutput, err error) {
 	op := c.NewOperation(OpQueryPoints, input.RepoName)
 
 	output = &QueryOutput{}
 
-	req := c.newRequest(op, input.Token, output)
+	req := c.newRequest(ctx, op, input.Token, output)
 	if err = req.SetVariantBody(input); err != nil {
 		return
 	}
@@ -161,9 +231,13 @@ func (c *Tsdb) QueryPoints(input *QueryInput) (output *QueryOutput, err error) {
 }
 
 func (c *Tsdb) PostPointsFromFile(input *PostPointsFromFileInput) (err error) {
+	return c.PostPointsFromFileWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) PostPointsFromFileWithContext(ctx context.Context, input *PostPointsFromFileInput) (err error) {
 	op := c.NewOperation(OpWritePoints, input.RepoName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	file, err := os.Open(input.FilePath)
 	if err != nil {
 		return err
@@ -175,23 +249,35 @@ func (c *Tsdb) PostPointsFromFile(input *PostPointsFromFileInput) (err error) {
 }
 
 func (c *Tsdb) PostPointsFromReader(input *PostPointsFromReaderInput) (err error) {
+	return c.PostPointsFromReaderWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) PostPointsFromReaderWithContext(ctx context.Context, input *PostPointsFromReaderInput) (err error) {
 	op := c.NewOperation(OpWritePoints, input.RepoName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	req.SetReaderBody(input.Reader)
 	req.SetHeader(HTTPHeaderContentType, ContentTypeText)
 	return req.Send()
 }
 
 func (c *Tsdb) PostPointsFromBytes(input *PostPointsFromBytesInput) (err error) {
+	return c.PostPointsFromBytesWithContext(context.Background(), input)
+}
+
+func (c *Tsdb) PostPointsFromBytesWithContext(ctx context.Context, input *PostPointsFromBytesInput) (err error) {
 	op := c.NewOperation(OpWritePoints, input.RepoName)
 
-	req := c.newRequest(op, input.Token, nil)
+	req := c.newRequest(ctx, op, input.Token, nil)
 	req.SetBufferBody(input.Buffer)
 	req.SetHeader(HTTPHeaderContentType, ContentTypeText)
 	return req.Send()
 }
 
 func (c *Tsdb) MakeToken(desc *TokenDesc) (string, error) {
-	return MakeTokenInternal(c.Config.Ak, c.Config.Sk, desc)
+	creds, err := c.Config.GetCredentials()
+	if err != nil {
+		return "", err
+	}
+	return MakeTokenInternal(creds.Ak, creds.Sk, desc)
 }
@@ -1,12 +1,9 @@
 package tsdb
 
 import (
+	"context"
 	"fmt"
-	"net"
 	"net/http"
-	"time"
-
-	"crypto/tls"
 
 	"github.com/qiniu/pandora-go-sdk/base"
 	"github.com/qiniu/pandora-go-sdk/base/config"
@@ -40,28 +37,16 @@ func NewDefaultClient(c *config.Config) (p *Tsdb, err error) {
 		return
 	}
 
-	var t = &http.Transport{
-		Dial: (&net.Dialer{
-			Timeout:   c.DialTimeout,
-			KeepAlive: 30 * time.Second,
-		}).Dial,
-		ResponseHeaderTimeout: c.ResponseTimeout,
-		TLSClientConfig:       &tls.Config{},
-	}
-	if c.AllowInsecureServer {
-		t.TLSClientConfig.InsecureSkipVerify = true
-	}
-
 	p = &Tsdb{
 		Config:     c,
-		HTTPClient: &http.Client{Transport: t},
+		HTTPClient: request.NewHTTPClient(c),
 	}
 
 	return
 }
 
-func (c *Tsdb) newRequest(op *request.Operation, token string, v interface{}) *request.Request {
-	req := request.New(c.Config, c.HTTPClient, op, token, builder, v)
+func (c *Tsdb) newRequest(ctx context.Context, op *request.Operation, token string, v interface{}) *request.Request {
+	req := request.NewWithContext(ctx, c.Config, c.HTTPClient, op, token, builder, v)
 	req.Data = v
 	return req
 }